@@ -4,16 +4,24 @@ import (
 	"context"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
 	"github.com/pavel-trbv/go-todo-app/internal/handler"
+	"github.com/pavel-trbv/go-todo-app/internal/hash"
+	"github.com/pavel-trbv/go-todo-app/internal/keys"
+	"github.com/pavel-trbv/go-todo-app/internal/netguard"
+	"github.com/pavel-trbv/go-todo-app/internal/notify"
 	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/pavel-trbv/go-todo-app/internal/scheduler"
 	"github.com/pavel-trbv/go-todo-app/internal/server"
 	"github.com/pavel-trbv/go-todo-app/internal/service"
+	"github.com/pavel-trbv/go-todo-app/internal/stream"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata"
 )
 
 func main() {
@@ -27,20 +35,63 @@ func main() {
 		logrus.Fatalf("error loading env variables: %s", err.Error())
 	}
 
-	db, err := repository.NewPostgresDB(repository.Config{
+	dbConfig := repository.Config{
 		Host:     viper.GetString("db.host"),
 		Port:     viper.GetString("db.port"),
 		Username: viper.GetString("db.username"),
 		DBName:   viper.GetString("db.dbname"),
 		SSLMode:  viper.GetString("db.sslmode"),
 		Password: os.Getenv("DB_PASSWORD"),
-	})
+	}
+
+	db, err := repository.NewPostgresDB(dbConfig)
 	if err != nil {
 		logrus.Fatalf("failed to initialize db: %s", err.Error())
 	}
 
+	var keyConfigs []keys.Config
+	if err := viper.UnmarshalKey("auth.keys", &keyConfigs); err != nil {
+		logrus.Fatalf("error reading signing keys config: %s", err.Error())
+	}
+
+	keySet, err := keys.Load(keyConfigs)
+	if err != nil {
+		logrus.Fatalf("failed to load signing keys: %s", err.Error())
+	}
+
 	repos := repository.NewRepository(db)
-	services := service.NewService(repos)
+
+	listener, err := repository.NewEventListener(dbConfig)
+	if err != nil {
+		logrus.Fatalf("failed to listen for events: %s", err.Error())
+	}
+
+	broker, err := stream.NewBroker(repos.Event, listener.NotificationChannel(), stream.Config{
+		BatchSize:    viper.GetInt("stream.batch_size"),
+		PollInterval: viper.GetDuration("stream.poll_interval"),
+		Retention:    viper.GetDuration("stream.retention"),
+		Buffer:       viper.GetInt("stream.buffer"),
+		ReplayLimit:  viper.GetInt("stream.replay_limit"),
+	})
+	if err != nil {
+		logrus.Fatalf("failed to initialize stream broker: %s", err.Error())
+	}
+	go broker.Run()
+
+	if os.Getenv("PASSWORD_SALT") == "" {
+		logrus.Warn("PASSWORD_SALT is not set, users with legacy password hashes can not log in")
+	}
+
+	services := service.NewService(repos, service.Deps{
+		Hasher:          newPasswordHasher(),
+		Keys:            keySet,
+		AccessTokenTTL:  viper.GetDuration("auth.access_token_ttl"),
+		RefreshTokenTTL: viper.GetDuration("auth.refresh_token_ttl"),
+		Stream:          broker,
+		IdempotencyTTL:  viper.GetDuration("idempotency.ttl"),
+		Guard:           newGuard(),
+		EmailDomains:    viper.GetStringSlice("notify.smtp.allowed_domains"),
+	})
 	handlers := handler.NewHandler(services)
 
 	srv := new(server.Server)
@@ -51,22 +102,108 @@ func main() {
 		}
 	}()
 
+	reminders := scheduler.NewReminderScheduler(repos.Reminder, newNotifyChannels(), scheduler.ReminderConfig{
+		Interval:    viper.GetDuration("reminders.interval"),
+		BatchSize:   viper.GetInt("reminders.batch_size"),
+		MaxAttempts: viper.GetInt("reminders.max_attempts"),
+		SendTimeout: viper.GetDuration("reminders.send_timeout"),
+	})
+	go reminders.Run()
+
+	webhooks := scheduler.NewWebhookDispatcher(repos.Webhook, scheduler.WebhookConfig{
+		Interval:    viper.GetDuration("webhooks.interval"),
+		BatchSize:   viper.GetInt("webhooks.batch_size"),
+		MaxAttempts: viper.GetInt("webhooks.max_attempts"),
+		Timeout:     viper.GetDuration("webhooks.timeout"),
+		MinBackoff:  viper.GetDuration("webhooks.min_backoff"),
+		MaxBackoff:  viper.GetDuration("webhooks.max_backoff"),
+		Guard:       newGuard(),
+	})
+	go webhooks.Run()
+
+	syncPruner := scheduler.NewSyncPruner(repos.Sync, scheduler.SyncConfig{
+		Interval:  viper.GetDuration("sync.prune_interval"),
+		Retention: viper.GetDuration("sync.retention"),
+	})
+	go syncPruner.Run()
+
 	logrus.Println("Todo App started")
 
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 	<-quit
 
 	logrus.Println("Todo App is shutting down")
 
+	// streams are not waited for by the server, they end with the broker
+	if err := broker.Shutdown(context.Background()); err != nil {
+		logrus.Errorf("error occured when stream broker was shutting down: %s", err.Error())
+	}
+	if err := listener.Close(); err != nil {
+		logrus.Errorf("error occured when event listener was closing connection: %s", err.Error())
+	}
 	if err := srv.Shutdown(context.Background()); err != nil {
 		logrus.Errorf("error occured when server was shutting down: %s", err.Error())
 	}
+	if err := reminders.Shutdown(context.Background()); err != nil {
+		logrus.Errorf("error occured when reminder scheduler was shutting down: %s", err.Error())
+	}
+	if err := webhooks.Shutdown(context.Background()); err != nil {
+		logrus.Errorf("error occured when webhook dispatcher was shutting down: %s", err.Error())
+	}
+	if err := syncPruner.Shutdown(context.Background()); err != nil {
+		logrus.Errorf("error occured when sync pruner was shutting down: %s", err.Error())
+	}
 	if err := db.Close(); err != nil {
 		logrus.Errorf("error occured when db was closing connection: %s", err.Error())
 	}
 }
 
+func newGuard() netguard.Guard {
+	return netguard.Guard{AllowPrivate: viper.GetBool("outbound.allow_private_networks")}
+}
+
+func newPasswordHasher() hash.PasswordHasher {
+	hasher, err := hash.NewPasswordHasher(hash.Config{
+		Algorithm:  viper.GetString("auth.password.algorithm"),
+		BcryptCost: viper.GetInt("auth.password.bcrypt.cost"),
+		Argon2id: hash.Argon2idParams{
+			Memory:      viper.GetUint32("auth.password.argon2id.memory"),
+			Iterations:  viper.GetUint32("auth.password.argon2id.iterations"),
+			Parallelism: uint8(viper.GetUint("auth.password.argon2id.parallelism")),
+		},
+		LegacySalt: os.Getenv("PASSWORD_SALT"),
+	})
+	if err != nil {
+		logrus.Fatalf("failed to initialize password hasher: %s", err.Error())
+	}
+
+	return hasher
+}
+
+// newNotifyChannels sets up the reminder channels, email is only available
+// once an SMTP server is configured.
+func newNotifyChannels() notify.Channels {
+	channels := notify.Channels{
+		domain.ChannelLog:     notify.NewLogNotifier(),
+		domain.ChannelWebhook: notify.NewWebhookNotifier(viper.GetDuration("notify.webhook.timeout"), newGuard()),
+	}
+
+	if host := viper.GetString("notify.smtp.host"); host != "" {
+		channels[domain.ChannelEmail] = notify.NewSMTPNotifier(notify.SMTPConfig{
+			Host:           host,
+			Port:           viper.GetString("notify.smtp.port"),
+			Username:       viper.GetString("notify.smtp.username"),
+			Password:       os.Getenv("SMTP_PASSWORD"),
+			From:           viper.GetString("notify.smtp.from"),
+			Timeout:        viper.GetDuration("notify.smtp.timeout"),
+			AllowedDomains: viper.GetStringSlice("notify.smtp.allowed_domains"),
+		})
+	}
+
+	return channels
+}
+
 func initConfig() error {
 	viper.AddConfigPath("configs")
 	viper.SetConfigName("config")
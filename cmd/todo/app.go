@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/pkg/client"
+	"github.com/spf13/cobra"
+	"io"
+)
+
+// app is the state shared by the commands.
+type app struct {
+	in  io.Reader
+	out io.Writer
+
+	// flags
+	server string
+	output string
+
+	configPath string
+	config     config
+	client     *client.Client
+}
+
+func newRootCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "todo",
+		Short:         "Manage todo lists and items from the terminal",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.init()
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&a.server, "server", "", "URL of the API, the one logged in to by default")
+	cmd.PersistentFlags().StringVarP(&a.output, "output", "o", outputTable, "output format, table or json")
+	_ = cmd.RegisterFlagCompletionFunc("output", func(*cobra.Command, []string, string) ([]string,
+		cobra.ShellCompDirective) {
+		return []string{outputTable, outputJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	cmd.AddCommand(newLoginCmd(a), newLogoutCmd(a), newListsCmd(a), newAddCmd(a), newDoneCmd(a), newLsCmd(a))
+
+	return cmd
+}
+
+// init loads the config. Completions call it on their own, as they run
+// without the hooks of the commands.
+func (a *app) init() error {
+	if a.configPath != "" {
+		return nil
+	}
+
+	if a.output != outputTable && a.output != outputJSON {
+		return fmt.Errorf("invalid output %q, use %s or %s", a.output, outputTable, outputJSON)
+	}
+
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return fmt.Errorf("error loading config %s: %w", path, err)
+	}
+	a.configPath, a.config = path, cfg
+
+	if a.server == "" {
+		a.server = a.config.Server
+	}
+
+	return nil
+}
+
+// session returns the client of the logged in user.
+func (a *app) session() (*client.Client, error) {
+	if a.client == nil {
+		if !a.config.loggedIn() {
+			return nil, errors.New("not logged in, run todo login")
+		}
+		a.client = client.New(a.server, client.WithTokens(a.config.tokens()))
+	}
+
+	return a.client, nil
+}
+
+// saveSession stores the tokens of the session when they were refreshed.
+func (a *app) saveSession() error {
+	if a.client == nil {
+		return nil
+	}
+
+	tokens := a.client.Tokens()
+	if tokens.AccessToken == a.config.AccessToken && tokens.RefreshToken == a.config.RefreshToken {
+		return nil
+	}
+	a.config.setTokens(tokens)
+
+	return a.config.save(a.configPath)
+}
+
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+
+	return context.Background()
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/pkg/client"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"strings"
+)
+
+func newLoginCmd(a *app) *cobra.Command {
+	var username, token string
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Log in to the API",
+		Long: "Log in with a username and a password, which are asked for unless given, or with a personal " +
+			"access token. The credentials are kept in the config file of the user.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if token != "" {
+				a.config.Server, a.config.Username = a.server, ""
+				a.config.setTokens(domain.Tokens{AccessToken: token})
+				if err := a.config.save(a.configPath); err != nil {
+					return err
+				}
+
+				a.message("Logged in to %s with an access token", a.server)
+				return nil
+			}
+
+			return a.login(commandContext(cmd), cmd.ErrOrStderr(), username)
+		},
+	}
+
+	cmd.Flags().StringVarP(&username, "username", "u", "", "name of the user")
+	cmd.Flags().StringVar(&token, "token", "", "personal access token to log in with")
+
+	return cmd
+}
+
+func (a *app) login(ctx context.Context, prompt io.Writer, username string) error {
+	input := bufio.NewReader(a.in)
+
+	var err error
+	if username == "" {
+		fmt.Fprint(prompt, "Username: ")
+		if username, err = readLine(input); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprint(prompt, "Password: ")
+	password, err := a.readPassword(input)
+	fmt.Fprintln(prompt)
+	if err != nil {
+		return err
+	}
+
+	a.client = client.New(a.server)
+	if err := a.client.SignIn(ctx, username, password); err != nil {
+		return err
+	}
+
+	a.config.Server, a.config.Username = a.server, username
+	a.config.setTokens(a.client.Tokens())
+	if err := a.config.save(a.configPath); err != nil {
+		return err
+	}
+
+	a.message("Logged in to %s as %s", a.server, username)
+
+	return nil
+}
+
+// readPassword reads the password without echoing it, when it is typed in a
+// terminal.
+func (a *app) readPassword(input *bufio.Reader) (string, error) {
+	if f, ok := a.in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		password, err := term.ReadPassword(int(f.Fd()))
+		return string(password), err
+	}
+
+	return readLine(input)
+}
+
+func readLine(input *bufio.Reader) (string, error) {
+	line, err := input.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func newLogoutCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Log out and forget the credentials",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !a.config.loggedIn() {
+				a.message("Not logged in")
+				return nil
+			}
+
+			// access tokens live on until they are revoked, only sessions end
+			if a.config.RefreshToken != "" {
+				c, err := a.session()
+				if err != nil {
+					return err
+				}
+
+				if err := c.Logout(commandContext(cmd)); err != nil && !errors.Is(err, client.ErrUnauthorized) {
+					return err
+				}
+			}
+
+			a.client = nil
+			a.config.Username = ""
+			a.config.setTokens(domain.Tokens{})
+			if err := a.config.save(a.configPath); err != nil {
+				return err
+			}
+
+			a.message("Logged out of %s", a.server)
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const defaultServer = "http://localhost:8000"
+
+// config is kept in $XDG_CONFIG_HOME/todo/config.json, ~/.config/todo on most
+// systems. It holds the credentials, so only the user may read it.
+type config struct {
+	Server       string     `json:"server"`
+	Username     string     `json:"username,omitempty"`
+	AccessToken  string     `json:"access_token,omitempty"`
+	RefreshToken string     `json:"refresh_token,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
+
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "todo", "config.json"), nil
+}
+
+// loadConfig reads the config, a missing one is empty.
+func loadConfig(path string) (config, error) {
+	cfg := config{Server: defaultServer}
+
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	return cfg, json.Unmarshal(data, &cfg)
+}
+
+func (c config) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0600)
+}
+
+func (c config) loggedIn() bool {
+	return c.AccessToken != ""
+}
+
+// tokens returns the stored tokens, with the time left until the access token
+// expires.
+func (c config) tokens() domain.Tokens {
+	tokens := domain.Tokens{AccessToken: c.AccessToken, RefreshToken: c.RefreshToken}
+	if c.ExpiresAt != nil {
+		tokens.ExpiresIn = int64(time.Until(*c.ExpiresAt) / time.Second)
+		// an expired token is still refreshed before it is used
+		if tokens.ExpiresIn <= 0 {
+			tokens.ExpiresIn = 1
+		}
+	}
+
+	return tokens
+}
+
+func (c *config) setTokens(tokens domain.Tokens) {
+	c.AccessToken, c.RefreshToken, c.ExpiresAt = tokens.AccessToken, tokens.RefreshToken, nil
+	if tokens.ExpiresIn > 0 {
+		expiresAt := time.Now().Add(time.Duration(tokens.ExpiresIn) * time.Second)
+		c.ExpiresAt = &expiresAt
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// parseDue reads a due date relative to now: today, tomorrow, a weekday, which
+// is the coming one, a date or an RFC 3339 time. Items due on a day are due by
+// its end in the location of now. The result is in UTC, the form the API
+// stores.
+func parseDue(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return endOfDay(t), nil
+	}
+
+	lower := strings.ToLower(value)
+	switch lower {
+	case "today":
+		return endOfDay(now), nil
+	case "tomorrow":
+		return endOfDay(now.AddDate(0, 0, 1)), nil
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if lower == name || lower == name[:3] {
+			days := (int(day) - int(now.Weekday()) + 7) % 7
+			return endOfDay(now.AddDate(0, 0, days)), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid due date %q, use today, tomorrow, a weekday, YYYY-MM-DD or RFC 3339",
+		value)
+}
+
+func endOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+
+	return time.Date(year, month, day, 23, 59, 59, 0, t.Location()).UTC()
+}
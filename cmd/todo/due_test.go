@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseDue(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name          string
+		value         string
+		expectedDue   time.Time
+		expectedError bool
+	}{
+		{
+			name:        "Today",
+			value:       "today",
+			expectedDue: time.Date(2026, 10, 14, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:        "Tomorrow",
+			value:       "Tomorrow",
+			expectedDue: time.Date(2026, 10, 15, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:        "Weekday",
+			value:       "friday",
+			expectedDue: time.Date(2026, 10, 16, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:        "Short Weekday",
+			value:       "mon",
+			expectedDue: time.Date(2026, 10, 19, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:        "Same Weekday",
+			value:       "wednesday",
+			expectedDue: time.Date(2026, 10, 14, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:        "Date",
+			value:       "2026-12-24",
+			expectedDue: time.Date(2026, 12, 24, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:        "Time",
+			value:       "2026-12-24T18:00:00Z",
+			expectedDue: time.Date(2026, 12, 24, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "Invalid",
+			value:         "someday",
+			expectedError: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			due, err := parseDue(testCase.value, now)
+
+			if testCase.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, testCase.expectedDue.Equal(due), "got %s", due)
+			}
+		})
+	}
+}
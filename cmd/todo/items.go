@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/spf13/cobra"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func newAddCmd(a *app) *cobra.Command {
+	var list, due, priority string
+
+	cmd := &cobra.Command{
+		Use:     "add TITLE",
+		Short:   "Add an item to a list",
+		Example: `  todo add "Send the report" --list work --due friday`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			item := domain.TodoItem{Title: args[0], Priority: priority}
+			if due != "" {
+				dueAt, err := parseDue(due, time.Now())
+				if err != nil {
+					return err
+				}
+				item.DueAt = &dueAt
+			}
+
+			c, err := a.session()
+			if err != nil {
+				return err
+			}
+
+			ctx := commandContext(cmd)
+			listId, err := resolveList(ctx, c, list)
+			if err != nil {
+				return err
+			}
+
+			id, err := c.CreateItem(ctx, listId, item)
+			if err != nil {
+				return err
+			}
+
+			return a.print(map[string]int{"id": id}, func(w io.Writer) {
+				fmt.Fprintf(w, "Added item %d\n", id)
+			})
+		},
+	}
+
+	a.addListFlag(cmd, &list)
+	cmd.Flags().StringVar(&due, "due", "", "due date: today, tomorrow, a weekday, YYYY-MM-DD or RFC 3339")
+	addPriorityFlag(cmd, &priority, "priority of the item")
+
+	return cmd
+}
+
+func newDoneCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "done ID...",
+		Short: "Mark items as done",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids := make([]int, 0, len(args))
+			for _, arg := range args {
+				id, err := strconv.Atoi(arg)
+				if err != nil {
+					return fmt.Errorf("invalid item id %q", arg)
+				}
+				ids = append(ids, id)
+			}
+
+			c, err := a.session()
+			if err != nil {
+				return err
+			}
+
+			for _, id := range ids {
+				if err := c.PatchItem(commandContext(cmd), id, domain.Patch{"done": true}, nil); err != nil {
+					return fmt.Errorf("error completing item %d: %w", id, err)
+				}
+			}
+
+			return a.print(map[string][]int{"ids": ids}, func(w io.Writer) {
+				for _, id := range ids {
+					fmt.Fprintf(w, "Completed item %d\n", id)
+				}
+			})
+		},
+		ValidArgsFunction: func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+}
+
+func newLsCmd(a *app) *cobra.Command {
+	var list, priority string
+	var done, undone bool
+
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "Show the items of a list",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if done && undone {
+				return fmt.Errorf("--done and --undone can not be combined")
+			}
+
+			filter := domain.ItemFilter{Page: domain.Page{Limit: domain.MaxPageLimit}}
+			if done || undone {
+				filter.Done = &done
+			}
+			if priority != "" {
+				filter.Priorities = strings.Split(priority, ",")
+			}
+
+			c, err := a.session()
+			if err != nil {
+				return err
+			}
+
+			ctx := commandContext(cmd)
+			listId, err := resolveList(ctx, c, list)
+			if err != nil {
+				return err
+			}
+
+			items := []domain.TodoItem{}
+			for {
+				page, err := c.GetItems(ctx, listId, filter)
+				if err != nil {
+					return err
+				}
+				items = append(items, page.Data...)
+
+				if page.NextCursor == "" {
+					break
+				}
+				filter.Cursor = page.NextCursor
+			}
+
+			return a.print(items, func(w io.Writer) {
+				fmt.Fprintln(w, "ID\tDONE\tPRIORITY\tDUE\tTITLE")
+				for _, item := range items {
+					mark := ""
+					if item.Done {
+						mark = "x"
+					}
+					fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", item.Id, mark, item.Priority, formatTime(item.DueAt),
+						item.Title)
+				}
+			})
+		},
+	}
+
+	a.addListFlag(cmd, &list)
+	cmd.Flags().BoolVar(&done, "done", false, "show only the items that are done")
+	cmd.Flags().BoolVar(&undone, "undone", false, "show only the items that are not done")
+	addPriorityFlag(cmd, &priority, "show only the items of these priorities, separated by commas")
+
+	return cmd
+}
+
+func addPriorityFlag(cmd *cobra.Command, priority *string, usage string) {
+	cmd.Flags().StringVarP(priority, "priority", "p", "", usage+": "+strings.Join(domain.Priorities, ", "))
+	_ = cmd.RegisterFlagCompletionFunc("priority", func(*cobra.Command, []string, string) ([]string,
+		cobra.ShellCompDirective) {
+		return domain.Priorities, cobra.ShellCompDirectiveNoFileComp
+	})
+}
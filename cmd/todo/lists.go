@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/pkg/client"
+	"github.com/spf13/cobra"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func newListsCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "lists",
+		Short: "Show the lists",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := a.session()
+			if err != nil {
+				return err
+			}
+
+			lists, err := allLists(commandContext(cmd), c)
+			if err != nil {
+				return err
+			}
+
+			return a.print(lists, func(w io.Writer) {
+				fmt.Fprintln(w, "ID\tTITLE\tROLE")
+				for _, list := range lists {
+					fmt.Fprintf(w, "%d\t%s\t%s\n", list.Id, list.Title, list.Role)
+				}
+			})
+		},
+	}
+}
+
+// allLists reads every page of lists.
+func allLists(ctx context.Context, c *client.Client) ([]domain.TodoList, error) {
+	filter := domain.ListFilter{Page: domain.Page{Limit: domain.MaxPageLimit}}
+
+	lists := []domain.TodoList{}
+	for {
+		page, err := c.GetLists(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, page.Data...)
+
+		if page.NextCursor == "" {
+			return lists, nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+// resolveList returns the id of the list given by its id or title.
+func resolveList(ctx context.Context, c *client.Client, name string) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+
+	lists, err := allLists(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+
+	id := 0
+	for _, list := range lists {
+		if !strings.EqualFold(list.Title, name) {
+			continue
+		}
+
+		if id != 0 {
+			return 0, fmt.Errorf("there are several lists called %q, use the id of one", name)
+		}
+		id = list.Id
+	}
+
+	if id == 0 {
+		return 0, fmt.Errorf("list %q not found", name)
+	}
+
+	return id, nil
+}
+
+// addListFlag adds the required --list flag, completed with the titles of the
+// lists.
+func (a *app) addListFlag(cmd *cobra.Command, list *string) {
+	cmd.Flags().StringVarP(list, "list", "l", "", "title or id of the list")
+	_ = cmd.MarkFlagRequired("list")
+	_ = cmd.RegisterFlagCompletionFunc("list", func(cmd *cobra.Command, args []string,
+		toComplete string) ([]string, cobra.ShellCompDirective) {
+		if err := a.init(); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		c, err := a.session()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		lists, err := allLists(commandContext(cmd), c)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		titles := make([]string, 0, len(lists))
+		for _, list := range lists {
+			titles = append(titles, list.Title)
+		}
+
+		return titles, cobra.ShellCompDirectiveNoFileComp
+	})
+}
@@ -0,0 +1,35 @@
+// Command todo manages todo lists and items from the terminal through the
+// HTTP API.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "todo:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command line args. The tokens of the session are saved
+// even when the command failed, since they may have been refreshed before.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	a := &app{in: stdin, out: stdout}
+
+	cmd := newRootCmd(a)
+	cmd.SetArgs(args)
+	cmd.SetIn(stdin)
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+
+	err := cmd.Execute()
+	if saveErr := a.saveSession(); err == nil {
+		err = saveErr
+	}
+
+	return err
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// print writes v as JSON or, in the table output, what table writes.
+func (a *app) print(v interface{}, table func(w io.Writer)) error {
+	if a.output == outputJSON {
+		encoder := json.NewEncoder(a.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	table(w)
+
+	return w.Flush()
+}
+
+// message tells what a command did, it is left out of the JSON output.
+func (a *app) message(format string, args ...interface{}) {
+	if a.output == outputTable {
+		fmt.Fprintf(a.out, format+"\n", args...)
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/handler"
+	"github.com/pavel-trbv/go-todo-app/internal/service"
+	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mocks struct {
+	auth  *mock_service.MockAuthorization
+	lists *mock_service.MockTodoList
+	items *mock_service.MockTodoItem
+}
+
+// setup serves the handler of mocked services and points the config at a
+// temporary directory.
+func setup(t *testing.T) (mocks, *httptest.Server, string) {
+	c := gomock.NewController(t)
+	t.Cleanup(c.Finish)
+
+	m := mocks{
+		auth:  mock_service.NewMockAuthorization(c),
+		lists: mock_service.NewMockTodoList(c),
+		items: mock_service.NewMockTodoItem(c),
+	}
+
+	services := &service.Service{Authorization: m.auth, TodoList: m.lists, TodoItem: m.items}
+	server := httptest.NewServer(handler.NewHandler(services).InitRoutes())
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	return m, server, filepath.Join(dir, "todo", "config.json")
+}
+
+func TestLogin(t *testing.T) {
+	m, server, path := setup(t)
+	m.auth.EXPECT().GenerateTokens("user", "qwerty").
+		Return(domain.Tokens{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 900}, nil)
+
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"login", "--server", server.URL}, strings.NewReader("user\nqwerty\n"), &stdout, &stderr)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Logged in to "+server.URL+" as user\n", stdout.String())
+	assert.Equal(t, "Username: Password: \n", stderr.String())
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	cfg, err := loadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL, cfg.Server)
+	assert.Equal(t, "user", cfg.Username)
+	assert.Equal(t, "access", cfg.AccessToken)
+	assert.Equal(t, "refresh", cfg.RefreshToken)
+	assert.NotNil(t, cfg.ExpiresAt)
+}
+
+func TestCommands(t *testing.T) {
+	lists := domain.ListPage{Data: []domain.TodoList{
+		{Id: 1, Title: "Home", Role: domain.RoleOwner},
+		{Id: 2, Title: "Work", Role: domain.RoleEditor},
+	}}
+	items := domain.ItemPage{Data: []domain.TodoItem{
+		{Id: 42, Title: "Send the report", Priority: domain.PriorityHigh},
+	}}
+	listFilter := domain.ListFilter{Page: domain.Page{Limit: domain.MaxPageLimit}}
+	undone := false
+
+	testTable := []struct {
+		name           string
+		args           []string
+		mockBehavior   func(m mocks)
+		expectedOutput string
+		expectedError  string
+	}{
+		{
+			name: "Lists",
+			args: []string{"lists"},
+			mockBehavior: func(m mocks) {
+				m.lists.EXPECT().GetAll(1, listFilter).Return(lists, nil)
+			},
+			expectedOutput: "ID  TITLE  ROLE\n1   Home   owner\n2   Work   editor\n",
+		},
+		{
+			name: "Add",
+			args: []string{"add", "Send the report", "--list", "work", "--priority", "high"},
+			mockBehavior: func(m mocks) {
+				m.lists.EXPECT().GetAll(1, listFilter).Return(lists, nil)
+				m.items.EXPECT().Create(1, 2, domain.TodoItem{Title: "Send the report", Priority: "high"}).
+					Return(42, nil)
+			},
+			expectedOutput: "Added item 42\n",
+		},
+		{
+			name: "Add JSON",
+			args: []string{"add", "Send the report", "--list", "2", "-o", "json"},
+			mockBehavior: func(m mocks) {
+				m.items.EXPECT().Create(1, 2, domain.TodoItem{Title: "Send the report"}).Return(42, nil)
+			},
+			expectedOutput: "{\n  \"id\": 42\n}\n",
+		},
+		{
+			name: "Unknown List",
+			args: []string{"add", "Send the report", "--list", "garden"},
+			mockBehavior: func(m mocks) {
+				m.lists.EXPECT().GetAll(1, listFilter).Return(lists, nil)
+			},
+			expectedError: `list "garden" not found`,
+		},
+		{
+			name: "Done",
+			args: []string{"done", "42", "43"},
+			mockBehavior: func(m mocks) {
+				m.items.EXPECT().Patch(1, 42, domain.PatchInput{ContentType: domain.MergePatchType,
+					Body: []byte(`{"done":true}`)}).Return(nil)
+				m.items.EXPECT().Patch(1, 43, domain.PatchInput{ContentType: domain.MergePatchType,
+					Body: []byte(`{"done":true}`)}).Return(nil)
+			},
+			expectedOutput: "Completed item 42\nCompleted item 43\n",
+		},
+		{
+			name: "Done Not Found",
+			args: []string{"done", "44"},
+			mockBehavior: func(m mocks) {
+				m.items.EXPECT().Patch(1, 44, gomock.Any()).Return(domain.NewError(domain.ErrNotFound, "item not found"))
+			},
+			expectedError: "error completing item 44: 404 not_found: item not found",
+		},
+		{
+			name: "Ls",
+			args: []string{"ls", "--list", "Work", "--undone"},
+			mockBehavior: func(m mocks) {
+				m.lists.EXPECT().GetAll(1, listFilter).Return(lists, nil)
+				m.items.EXPECT().GetAll(1, 2, domain.ItemFilter{Page: domain.Page{Limit: domain.MaxPageLimit},
+					Done: &undone}).Return(items, nil)
+			},
+			expectedOutput: "ID  DONE  PRIORITY  DUE  TITLE\n42        high           Send the report\n",
+		},
+		{
+			name:          "Done And Undone",
+			args:          []string{"ls", "--list", "2", "--done", "--undone"},
+			mockBehavior:  func(m mocks) {},
+			expectedError: "--done and --undone can not be combined",
+		},
+		{
+			name:          "Invalid Output",
+			args:          []string{"lists", "-o", "yaml"},
+			mockBehavior:  func(m mocks) {},
+			expectedError: `invalid output "yaml", use table or json`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			m, server, path := setup(t)
+			m.auth.EXPECT().ParseToken("access").Return(domain.Identity{UserId: 1, SessionId: 1}, nil).AnyTimes()
+			testCase.mockBehavior(m)
+
+			cfg := config{Server: server.URL, Username: "user", AccessToken: "access", RefreshToken: "refresh"}
+			assert.NoError(t, cfg.save(path))
+
+			var stdout, stderr bytes.Buffer
+			err := run(testCase.args, strings.NewReader(""), &stdout, &stderr)
+
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expectedOutput, stdout.String())
+			}
+		})
+	}
+}
+
+func TestSession(t *testing.T) {
+	m, server, path := setup(t)
+	m.auth.EXPECT().ParseToken("old").Return(domain.Identity{}, service.ErrInvalidToken)
+	m.auth.EXPECT().RefreshTokens("refresh").
+		Return(domain.Tokens{AccessToken: "new", RefreshToken: "refresh2", ExpiresIn: 900}, nil)
+	m.auth.EXPECT().ParseToken("new").Return(domain.Identity{UserId: 1, SessionId: 1}, nil).Times(2)
+	m.lists.EXPECT().GetAll(1, gomock.Any()).Return(domain.ListPage{}, errors.New("something went wrong"))
+	m.auth.EXPECT().Logout(1).Return(nil)
+
+	cfg := config{Server: server.URL, Username: "user", AccessToken: "old", RefreshToken: "refresh"}
+	assert.NoError(t, cfg.save(path))
+
+	// the refreshed tokens are kept although the command failed
+	var stdout, stderr bytes.Buffer
+	assert.Error(t, run([]string{"lists"}, strings.NewReader(""), &stdout, &stderr))
+
+	cfg, err := loadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", cfg.AccessToken)
+	assert.Equal(t, "refresh2", cfg.RefreshToken)
+
+	assert.NoError(t, run([]string{"logout"}, strings.NewReader(""), &stdout, &stderr))
+
+	cfg, err = loadConfig(path)
+	assert.NoError(t, err)
+	assert.Equal(t, config{Server: server.URL}, cfg)
+
+	assert.EqualError(t, run([]string{"lists"}, strings.NewReader(""), &stdout, &stderr),
+		"not logged in, run todo login")
+}
+
+func TestDueRoundTrip(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+9", 9*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	m, server, path := setup(t)
+	m.auth.EXPECT().ParseToken("access").Return(domain.Identity{UserId: 1, SessionId: 1}, nil).AnyTimes()
+
+	var created domain.TodoItem
+	m.items.EXPECT().Create(1, 2, gomock.Any()).DoAndReturn(func(userId, listId int, item domain.TodoItem) (int, error) {
+		created = item
+		return 42, nil
+	})
+
+	cfg := config{Server: server.URL, Username: "user", AccessToken: "access", RefreshToken: "refresh"}
+	assert.NoError(t, cfg.save(path))
+
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"add", "Send the report", "--list", "2", "--due", "2026-12-24"}, strings.NewReader(""),
+		&stdout, &stderr)
+	assert.NoError(t, err)
+
+	if assert.NotNil(t, created.DueAt) {
+		assert.True(t, time.Date(2026, 12, 24, 23, 59, 59, 0, time.Local).Equal(*created.DueAt),
+			"got %s", created.DueAt)
+	}
+
+	created.Id = 42
+	m.items.EXPECT().GetAll(1, 2, gomock.Any()).Return(domain.ItemPage{Data: []domain.TodoItem{created}}, nil)
+
+	stdout.Reset()
+	err = run([]string{"ls", "--list", "2"}, strings.NewReader(""), &stdout, &stderr)
+	assert.NoError(t, err)
+	assert.Contains(t, stdout.String(), "2026-12-24 23:59")
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AccessTokenPrefix tells personal access tokens apart from JWTs in the
+// Authorization header.
+const AccessTokenPrefix = "tdp_"
+
+const (
+	ScopeListsRead   = "lists:read"
+	ScopeListsWrite  = "lists:write"
+	ScopeItemsRead   = "items:read"
+	ScopeItemsWrite  = "items:write"
+	ScopeTokensRead  = "tokens:read"
+	ScopeTokensWrite = "tokens:write"
+
+	ScopeWebhooksRead  = "webhooks:read"
+	ScopeWebhooksWrite = "webhooks:write"
+)
+
+var AllScopes = Scopes{
+	ScopeListsRead,
+	ScopeListsWrite,
+	ScopeItemsRead,
+	ScopeItemsWrite,
+	ScopeTokensRead,
+	ScopeTokensWrite,
+	ScopeWebhooksRead,
+	ScopeWebhooksWrite,
+}
+
+// Scopes are stored as a single space separated string, like OAuth does.
+type Scopes []string
+
+func (s Scopes) Has(scope string) bool {
+	for _, v := range s {
+		if v == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s Scopes) Value() (driver.Value, error) {
+	return strings.Join(s, " "), nil
+}
+
+func (s *Scopes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		*s = strings.Fields(v)
+	case []byte:
+		*s = strings.Fields(string(v))
+	default:
+		return fmt.Errorf("unsupported scopes type %T", src)
+	}
+
+	return nil
+}
+
+type AccessToken struct {
+	Id         int        `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	Scopes     Scopes     `json:"scopes" db:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreatedAccessToken is only returned once, the plain token is never stored.
+type CreatedAccessToken struct {
+	AccessToken
+	Token string `json:"token"`
+}
+
+type CreateAccessTokenInput struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    Scopes     `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (i CreateAccessTokenInput) Validate() error {
+	if len(i.Scopes) == 0 {
+		return NewError(ErrValidation, "at least one scope is required")
+	}
+
+	for _, scope := range i.Scopes {
+		if !AllScopes.Has(scope) {
+			return NewError(ErrValidation, "unknown scope "+scope)
+		}
+	}
+
+	if i.ExpiresAt != nil && i.ExpiresAt.Before(time.Now()) {
+		return NewError(ErrValidation, "expiration date is in the past")
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package domain
+
+import "fmt"
+
+// Bulk modes: an atomic request applies all of its entries or none, a best
+// effort one applies every entry it can.
+const (
+	BulkAtomic     = "atomic"
+	BulkBestEffort = "best_effort"
+)
+
+const (
+	BulkApplied = "applied"
+	BulkFailed  = "failed"
+	// BulkSkipped entries were not applied because another entry of an
+	// atomic request failed.
+	BulkSkipped = "skipped"
+)
+
+// MaxBulkItems bounds the entries of a single bulk request.
+const MaxBulkItems = 100
+
+type BulkCreateItemsInput struct {
+	Mode  string     `json:"mode"`
+	Items []TodoItem `json:"items" binding:"required"`
+}
+
+func (i BulkCreateItemsInput) Validate() error {
+	return validateBulk(i.Mode, len(i.Items))
+}
+
+// BulkUpdateItemsInput applies the same update to every item.
+type BulkUpdateItemsInput struct {
+	Mode   string          `json:"mode"`
+	Ids    []int           `json:"ids" binding:"required"`
+	Update UpdateItemInput `json:"update"`
+}
+
+func (i BulkUpdateItemsInput) Validate() error {
+	if err := validateBulk(i.Mode, len(i.Ids)); err != nil {
+		return err
+	}
+
+	if err := validateBulkIds(i.Ids); err != nil {
+		return err
+	}
+
+	if i.Update.Version != nil {
+		return NewError(ErrValidation, "version is not supported by bulk updates")
+	}
+
+	return i.Update.Validate()
+}
+
+type BulkDeleteItemsInput struct {
+	Mode string `json:"mode"`
+	Ids  []int  `json:"ids" binding:"required"`
+}
+
+func (i BulkDeleteItemsInput) Validate() error {
+	if err := validateBulk(i.Mode, len(i.Ids)); err != nil {
+		return err
+	}
+
+	return validateBulkIds(i.Ids)
+}
+
+func validateBulk(mode string, entries int) error {
+	if mode != "" && mode != BulkAtomic && mode != BulkBestEffort {
+		return NewError(ErrValidation, "invalid mode")
+	}
+
+	if entries == 0 {
+		return NewError(ErrValidation, "no entries given")
+	}
+
+	if entries > MaxBulkItems {
+		return NewError(ErrValidation, fmt.Sprintf("at most %d entries are applied at once", MaxBulkItems))
+	}
+
+	return nil
+}
+
+func validateBulkIds(ids []int) error {
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return NewError(ErrValidation, "invalid id")
+		}
+
+		if seen[id] {
+			return NewError(ErrValidation, fmt.Sprintf("id %d is given twice", id))
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
+
+// BulkResult is the outcome of the entry at Index of a bulk request.
+type BulkResult struct {
+	Index  int    `json:"index"`
+	Id     int    `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkResponse tells whether the changes of a bulk request were committed,
+// an atomic request that failed changed nothing.
+type BulkResponse struct {
+	Committed bool         `json:"committed"`
+	Results   []BulkResult `json:"results"`
+}
+
+// Failed reports whether an entry failed.
+func (r BulkResponse) Failed() bool {
+	for _, result := range r.Results {
+		if result.Status == BulkFailed {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error kinds produced by the repository and service layers. Handlers map
+// them to HTTP status codes, any other error is reported as internal.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// ErrVersionMismatch is the conflict of a change based on a version of a list
+// or item that is not the current one anymore.
+var ErrVersionMismatch = fmt.Errorf("%w: version mismatch", ErrConflict)
+
+// ErrSyncTokenExpired is the conflict of a sync token older than the change
+// log, the client has to sync again without a token.
+var ErrSyncTokenExpired = fmt.Errorf("%w: sync token expired", ErrConflict)
+
+// Error is an error of one of the kinds above with a message that is safe to
+// show to the client.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func NewError(kind error, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
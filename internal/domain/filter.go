@@ -0,0 +1,114 @@
+package domain
+
+import "time"
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+const (
+	SortByPosition  = "position"
+	SortById        = "id"
+	SortByTitle     = "title"
+	SortByPriority  = "priority"
+	SortByDueAt     = "due_at"
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+)
+
+var (
+	ListSortFields = []string{SortByPosition, SortById, SortByTitle}
+	ItemSortFields = []string{SortByPosition, SortById, SortByTitle, SortByPriority, SortByDueAt, SortByCreatedAt,
+		SortByUpdatedAt}
+)
+
+// Page holds the part of a collection shared by every filter: ordering and
+// the opaque cursor returned as next_cursor by the previous page.
+type Page struct {
+	Sort   string
+	Order  string
+	Limit  int
+	Cursor string
+}
+
+func (p *Page) normalize(sortFields []string) error {
+	if p.Sort == "" {
+		p.Sort = SortByPosition
+	}
+	if !contains(sortFields, p.Sort) {
+		return NewError(ErrValidation, "invalid sort field")
+	}
+
+	if p.Order == "" {
+		p.Order = SortAsc
+	}
+	if p.Order != SortAsc && p.Order != SortDesc {
+		return NewError(ErrValidation, "invalid sort order")
+	}
+
+	if p.Limit == 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit < 0 || p.Limit > MaxPageLimit {
+		return NewError(ErrValidation, "invalid limit")
+	}
+
+	return nil
+}
+
+type ListFilter struct {
+	Page
+	Query string
+}
+
+// Normalize validates the filter and fills in the defaults.
+func (f *ListFilter) Normalize() error {
+	return f.Page.normalize(ListSortFields)
+}
+
+type ItemFilter struct {
+	Page
+	Done       *bool
+	Priorities []string
+	DueBefore  *time.Time
+	DueAfter   *time.Time
+	LabelId    int
+	Query      string
+}
+
+// Normalize validates the filter and fills in the defaults.
+func (f *ItemFilter) Normalize() error {
+	for _, priority := range f.Priorities {
+		if !validPriority(priority) {
+			return NewError(ErrValidation, "invalid priority")
+		}
+	}
+
+	return f.Page.normalize(ItemSortFields)
+}
+
+type ListPage struct {
+	Data       []TodoList `json:"data"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+type ItemPage struct {
+	Data       []TodoItem `json:"data"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
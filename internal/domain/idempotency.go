@@ -0,0 +1,11 @@
+package domain
+
+// IdempotentResponse is the response to a request made with an idempotency
+// key. RequestHash tells a retry of the request from another request reusing
+// the key.
+type IdempotentResponse struct {
+	RequestHash string `db:"request_hash"`
+	StatusCode  int    `db:"status_code"`
+	ContentType string `db:"content_type"`
+	Body        []byte `db:"body"`
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"regexp"
+	"unicode/utf8"
+)
+
+const (
+	DefaultLabelColor  = "#9e9e9e"
+	maxLabelNameLength = 64
+)
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Label is owned by a single user, who can attach it to any item of the
+// lists they are a member of. Other members of the list do not see it.
+type Label struct {
+	Id    int    `json:"id" db:"id"`
+	Name  string `json:"name" db:"name" binding:"required"`
+	Color string `json:"color" db:"color"`
+}
+
+func (l Label) Validate() error {
+	if err := validateLabelName(l.Name); err != nil {
+		return err
+	}
+
+	if l.Color != "" && !labelColorPattern.MatchString(l.Color) {
+		return NewError(ErrValidation, "color must be a hex code like #ff0000")
+	}
+
+	return nil
+}
+
+type UpdateLabelInput struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+func (i UpdateLabelInput) Validate() error {
+	if i.Name == nil && i.Color == nil {
+		return NewError(ErrValidation, "update structure has no value")
+	}
+
+	if i.Name != nil {
+		if err := validateLabelName(*i.Name); err != nil {
+			return err
+		}
+	}
+
+	if i.Color != nil && !labelColorPattern.MatchString(*i.Color) {
+		return NewError(ErrValidation, "color must be a hex code like #ff0000")
+	}
+
+	return nil
+}
+
+// Patch returns the fields the input changes.
+func (i UpdateLabelInput) Patch() Patch {
+	patch := make(Patch)
+	if i.Name != nil {
+		patch["name"] = *i.Name
+	}
+	if i.Color != nil {
+		patch["color"] = *i.Color
+	}
+
+	return patch
+}
+
+func validateLabelName(name string) error {
+	if name == "" {
+		return NewError(ErrValidation, "label name must not be empty")
+	}
+
+	if utf8.RuneCountInString(name) > maxLabelNameLength {
+		return NewError(ErrValidation, "label name is too long")
+	}
+
+	return nil
+}
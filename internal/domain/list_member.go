@@ -0,0 +1,74 @@
+package domain
+
+import "time"
+
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+var (
+	ErrAlreadyMember = NewError(ErrConflict, "user is already a member of the list")
+	ErrLastOwner     = NewError(ErrConflict, "list must keep at least one owner")
+)
+
+// WriteRoles may change the list and its items, only owners may delete the
+// list or manage its members.
+var WriteRoles = []string{RoleOwner, RoleEditor}
+
+func CanWrite(role string) bool {
+	return role == RoleOwner || role == RoleEditor
+}
+
+func validRole(role string) bool {
+	return role == RoleOwner || role == RoleEditor || role == RoleViewer
+}
+
+type ListMember struct {
+	UserId   int    `json:"user_id" db:"user_id"`
+	Name     string `json:"name" db:"name"`
+	Username string `json:"username" db:"username"`
+	Role     string `json:"role" db:"role"`
+}
+
+type Invitation struct {
+	Id              int       `json:"id" db:"id"`
+	ListId          int       `json:"list_id" db:"list_id"`
+	ListTitle       string    `json:"list_title" db:"list_title"`
+	InviterUsername string    `json:"inviter_username" db:"inviter_username"`
+	Role            string    `json:"role" db:"role"`
+	Status          string    `json:"status" db:"status"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+type InviteMemberInput struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+func (i InviteMemberInput) Validate() error {
+	if !validRole(i.Role) {
+		return NewError(ErrValidation, "invalid role")
+	}
+
+	return nil
+}
+
+type UpdateMemberInput struct {
+	Role string `json:"role" binding:"required"`
+}
+
+func (i UpdateMemberInput) Validate() error {
+	if !validRole(i.Role) {
+		return NewError(ErrValidation, "invalid role")
+	}
+
+	return nil
+}
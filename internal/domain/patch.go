@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// Patch holds the new values of the fields of a list or an item by their
+// JSON names. A nil value clears the field.
+type Patch map[string]interface{}
+
+// PatchInput is a JSON merge patch or a JSON patch, told apart by
+// ContentType. It applies only to the given Version, when one is set.
+type PatchInput struct {
+	ContentType string
+	Body        []byte
+	Version     *int
+}
+
+// The fields a patch can change, the ones mapped to true can be cleared.
+var (
+	listPatchFields = map[string]bool{
+		"title":       false,
+		"description": true,
+	}
+	itemPatchFields = map[string]bool{
+		"title":         false,
+		"description":   true,
+		"done":          false,
+		"due_at":        true,
+		"priority":      false,
+		"auto_complete": false,
+		"recurrence":    false,
+		"timezone":      false,
+	}
+)
+
+// NewListPatch checks the fields a patch changed in a list, as JSON values,
+// and converts them.
+func NewListPatch(changes map[string]json.RawMessage) (Patch, error) {
+	var input UpdateListInput
+	if err := decodeChanges(changes, listPatchFields, &input); err != nil {
+		return nil, err
+	}
+
+	if err := input.validateValues(); err != nil {
+		return nil, err
+	}
+
+	return withCleared(input.Patch(), changes), nil
+}
+
+// NewItemPatch checks the fields a patch changed in an item, as JSON values,
+// and converts them.
+func NewItemPatch(changes map[string]json.RawMessage) (Patch, error) {
+	var input UpdateItemInput
+	if err := decodeChanges(changes, itemPatchFields, &input); err != nil {
+		return nil, err
+	}
+
+	if err := input.validateValues(); err != nil {
+		return nil, err
+	}
+
+	return withCleared(input.Patch(), changes), nil
+}
+
+// decodeChanges decodes the changed fields one by one into an update input,
+// so that a value of the wrong type is reported by its field.
+func decodeChanges(changes map[string]json.RawMessage, fields map[string]bool, input interface{}) error {
+	for field, value := range changes {
+		nullable, ok := fields[field]
+		if !ok {
+			return NewError(ErrValidation, fmt.Sprintf("field %s can not be changed", field))
+		}
+
+		if isNull(value) {
+			if !nullable {
+				return NewError(ErrValidation, fmt.Sprintf("field %s can not be null", field))
+			}
+			continue
+		}
+
+		b, err := json.Marshal(map[string]json.RawMessage{field: value})
+		if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(b, input); err != nil {
+			return NewError(ErrValidation, fmt.Sprintf("invalid value of field %s", field))
+		}
+	}
+
+	return nil
+}
+
+func withCleared(patch Patch, changes map[string]json.RawMessage) Patch {
+	for field, value := range changes {
+		if isNull(value) {
+			patch[field] = nil
+		}
+	}
+
+	return patch
+}
+
+func isNull(value json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+}
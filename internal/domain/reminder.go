@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelLog     = "log"
+)
+
+var Channels = []string{ChannelEmail, ChannelWebhook, ChannelLog}
+
+// Reminder notifies its owner about an item, either at RemindAt or
+// OffsetMinutes before the due date of the item. Offset reminders follow the
+// due date when it changes and never fire for items without one. Target is
+// the address of the channel: an email address or a webhook URL, the log
+// channel has none.
+type Reminder struct {
+	Id            int        `json:"id" db:"id"`
+	ItemId        int        `json:"item_id" db:"item_id"`
+	RemindAt      *time.Time `json:"remind_at" db:"remind_at"`
+	OffsetMinutes *int       `json:"offset_minutes" db:"offset_minutes"`
+	Channel       string     `json:"channel" db:"channel" binding:"required"`
+	Target        string     `json:"target" db:"target"`
+	SentAt        *time.Time `json:"sent_at" db:"sent_at"`
+}
+
+func (r Reminder) Validate() error {
+	if (r.RemindAt == nil) == (r.OffsetMinutes == nil) {
+		return NewError(ErrValidation, "exactly one of remind_at and offset_minutes is required")
+	}
+
+	if r.OffsetMinutes != nil && *r.OffsetMinutes < 0 {
+		return NewError(ErrValidation, "offset_minutes must not be negative")
+	}
+
+	switch r.Channel {
+	case ChannelEmail:
+		if address, err := mail.ParseAddress(r.Target); err != nil || address.Address != r.Target {
+			return NewError(ErrValidation, "target must be an email address")
+		}
+	case ChannelWebhook:
+		if u, err := url.Parse(r.Target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return NewError(ErrValidation, "target must be an http or https URL")
+		}
+	case ChannelLog:
+		if r.Target != "" {
+			return NewError(ErrValidation, "log reminders have no target")
+		}
+	default:
+		return NewError(ErrValidation, "invalid channel")
+	}
+
+	if len(r.Target) > 255 {
+		return NewError(ErrValidation, "target is too long")
+	}
+
+	return nil
+}
+
+// EmailAllowed reports whether email reminders may be sent to the address.
+// Only addresses at one of the domains are, so that the server can not be
+// used to mail anyone.
+func EmailAllowed(address string, domains []string) bool {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return false
+	}
+
+	for _, domain := range domains {
+		if strings.EqualFold(address[at+1:], domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DueReminder is a reminder claimed by the scheduler together with what the
+// notification tells about its item.
+type DueReminder struct {
+	Reminder
+	UserId    int        `db:"user_id"`
+	ItemTitle string     `db:"item_title"`
+	DueAt     *time.Time `db:"due_at"`
+	FireAt    time.Time  `db:"fire_at"`
+}
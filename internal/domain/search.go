@@ -0,0 +1,52 @@
+package domain
+
+import "strings"
+
+const (
+	SearchResultList = "list"
+	SearchResultItem = "item"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// SearchResult is a list or an item matching a search query. Snippet is
+// HTML: the matching text, escaped, with the hits wrapped in <mark> tags.
+type SearchResult struct {
+	Type    string     `json:"type" db:"type"`
+	Id      int        `json:"id" db:"id"`
+	Title   string     `json:"title" db:"title"`
+	Snippet string     `json:"snippet" db:"snippet"`
+	Rank    float64    `json:"rank" db:"rank"`
+	List    SearchList `json:"list" db:"list"`
+}
+
+// SearchList is the list owning a search hit, for lists it is the list itself.
+type SearchList struct {
+	Id    int    `json:"id" db:"id"`
+	Title string `json:"title" db:"title"`
+}
+
+type SearchInput struct {
+	Query string
+	Limit int
+}
+
+// Normalize validates the input and fills in the defaults.
+func (i *SearchInput) Normalize() error {
+	i.Query = strings.TrimSpace(i.Query)
+	if i.Query == "" {
+		return NewError(ErrValidation, "search query is empty")
+	}
+
+	if i.Limit == 0 {
+		i.Limit = DefaultSearchLimit
+	}
+	if i.Limit < 0 || i.Limit > MaxSearchLimit {
+		return NewError(ErrValidation, "invalid limit")
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshToken struct {
+	Id        int        `db:"id"`
+	SessionId int        `db:"session_id"`
+	UserId    int        `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+// Identity is the authenticated caller, either a session started with
+// a password or a personal access token limited to its scopes.
+type Identity struct {
+	UserId        int
+	SessionId     int
+	AccessTokenId int
+	Scopes        Scopes
+}
+
+func (i Identity) HasScope(scope string) bool {
+	if i.AccessTokenId == 0 {
+		return true
+	}
+
+	return i.Scopes.Has(scope)
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "encoding/json"
+
+// EventStreamReset tells a client resuming the change stream that it missed
+// too many events and should reload what it shows.
+const EventStreamReset = "reset"
+
+// StreamEvent is an event as the change stream sends it. Ids grow in the
+// order events are committed, clients resume after the last one they got.
+type StreamEvent struct {
+	Id         int64           `json:"id" db:"id"`
+	Type       string          `json:"event" db:"type"`
+	Payload    json.RawMessage `json:"payload" db:"payload"`
+	Recipients []int           `json:"-" db:"-"`
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	EntityList = "list"
+	EntityItem = "item"
+)
+
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+)
+
+const (
+	SyncApplied  = "applied"
+	SyncConflict = "conflict"
+	SyncRejected = "rejected"
+	SyncFailed   = "failed"
+)
+
+// MaxSyncMutations bounds the mutations applied by a single sync request.
+const MaxSyncMutations = 100
+
+// SyncItem is an item along with the list it is in.
+type SyncItem struct {
+	TodoItem
+	ListId int `json:"list_id" db:"list_id"`
+}
+
+type SyncDeleted struct {
+	Lists []int `json:"lists"`
+	Items []int `json:"items"`
+}
+
+// SyncChanges holds the lists and items that changed since a sync token, as
+// they are now. Deleted holds the ones that are gone or that the user can not
+// see anymore. Token is passed to the next sync.
+type SyncChanges struct {
+	Token   string      `json:"token"`
+	Lists   []TodoList  `json:"lists"`
+	Items   []SyncItem  `json:"items"`
+	Deleted SyncDeleted `json:"deleted"`
+}
+
+// SyncMutation is a change a client made offline. Ref identifies it in the
+// results, an item created in a list created by an earlier mutation of the
+// same batch refers to it with ListRef. Updates and deletes apply only to the
+// Version the client has seen.
+type SyncMutation struct {
+	Ref     string          `json:"ref"`
+	Op      string          `json:"op"`
+	Entity  string          `json:"entity"`
+	Id      int             `json:"id"`
+	ListId  int             `json:"list_id"`
+	ListRef string          `json:"list_ref"`
+	Version *int            `json:"version"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func (m SyncMutation) Validate() error {
+	if m.Ref == "" {
+		return NewError(ErrValidation, "mutation ref must not be empty")
+	}
+
+	if m.Entity != EntityList && m.Entity != EntityItem {
+		return NewError(ErrValidation, fmt.Sprintf("mutation %s: invalid entity", m.Ref))
+	}
+
+	switch m.Op {
+	case SyncCreate:
+		if m.Entity == EntityItem && m.ListId == 0 && m.ListRef == "" {
+			return NewError(ErrValidation, fmt.Sprintf("mutation %s: list_id or list_ref is required", m.Ref))
+		}
+	case SyncUpdate, SyncDelete:
+		if m.Id == 0 || m.Version == nil {
+			return NewError(ErrValidation, fmt.Sprintf("mutation %s: id and version are required", m.Ref))
+		}
+	default:
+		return NewError(ErrValidation, fmt.Sprintf("mutation %s: invalid op", m.Ref))
+	}
+
+	return nil
+}
+
+type SyncInput struct {
+	Mutations []SyncMutation `json:"mutations" binding:"required"`
+}
+
+func (i SyncInput) Validate() error {
+	if len(i.Mutations) > MaxSyncMutations {
+		return NewError(ErrValidation, fmt.Sprintf("at most %d mutations are applied at once", MaxSyncMutations))
+	}
+
+	refs := make(map[string]bool, len(i.Mutations))
+	for _, m := range i.Mutations {
+		if err := m.Validate(); err != nil {
+			return err
+		}
+
+		if refs[m.Ref] {
+			return NewError(ErrValidation, fmt.Sprintf("mutation ref %s is not unique", m.Ref))
+		}
+		refs[m.Ref] = true
+	}
+
+	return nil
+}
+
+// SyncResult is the outcome of a mutation. A conflict carries the entity as
+// it is now, or nothing when it is gone.
+type SyncResult struct {
+	Ref     string      `json:"ref"`
+	Status  string      `json:"status"`
+	Id      int         `json:"id,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Current interface{} `json:"current,omitempty"`
+}
@@ -1,24 +1,106 @@
 package domain
 
-import "errors"
+import (
+	"github.com/pavel-trbv/go-todo-app/internal/recurrence"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	PriorityNone   = "none"
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+var Priorities = []string{PriorityNone, PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+// DefaultTimezone is used to expand recurrence rules of items created
+// without a timezone.
+const DefaultTimezone = "UTC"
+
+func validPriority(priority string) bool {
+	return contains(Priorities, priority)
+}
+
+func validateRecurrence(rule string) error {
+	if _, err := recurrence.Parse(rule); err != nil {
+		return NewError(ErrValidation, "invalid recurrence: "+err.Error())
+	}
+
+	return nil
+}
+
+func validateTimezone(name string) error {
+	if _, err := time.LoadLocation(name); err != nil || name == "" {
+		return NewError(ErrValidation, "invalid timezone")
+	}
+
+	return nil
+}
 
 type TodoList struct {
-	Id          int    `json:"id" db:"id"`
-	Title       string `json:"title" db:"title" binding:"required"`
-	Description string `json:"description" db:"description"`
+	Id          int     `json:"id" db:"id"`
+	Title       string  `json:"title" db:"title" binding:"required"`
+	Description *string `json:"description" db:"description"`
+	Role        string  `json:"role,omitempty" db:"role"`
+	Position    string  `json:"position" db:"position"`
+	Version     int     `json:"version" db:"version"`
 }
 
 type UsersList struct {
 	Id     int
 	UserId int
 	ListId int
+	Role   string
 }
 
+// TodoItem may have child items. Children live in the same list as their
+// parent, ParentId is nil for top level items. A parent with AutoComplete set
+// is done exactly while all of its children are.
+//
+// An item with a Recurrence rule (see internal/recurrence) is the open
+// occurrence of a series: completing it creates the next occurrence, which
+// carries the rule on, with its due date computed in Timezone. Clearing the
+// rule stops the series.
 type TodoItem struct {
-	Id          int    `json:"id" db:"id"`
-	Title       string `json:"title" db:"title" binding:"required"`
-	Description string `json:"description" db:"description"`
-	Done        bool   `json:"done" db:"done"`
+	Id           int        `json:"id" db:"id"`
+	Title        string     `json:"title" db:"title" binding:"required"`
+	Description  *string    `json:"description" db:"description"`
+	Done         bool       `json:"done" db:"done"`
+	DueAt        *time.Time `json:"due_at" db:"due_at"`
+	Priority     string     `json:"priority" db:"priority"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt  *time.Time `json:"completed_at" db:"completed_at"`
+	ParentId     *int       `json:"parent_id" db:"parent_id"`
+	Position     string     `json:"position" db:"position"`
+	AutoComplete bool       `json:"auto_complete" db:"auto_complete"`
+	Recurrence   string     `json:"recurrence" db:"recurrence"`
+	Timezone     string     `json:"timezone" db:"timezone"`
+	Version      int        `json:"version" db:"version"`
+	Labels       []Label    `json:"labels" db:"-"`
+}
+
+func (i TodoItem) Validate() error {
+	if i.Priority != "" && !validPriority(i.Priority) {
+		return NewError(ErrValidation, "invalid priority")
+	}
+
+	if i.Recurrence != "" {
+		if err := validateRecurrence(i.Recurrence); err != nil {
+			return err
+		}
+	}
+
+	if i.Timezone != "" {
+		if err := validateTimezone(i.Timezone); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 type ListsItem struct {
@@ -27,29 +109,180 @@ type ListsItem struct {
 	ItemId int
 }
 
+// UpdateListInput and UpdateItemInput apply only to the given Version,
+// when one is set.
 type UpdateListInput struct {
 	Title       *string `json:"title"`
 	Description *string `json:"description"`
+	Version     *int    `json:"version"`
 }
 
 func (i UpdateListInput) Validate() error {
 	if i.Title == nil && i.Description == nil {
-		return errors.New("update structure has no value")
+		return NewError(ErrValidation, "update structure has no value")
+	}
+
+	return i.validateValues()
+}
+
+func (i UpdateListInput) validateValues() error {
+	if i.Title != nil && *i.Title == "" {
+		return NewError(ErrValidation, "title must not be empty")
 	}
 
 	return nil
 }
 
+// Patch returns the fields the input changes.
+func (i UpdateListInput) Patch() Patch {
+	patch := make(Patch)
+	if i.Title != nil {
+		patch["title"] = *i.Title
+	}
+	if i.Description != nil {
+		patch["description"] = *i.Description
+	}
+
+	return patch
+}
+
 type UpdateItemInput struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Done        *bool   `json:"done"`
+	Title        *string    `json:"title"`
+	Description  *string    `json:"description"`
+	Done         *bool      `json:"done"`
+	DueAt        *time.Time `json:"due_at"`
+	Priority     *string    `json:"priority"`
+	AutoComplete *bool      `json:"auto_complete"`
+	// Recurrence set to an empty string stops the series.
+	Recurrence *string `json:"recurrence"`
+	Timezone   *string `json:"timezone"`
+	Version    *int    `json:"version"`
 }
 
 func (i UpdateItemInput) Validate() error {
-	if i.Title == nil && i.Description == nil && i.Done == nil {
-		return errors.New("update structure has no value")
+	if i.Title == nil && i.Description == nil && i.Done == nil && i.DueAt == nil && i.Priority == nil &&
+		i.AutoComplete == nil && i.Recurrence == nil && i.Timezone == nil {
+		return NewError(ErrValidation, "update structure has no value")
+	}
+
+	return i.validateValues()
+}
+
+func (i UpdateItemInput) validateValues() error {
+	if i.Title != nil && *i.Title == "" {
+		return NewError(ErrValidation, "title must not be empty")
+	}
+
+	if i.Priority != nil && !validPriority(*i.Priority) {
+		return NewError(ErrValidation, "invalid priority")
+	}
+
+	if i.Recurrence != nil && *i.Recurrence != "" {
+		if err := validateRecurrence(*i.Recurrence); err != nil {
+			return err
+		}
+	}
+
+	if i.Timezone != nil {
+		if err := validateTimezone(*i.Timezone); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Patch returns the fields the input changes.
+func (i UpdateItemInput) Patch() Patch {
+	patch := make(Patch)
+	if i.Title != nil {
+		patch["title"] = *i.Title
+	}
+	if i.Description != nil {
+		patch["description"] = *i.Description
+	}
+	if i.Done != nil {
+		patch["done"] = *i.Done
+	}
+	if i.DueAt != nil {
+		patch["due_at"] = *i.DueAt
+	}
+	if i.Priority != nil {
+		patch["priority"] = *i.Priority
+	}
+	if i.AutoComplete != nil {
+		patch["auto_complete"] = *i.AutoComplete
+	}
+	if i.Recurrence != nil {
+		patch["recurrence"] = *i.Recurrence
+	}
+	if i.Timezone != nil {
+		patch["timezone"] = *i.Timezone
+	}
+
+	return patch
+}
+
+type ReorderItemsInput struct {
+	Ids []int `json:"ids" binding:"required"`
+}
+
+// MoveInput places a list or an item right after AfterId and/or right before
+// BeforeId, both have to be its siblings. Giving a single neighbour is enough.
+// Items can also be moved to another list with ListId, they become top level
+// items there and are appended unless a neighbour is given.
+type MoveInput struct {
+	ListId   *int `json:"list_id"`
+	AfterId  *int `json:"after_id"`
+	BeforeId *int `json:"before_id"`
+}
+
+func (i MoveInput) Validate() error {
+	if i.ListId == nil && i.AfterId == nil && i.BeforeId == nil {
+		return NewError(ErrValidation, "list_id, after_id or before_id is required")
 	}
 
 	return nil
 }
+
+// CopyItemInput names the list receiving a deep copy of an item. Without it
+// the copy is placed right after the original.
+type CopyItemInput struct {
+	ListId *int `json:"list_id"`
+}
+
+type DuplicateListInput struct {
+	Title *string `json:"title"`
+}
+
+// maxTitleLength is the length of the title column of lists and items.
+const maxTitleLength = 255
+
+const copySuffix = " (copy)"
+
+func (i DuplicateListInput) Validate() error {
+	if i.Title == nil {
+		return nil
+	}
+
+	if *i.Title == "" {
+		return NewError(ErrValidation, "title must not be empty")
+	}
+
+	if utf8.RuneCountInString(*i.Title) > maxTitleLength {
+		return NewError(ErrValidation, "title is too long")
+	}
+
+	return nil
+}
+
+// CopyTitle returns the title of a copy, the original is shortened when the
+// suffix would not fit otherwise.
+func CopyTitle(title string) string {
+	runes := []rune(title)
+	if max := maxTitleLength - len(copySuffix); len(runes) > max {
+		runes = runes[:max]
+	}
+
+	return string(runes) + copySuffix
+}
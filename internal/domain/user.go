@@ -5,4 +5,6 @@ type User struct {
 	Name     string `json:"name" binding:"required"`
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+
+	PasswordHash string `json:"-" db:"password_hash"`
 }
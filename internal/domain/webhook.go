@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+const (
+	EventListCreated   = "list.created"
+	EventListUpdated   = "list.updated"
+	EventListDeleted   = "list.deleted"
+	EventItemCreated   = "item.created"
+	EventItemUpdated   = "item.updated"
+	EventItemCompleted = "item.completed"
+	EventItemDeleted   = "item.deleted"
+)
+
+var EventTypes = []string{EventListCreated, EventListUpdated, EventListDeleted, EventItemCreated, EventItemUpdated,
+	EventItemCompleted, EventItemDeleted}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Event is a change of a list or of one of its items. It is delivered to
+// the webhooks of the members of the list, and to the ones of the user who
+// caused it. Recipients, when set, replace the members of the list: a deleted
+// list has none left, so its former members are captured beforehand.
+type Event struct {
+	Type       string      `json:"event"`
+	ActorId    int         `json:"actor_id"`
+	ListId     int         `json:"list_id"`
+	Data       interface{} `json:"data"`
+	CreatedAt  time.Time   `json:"created_at"`
+	Recipients []int       `json:"-"`
+}
+
+// Webhook subscribes a URL to some event types of the lists of its owner.
+// Deliveries are signed with Secret, which is only revealed on creation.
+type Webhook struct {
+	Id        int       `json:"id" db:"id"`
+	URL       string    `json:"url" db:"url"`
+	Secret    string    `json:"-" db:"secret"`
+	Events    []string  `json:"events" db:"-"`
+	Active    bool      `json:"active" db:"active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateWebhookInput struct {
+	URL    string   `json:"url" binding:"required"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events" binding:"required"`
+}
+
+func (i CreateWebhookInput) Validate() error {
+	if err := validateWebhookURL(i.URL); err != nil {
+		return err
+	}
+
+	return validateEventTypes(i.Events)
+}
+
+// CreatedWebhook is returned once, it is the only time the secret is shown.
+type CreatedWebhook struct {
+	Id     int    `json:"id"`
+	Secret string `json:"secret"`
+}
+
+type UpdateWebhookInput struct {
+	URL    *string   `json:"url"`
+	Secret *string   `json:"secret"`
+	Events *[]string `json:"events"`
+	Active *bool     `json:"active"`
+}
+
+func (i UpdateWebhookInput) Validate() error {
+	if i.URL == nil && i.Secret == nil && i.Events == nil && i.Active == nil {
+		return NewError(ErrValidation, "update structure has no value")
+	}
+
+	if i.URL != nil {
+		if err := validateWebhookURL(*i.URL); err != nil {
+			return err
+		}
+	}
+
+	if i.Secret != nil && *i.Secret == "" {
+		return NewError(ErrValidation, "secret must not be empty")
+	}
+
+	if i.Events != nil {
+		return validateEventTypes(*i.Events)
+	}
+
+	return nil
+}
+
+// Patch returns the fields the input changes.
+func (i UpdateWebhookInput) Patch() Patch {
+	patch := make(Patch)
+	if i.URL != nil {
+		patch["url"] = *i.URL
+	}
+	if i.Secret != nil {
+		patch["secret"] = *i.Secret
+	}
+	if i.Events != nil {
+		patch["events"] = *i.Events
+	}
+	if i.Active != nil {
+		patch["active"] = *i.Active
+	}
+
+	return patch
+}
+
+// WebhookDelivery is one event sent to a webhook, with the outcome of its
+// last attempt. URL and Secret are those of the webhook at claim time.
+type WebhookDelivery struct {
+	Id             int             `json:"id" db:"id"`
+	WebhookId      int             `json:"webhook_id" db:"webhook_id"`
+	Event          string          `json:"event" db:"event"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at" db:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code" db:"last_status_code"`
+	LastError      *string         `json:"last_error" db:"last_error"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at" db:"delivered_at"`
+	URL            string          `json:"-" db:"url"`
+	Secret         string          `json:"-" db:"secret"`
+}
+
+// DeliveryResult is the outcome of an attempt. A failed attempt is retried
+// after RetryIn, a zero RetryIn gives up on the delivery.
+type DeliveryResult struct {
+	StatusCode int
+	Err        error
+	RetryIn    time.Duration
+}
+
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return NewError(ErrValidation, "url must be an http or https URL")
+	}
+
+	if len(rawURL) > 255 {
+		return NewError(ErrValidation, "url is too long")
+	}
+
+	return nil
+}
+
+func validateEventTypes(events []string) error {
+	if len(events) == 0 {
+		return NewError(ErrValidation, "events must not be empty")
+	}
+
+	for _, event := range events {
+		if !contains(EventTypes, event) {
+			return NewError(ErrValidation, "invalid event type "+event)
+		}
+	}
+
+	return nil
+}
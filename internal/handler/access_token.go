@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) createAccessToken(c *gin.Context) {
+	identity, err := getIdentity(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var input domain.CreateAccessTokenInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	token, err := h.services.AccessToken.Create(identity, input)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+type getAllAccessTokensResponse struct {
+	Data []domain.AccessToken `json:"data"`
+}
+
+func (h *Handler) getAllAccessTokens(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tokens, err := h.services.AccessToken.GetAll(userId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getAllAccessTokensResponse{
+		Data: tokens,
+	})
+}
+
+func (h *Handler) revokeAccessToken(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.services.AccessToken.Revoke(userId, id); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
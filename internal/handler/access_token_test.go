@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/pavel-trbv/go-todo-app/internal/service"
+	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+// accessTokenRepository authenticates every access token as identity and
+// records the tokens created.
+type accessTokenRepository struct {
+	repository.AccessToken
+	identity domain.Identity
+	created  []domain.CreateAccessTokenInput
+}
+
+func (r *accessTokenRepository) Use(string) (domain.Identity, error) {
+	return r.identity, nil
+}
+
+func (r *accessTokenRepository) Create(userId int, tokenHash string,
+	input domain.CreateAccessTokenInput) (domain.AccessToken, error) {
+	r.created = append(r.created, input)
+	return domain.AccessToken{Id: 2, Name: input.Name, Scopes: input.Scopes}, nil
+}
+
+func TestHandler_createAccessToken(t *testing.T) {
+	testTable := []struct {
+		name                 string
+		token                string
+		identity             domain.Identity
+		inputBody            string
+		expectedStatusCode   int
+		expectedResponseBody string
+		expectedCreated      []domain.CreateAccessTokenInput
+	}{
+		{
+			name:               "Session",
+			token:              "token",
+			identity:           domain.Identity{UserId: 1, SessionId: 1},
+			inputBody:          `{"name":"ci","scopes":["lists:write","webhooks:write"]}`,
+			expectedStatusCode: 200,
+			expectedCreated: []domain.CreateAccessTokenInput{
+				{Name: "ci", Scopes: domain.Scopes{domain.ScopeListsWrite, domain.ScopeWebhooksWrite}},
+			},
+		},
+		{
+			name:  "Token Within Scopes",
+			token: "tdp_token",
+			identity: domain.Identity{UserId: 1, AccessTokenId: 1,
+				Scopes: domain.Scopes{domain.ScopeTokensWrite, domain.ScopeListsRead}},
+			inputBody:          `{"name":"ci","scopes":["lists:read"]}`,
+			expectedStatusCode: 200,
+			expectedCreated: []domain.CreateAccessTokenInput{
+				{Name: "ci", Scopes: domain.Scopes{domain.ScopeListsRead}},
+			},
+		},
+		{
+			name:  "Token Exceeding Scopes",
+			token: "tdp_token",
+			identity: domain.Identity{UserId: 1, AccessTokenId: 1,
+				Scopes: domain.Scopes{domain.ScopeTokensWrite, domain.ScopeListsRead}},
+			inputBody:            `{"name":"ci","scopes":["lists:read","webhooks:write"]}`,
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"code":"forbidden","message":"token can not grant scope webhooks:write"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthorization(c)
+			auth.EXPECT().ParseToken("token").Return(testCase.identity, nil).AnyTimes()
+
+			repo := &accessTokenRepository{identity: testCase.identity}
+
+			services := &service.Service{Authorization: auth, AccessToken: service.NewAccessTokenService(repo)}
+			handler := NewHandler(services)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/tokens/", bytes.NewBufferString(testCase.inputBody))
+			req.Header.Set("Authorization", "Bearer "+testCase.token)
+
+			// Make Request
+			handler.InitRoutes().ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			if testCase.expectedResponseBody != "" {
+				assert.Equal(t, testCase.expectedResponseBody, w.Body.String())
+			}
+			assert.Equal(t, testCase.expectedCreated, repo.created)
+		})
+	}
+}
@@ -6,11 +6,15 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/hash"
+	"github.com/pavel-trbv/go-todo-app/internal/keys"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
 	"github.com/pavel-trbv/go-todo-app/internal/service"
 	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
 	"github.com/stretchr/testify/assert"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestHandler_signUp(t *testing.T) {
@@ -43,14 +47,14 @@ func TestHandler_signUp(t *testing.T) {
 			inputBody:           `{"username":"test","password":"qwerty"}`,
 			mockBehavior:        func(s *mock_service.MockAuthorization, user domain.User) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:                "Invalid Body",
 			inputBody:           `{"username":`,
 			mockBehavior:        func(s *mock_service.MockAuthorization, user domain.User) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:      "Service Failure",
@@ -64,7 +68,7 @@ func TestHandler_signUp(t *testing.T) {
 				s.EXPECT().CreateUser(user).Return(0, errors.New("service error"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service error"}`,
+			expectedRequestBody: `{"code":"internal_error","message":"internal server error"}`,
 		},
 	}
 
@@ -82,6 +86,7 @@ func TestHandler_signUp(t *testing.T) {
 
 			// Test Server
 			r := gin.New()
+			r.Use(errorHandler)
 			r.POST("/sign-up", handler.signUp)
 
 			// Test Request
@@ -118,24 +123,28 @@ func TestHandler_signIn(t *testing.T) {
 				Password: "qwerty",
 			},
 			mockBehavior: func(s *mock_service.MockAuthorization, user signInInput) {
-				s.EXPECT().GenerateToken(user.Username, user.Password).Return("token", nil)
+				s.EXPECT().GenerateTokens(user.Username, user.Password).Return(domain.Tokens{
+					AccessToken:  "access",
+					RefreshToken: "refresh",
+					ExpiresIn:    900,
+				}, nil)
 			},
 			expectedStatusCode:  200,
-			expectedRequestBody: `{"token":"token"}`,
+			expectedRequestBody: `{"access_token":"access","refresh_token":"refresh","expires_in":900}`,
 		},
 		{
 			name:                "Missing Fields",
 			inputBody:           `{"username":"test"}`,
 			mockBehavior:        func(s *mock_service.MockAuthorization, user signInInput) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:                "Invalid Body",
 			inputBody:           `{"username":`,
 			mockBehavior:        func(s *mock_service.MockAuthorization, user signInInput) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:      "Service Failure",
@@ -145,11 +154,11 @@ func TestHandler_signIn(t *testing.T) {
 				Password: "qwerty",
 			},
 			mockBehavior: func(s *mock_service.MockAuthorization, user signInInput) {
-				s.EXPECT().GenerateToken(user.Username, user.Password).
-					Return("", errors.New("service error"))
+				s.EXPECT().GenerateTokens(user.Username, user.Password).
+					Return(domain.Tokens{}, errors.New("service error"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service error"}`,
+			expectedRequestBody: `{"code":"internal_error","message":"internal server error"}`,
 		},
 	}
 
@@ -167,6 +176,7 @@ func TestHandler_signIn(t *testing.T) {
 
 			// Test Server
 			r := gin.New()
+			r.Use(errorHandler)
 			r.POST("/sign-in", handler.signIn)
 
 			// Test Request
@@ -183,3 +193,174 @@ func TestHandler_signIn(t *testing.T) {
 		})
 	}
 }
+
+// userRepository finds one user and records its rehashed password, every
+// other method is left unimplemented.
+type userRepository struct {
+	repository.Authorization
+	user domain.User
+}
+
+func (r *userRepository) GetUser(username string) (domain.User, error) {
+	return r.user, nil
+}
+
+func (r *userRepository) UpdatePasswordHash(userId int, passwordHash string) error {
+	r.user.PasswordHash = passwordHash
+	return nil
+}
+
+// sessionRepository starts sessions, every other method is left unimplemented.
+type sessionRepository struct {
+	repository.Session
+}
+
+func (r *sessionRepository) Create(userId int, token domain.RefreshToken) (int, error) {
+	return 1, nil
+}
+
+func TestHandler_signIn_UnknownHash(t *testing.T) {
+	// a legacy hash can not be recognized when the salt is not configured
+	legacy, _ := hash.NewSHA1Hasher("salt").Hash("qwerty")
+	hasher := hash.NewMultiHasher(hash.NewBcryptHasher(4), hash.NewSHA1Hasher(""))
+
+	services := &service.Service{Authorization: service.NewAuthService(
+		&userRepository{user: domain.User{Id: 1, Username: "test", PasswordHash: legacy}}, nil, hasher, nil,
+		time.Minute, time.Hour)}
+	handler := NewHandler(services)
+
+	// Test Server
+	r := gin.New()
+	r.Use(errorHandler)
+	r.POST("/sign-in", handler.signIn)
+
+	// Test Request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/sign-in", bytes.NewBufferString(`{"username":"test","password":"qwerty"}`))
+
+	// Perform Request
+	r.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `{"code":"unauthorized","message":"invalid username or password"}`, w.Body.String())
+}
+
+func TestHandler_signIn_OtherAlgorithm(t *testing.T) {
+	// the password was hashed before the algorithm was switched to bcrypt
+	argon2id := hash.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+	previous, _ := hash.NewArgon2idHasher(argon2id).Hash("qwerty")
+	hasher, err := hash.NewPasswordHasher(hash.Config{Algorithm: "bcrypt", BcryptCost: 4, Argon2id: argon2id})
+	assert.NoError(t, err)
+
+	key, _ := keys.NewHMACKey("test", []byte("secret"))
+	keySet, _ := keys.NewKeySet(key)
+
+	users := &userRepository{user: domain.User{Id: 1, Username: "test", PasswordHash: previous}}
+	services := &service.Service{Authorization: service.NewAuthService(users, &sessionRepository{}, hasher, keySet,
+		time.Minute, time.Hour)}
+	handler := NewHandler(services)
+
+	// Test Server
+	r := gin.New()
+	r.Use(errorHandler)
+	r.POST("/sign-in", handler.signIn)
+
+	// Test Request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/sign-in", bytes.NewBufferString(`{"username":"test","password":"qwerty"}`))
+
+	// Perform Request
+	r.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"access_token"`)
+	assert.True(t, hash.NewBcryptHasher(4).Supports(users.user.PasswordHash))
+}
+
+func TestHandler_refresh(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockAuthorization, refreshToken string)
+
+	testTable := []struct {
+		name                string
+		inputBody           string
+		refreshToken        string
+		mockBehavior        mockBehavior
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:         "OK",
+			inputBody:    `{"refresh_token":"refresh"}`,
+			refreshToken: "refresh",
+			mockBehavior: func(s *mock_service.MockAuthorization, refreshToken string) {
+				s.EXPECT().RefreshTokens(refreshToken).Return(domain.Tokens{
+					AccessToken:  "access",
+					RefreshToken: "next",
+					ExpiresIn:    900,
+				}, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"access_token":"access","refresh_token":"next","expires_in":900}`,
+		},
+		{
+			name:                "Missing Fields",
+			inputBody:           `{}`,
+			mockBehavior:        func(s *mock_service.MockAuthorization, refreshToken string) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+		{
+			name:         "Reused Token",
+			inputBody:    `{"refresh_token":"refresh"}`,
+			refreshToken: "refresh",
+			mockBehavior: func(s *mock_service.MockAuthorization, refreshToken string) {
+				s.EXPECT().RefreshTokens(refreshToken).Return(domain.Tokens{}, service.ErrRefreshTokenReused)
+			},
+			expectedStatusCode:  401,
+			expectedRequestBody: `{"code":"unauthorized","message":"refresh token reuse detected"}`,
+		},
+		{
+			name:         "Service Failure",
+			inputBody:    `{"refresh_token":"refresh"}`,
+			refreshToken: "refresh",
+			mockBehavior: func(s *mock_service.MockAuthorization, refreshToken string) {
+				s.EXPECT().RefreshTokens(refreshToken).Return(domain.Tokens{}, errors.New("service error"))
+			},
+			expectedStatusCode:  500,
+			expectedRequestBody: `{"code":"internal_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthorization(c)
+			test.mockBehavior(auth, test.refreshToken)
+
+			services := &service.Service{Authorization: auth}
+			handler := NewHandler(services)
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.POST("/refresh", handler.refresh)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/refresh",
+				bytes.NewBufferString(test.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func parsePage(c *gin.Context) (domain.Page, error) {
+	page := domain.Page{
+		Sort:   c.Query("sort"),
+		Order:  c.Query("order"),
+		Cursor: c.Query("cursor"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return page, errors.New("invalid limit param")
+		}
+		page.Limit = value
+	}
+
+	return page, nil
+}
+
+func parseListFilter(c *gin.Context) (domain.ListFilter, error) {
+	page, err := parsePage(c)
+	if err != nil {
+		return domain.ListFilter{}, err
+	}
+
+	return domain.ListFilter{Page: page, Query: c.Query("q")}, nil
+}
+
+func parseItemFilter(c *gin.Context) (domain.ItemFilter, error) {
+	page, err := parsePage(c)
+	if err != nil {
+		return domain.ItemFilter{}, err
+	}
+
+	filter := domain.ItemFilter{Page: page, Query: c.Query("q")}
+
+	if done := c.Query("done"); done != "" {
+		value, err := strconv.ParseBool(done)
+		if err != nil {
+			return filter, errors.New("invalid done param")
+		}
+		filter.Done = &value
+	}
+
+	if priority := c.Query("priority"); priority != "" {
+		filter.Priorities = strings.Split(priority, ",")
+	}
+
+	if label := c.Query("label"); label != "" {
+		if filter.LabelId, err = strconv.Atoi(label); err != nil {
+			return filter, errors.New("invalid label param")
+		}
+	}
+
+	if filter.DueBefore, err = parseTimeQuery(c, "due_before"); err != nil {
+		return filter, err
+	}
+
+	if filter.DueAfter, err = parseTimeQuery(c, "due_after"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.New("invalid " + key + " param")
+	}
+
+	return &t, nil
+}
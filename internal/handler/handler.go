@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
 	"github.com/pavel-trbv/go-todo-app/internal/service"
 )
 
@@ -15,35 +16,115 @@ func NewHandler(services *service.Service) *Handler {
 
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.New()
+	router.Use(errorHandler)
+
+	router.GET("/.well-known/jwks.json", h.jwks)
+	router.GET("/openapi.json", h.openAPI)
+	router.GET("/docs", h.docs)
 
 	auth := router.Group("/auth")
 	{
 		auth.POST("/sign-up", h.signUp)
 		auth.POST("/sign-in", h.signIn)
+		auth.POST("/refresh", h.refresh)
+		auth.POST("/logout", h.userIdentity, h.requireSession, h.logout)
+		auth.POST("/logout-all", h.userIdentity, h.requireSession, h.logoutAll)
 	}
 
-	api := router.Group("/api", h.userIdentity)
+	api := router.Group("/api", h.userIdentity, h.idempotency)
 	{
 		lists := api.Group("/lists")
 		{
-			lists.POST("/", h.createList)
-			lists.GET("/", h.getAllLists)
-			lists.GET("/:id", h.getListById)
-			lists.PUT("/:id", h.updateList)
-			lists.DELETE("/:id", h.deleteList)
+			lists.POST("/", h.requireScope(domain.ScopeListsWrite), h.createList)
+			lists.GET("/", h.requireScope(domain.ScopeListsRead), h.getAllLists)
+			lists.GET("/:id", h.requireScope(domain.ScopeListsRead), h.getListById)
+			lists.PUT("/:id", h.requireScope(domain.ScopeListsWrite), h.updateList)
+			lists.PATCH("/:id", h.requireScope(domain.ScopeListsWrite), h.patchList)
+			lists.DELETE("/:id", h.requireScope(domain.ScopeListsWrite), h.deleteList)
+			lists.POST("/:id/leave", h.requireScope(domain.ScopeListsWrite), h.leaveList)
+			lists.POST("/:id/move", h.requireScope(domain.ScopeListsWrite), h.moveList)
+			lists.POST("/:id/duplicate", h.requireScope(domain.ScopeListsWrite), h.duplicateList)
+
+			members := lists.Group(":id/members")
+			{
+				members.GET("/", h.requireScope(domain.ScopeListsRead), h.getAllListMembers)
+				members.POST("/", h.requireScope(domain.ScopeListsWrite), h.inviteListMember)
+				members.PUT("/:user_id", h.requireScope(domain.ScopeListsWrite), h.updateListMember)
+				members.DELETE("/:user_id", h.requireScope(domain.ScopeListsWrite), h.removeListMember)
+			}
 
 			items := lists.Group(":id/items")
 			{
-				items.POST("/", h.createItem)
-				items.GET("/", h.getAllItems)
+				items.POST("/", h.requireScope(domain.ScopeItemsWrite), h.createItem)
+				items.GET("/", h.requireScope(domain.ScopeItemsRead), h.getAllItems)
+				items.POST("/bulk/create", h.requireScope(domain.ScopeItemsWrite), h.bulkCreateItems)
+				items.POST("/bulk/update", h.requireScope(domain.ScopeItemsWrite), h.bulkUpdateItems)
+				items.POST("/bulk/delete", h.requireScope(domain.ScopeItemsWrite), h.bulkDeleteItems)
+				items.POST("/clear-completed", h.requireScope(domain.ScopeItemsWrite), h.clearCompletedItems)
 			}
 		}
 
 		items := api.Group("/items")
 		{
-			items.GET("/:id", h.getItemById)
-			items.PUT("/:id", h.updateItem)
-			items.DELETE("/:id", h.deleteItem)
+			items.GET("/:id", h.requireScope(domain.ScopeItemsRead), h.getItemById)
+			items.PUT("/:id", h.requireScope(domain.ScopeItemsWrite), h.updateItem)
+			items.PATCH("/:id", h.requireScope(domain.ScopeItemsWrite), h.patchItem)
+			items.DELETE("/:id", h.requireScope(domain.ScopeItemsWrite), h.deleteItem)
+			items.POST("/:id/move", h.requireScope(domain.ScopeItemsWrite), h.moveItem)
+			items.POST("/:id/copy", h.requireScope(domain.ScopeItemsWrite), h.copyItem)
+			items.POST("/:id/children", h.requireScope(domain.ScopeItemsWrite), h.createChildItem)
+			items.GET("/:id/children", h.requireScope(domain.ScopeItemsRead), h.getChildItems)
+			items.PUT("/:id/children/order", h.requireScope(domain.ScopeItemsWrite), h.reorderChildItems)
+			items.POST("/:id/labels/:label_id", h.requireScope(domain.ScopeItemsWrite), h.attachLabel)
+			items.DELETE("/:id/labels/:label_id", h.requireScope(domain.ScopeItemsWrite), h.detachLabel)
+			items.POST("/:id/reminders", h.requireScope(domain.ScopeItemsWrite), h.createReminder)
+			items.GET("/:id/reminders", h.requireScope(domain.ScopeItemsRead), h.getAllReminders)
+		}
+
+		reminders := api.Group("/reminders")
+		{
+			reminders.DELETE("/:id", h.requireScope(domain.ScopeItemsWrite), h.deleteReminder)
+		}
+
+		labels := api.Group("/labels")
+		{
+			labels.POST("/", h.requireScope(domain.ScopeItemsWrite), h.createLabel)
+			labels.GET("/", h.requireScope(domain.ScopeItemsRead), h.getAllLabels)
+			labels.GET("/:id", h.requireScope(domain.ScopeItemsRead), h.getLabelById)
+			labels.PUT("/:id", h.requireScope(domain.ScopeItemsWrite), h.updateLabel)
+			labels.DELETE("/:id", h.requireScope(domain.ScopeItemsWrite), h.deleteLabel)
+			labels.GET("/:id/items", h.requireScope(domain.ScopeItemsRead), h.getLabelItems)
+		}
+
+		api.GET("/search", h.requireScope(domain.ScopeListsRead), h.requireScope(domain.ScopeItemsRead), h.search)
+		api.GET("/stream", h.requireScope(domain.ScopeListsRead), h.requireScope(domain.ScopeItemsRead), h.stream)
+		api.GET("/sync", h.requireScope(domain.ScopeListsRead), h.requireScope(domain.ScopeItemsRead), h.getSyncChanges)
+		api.POST("/sync", h.requireScope(domain.ScopeListsWrite), h.requireScope(domain.ScopeItemsWrite), h.applySync)
+
+		invitations := api.Group("/invitations")
+		{
+			invitations.GET("/", h.requireScope(domain.ScopeListsRead), h.getAllInvitations)
+			invitations.POST("/:id/accept", h.requireScope(domain.ScopeListsWrite), h.acceptInvitation)
+			invitations.POST("/:id/decline", h.requireScope(domain.ScopeListsWrite), h.declineInvitation)
+		}
+
+		webhooks := api.Group("/webhooks")
+		{
+			webhooks.POST("/", h.requireScope(domain.ScopeWebhooksWrite), h.createWebhook)
+			webhooks.GET("/", h.requireScope(domain.ScopeWebhooksRead), h.getAllWebhooks)
+			webhooks.GET("/:id", h.requireScope(domain.ScopeWebhooksRead), h.getWebhookById)
+			webhooks.PUT("/:id", h.requireScope(domain.ScopeWebhooksWrite), h.updateWebhook)
+			webhooks.DELETE("/:id", h.requireScope(domain.ScopeWebhooksWrite), h.deleteWebhook)
+			webhooks.GET("/:id/deliveries", h.requireScope(domain.ScopeWebhooksRead), h.getWebhookDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", h.requireScope(domain.ScopeWebhooksWrite),
+				h.redeliverWebhook)
+		}
+
+		tokens := api.Group("/tokens")
+		{
+			tokens.POST("/", h.requireScope(domain.ScopeTokensWrite), h.createAccessToken)
+			tokens.GET("/", h.requireScope(domain.ScopeTokensRead), h.getAllAccessTokens)
+			tokens.DELETE("/:id", h.requireScope(domain.ScopeTokensWrite), h.revokeAccessToken)
 		}
 	}
 
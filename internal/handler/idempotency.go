@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	idempotentReplayed   = "Idempotent-Replayed"
+	maxIdempotencyKeyLen = 255
+)
+
+// idempotency makes POST requests carrying an Idempotency-Key header safe to
+// retry: a request with a key already used by the user gets the response of
+// the first one. Requests with the same key run one at a time. Only
+// successful responses are stored, a failed request runs again on retry.
+// Keys are scoped to the user, so the /auth routes, which have none and
+// respond with credentials that must not be replayed, ignore the header.
+func (h *Handler) idempotency(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if c.Request.Method != http.MethodPost || key == "" {
+		return
+	}
+
+	if len(key) > maxIdempotencyKeyLen {
+		newErrorResponse(c, http.StatusBadRequest, "invalid Idempotency-Key header")
+		return
+	}
+
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	unlock, err := h.services.Idempotency.Lock(c.Request.Context(), userId, key)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	defer unlock()
+
+	requestHash := hashRequest(c.Request, body)
+
+	stored, err := h.services.Idempotency.Get(userId, key)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if stored != nil {
+		if stored.RequestHash != requestHash {
+			newErrorResponse(c, http.StatusUnprocessableEntity, "Idempotency-Key was used for another request")
+			return
+		}
+
+		c.Header(idempotentReplayed, "true")
+		c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+		c.Abort()
+		return
+	}
+
+	w := &bodyRecorder{ResponseWriter: c.Writer}
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	if len(c.Errors) > 0 || w.Status() < 200 || w.Status() >= 300 {
+		return
+	}
+
+	err = h.services.Idempotency.Save(userId, key, domain.IdempotentResponse{
+		RequestHash: requestHash,
+		StatusCode:  w.Status(),
+		ContentType: w.Header().Get("Content-Type"),
+		Body:        w.body.Bytes(),
+	})
+	if err != nil {
+		logrus.Errorf("failed to store response for idempotency key of user %d: %s", userId, err.Error())
+	}
+}
+
+// hashRequest identifies a request by its method, path and body.
+func hashRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bodyRecorder keeps a copy of the response body.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
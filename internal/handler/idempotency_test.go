@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/service"
+	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_idempotency(t *testing.T) {
+	type mockBehavior func(i *mock_service.MockIdempotency, l *mock_service.MockTodoList)
+
+	body := `{"title":"groceries"}`
+	requestHash := hashRequest(httptest.NewRequest("POST", "/api/lists", nil), []byte(body))
+	unlock := func() {}
+
+	testTable := []struct {
+		name                 string
+		key                  string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedReplayed     string
+		expectedResponseBody string
+	}{
+		{
+			name: "First Request",
+			key:  "abc",
+			mockBehavior: func(i *mock_service.MockIdempotency, l *mock_service.MockTodoList) {
+				i.EXPECT().Lock(gomock.Any(), 1, "abc").Return(unlock, nil)
+				i.EXPECT().Get(1, "abc").Return(nil, nil)
+				l.EXPECT().Create(1, domain.TodoList{Title: "groceries"}).Return(2, nil)
+				i.EXPECT().Save(1, "abc", domain.IdempotentResponse{
+					RequestHash: requestHash,
+					StatusCode:  200,
+					ContentType: "application/json; charset=utf-8",
+					Body:        []byte(`{"id":2}`),
+				}).Return(nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"id":2}`,
+		},
+		{
+			name: "Retry",
+			key:  "abc",
+			mockBehavior: func(i *mock_service.MockIdempotency, l *mock_service.MockTodoList) {
+				i.EXPECT().Lock(gomock.Any(), 1, "abc").Return(unlock, nil)
+				i.EXPECT().Get(1, "abc").Return(&domain.IdempotentResponse{
+					RequestHash: requestHash,
+					StatusCode:  200,
+					ContentType: "application/json; charset=utf-8",
+					Body:        []byte(`{"id":2}`),
+				}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedReplayed:     "true",
+			expectedResponseBody: `{"id":2}`,
+		},
+		{
+			name: "Key Reused",
+			key:  "abc",
+			mockBehavior: func(i *mock_service.MockIdempotency, l *mock_service.MockTodoList) {
+				i.EXPECT().Lock(gomock.Any(), 1, "abc").Return(unlock, nil)
+				i.EXPECT().Get(1, "abc").Return(&domain.IdempotentResponse{
+					RequestHash: "other",
+					StatusCode:  200,
+					Body:        []byte(`{"id":3}`),
+				}, nil)
+			},
+			expectedStatusCode:   422,
+			expectedResponseBody: `{"code":"validation_error","message":"Idempotency-Key was used for another request"}`,
+		},
+		{
+			name: "Failure Is Not Stored",
+			key:  "abc",
+			mockBehavior: func(i *mock_service.MockIdempotency, l *mock_service.MockTodoList) {
+				i.EXPECT().Lock(gomock.Any(), 1, "abc").Return(unlock, nil)
+				i.EXPECT().Get(1, "abc").Return(nil, nil)
+				l.EXPECT().Create(1, domain.TodoList{Title: "groceries"}).Return(0, errors.New("service error"))
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"code":"internal_error","message":"internal server error"}`,
+		},
+		{
+			name: "Without Key",
+			mockBehavior: func(i *mock_service.MockIdempotency, l *mock_service.MockTodoList) {
+				l.EXPECT().Create(1, domain.TodoList{Title: "groceries"}).Return(2, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"id":2}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			i := mock_service.NewMockIdempotency(c)
+			l := mock_service.NewMockTodoList(c)
+			testCase.mockBehavior(i, l)
+
+			services := &service.Service{Idempotency: i, TodoList: l}
+			handler := NewHandler(services)
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.Use(withUserId(1))
+			r.POST("/api/lists", handler.idempotency, handler.createList)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/lists", bytes.NewBufferString(body))
+			if testCase.key != "" {
+				req.Header.Set("Idempotency-Key", testCase.key)
+			}
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedReplayed, w.Header().Get("Idempotent-Replayed"))
+			assert.JSONEq(t, testCase.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_idempotencyAuthRoutes(t *testing.T) {
+	// Init Deps
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	a := mock_service.NewMockAuthorization(c)
+	i := mock_service.NewMockIdempotency(c)
+	gomock.InOrder(
+		a.EXPECT().GenerateTokens("user", "qwerty").Return(domain.Tokens{AccessToken: "first"}, nil),
+		a.EXPECT().GenerateTokens("user", "qwerty").Return(domain.Tokens{AccessToken: "second"}, nil),
+	)
+
+	services := &service.Service{Authorization: a, Idempotency: i}
+	r := NewHandler(services).InitRoutes()
+
+	for _, token := range []string{"first", "second"} {
+		// Test Request
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/auth/sign-in",
+			bytes.NewBufferString(`{"username":"user","password":"qwerty"}`))
+		req.Header.Set("Idempotency-Key", "abc")
+
+		// Perform Request
+		r.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, 200, w.Code)
+		assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+		assert.Contains(t, w.Body.String(), `"access_token":"`+token+`"`)
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
 	"net/http"
 	"strings"
 )
@@ -10,6 +11,8 @@ import (
 const (
 	authorizationToken = "Authorization"
 	userCtx            = "userId"
+	sessionCtx         = "sessionId"
+	identityCtx        = "identity"
 )
 
 func (h *Handler) userIdentity(c *gin.Context) {
@@ -36,12 +39,74 @@ func (h *Handler) userIdentity(c *gin.Context) {
 	}
 
 	token := headerParts[1]
-	userId, err := h.services.Authorization.ParseToken(token)
+
+	var identity domain.Identity
+	var err error
+	if strings.HasPrefix(token, domain.AccessTokenPrefix) {
+		identity, err = h.services.AccessToken.ParseToken(token)
+	} else {
+		identity, err = h.services.Authorization.ParseToken(token)
+	}
+	// rejected tokens get the same answer whatever the reason, other failures
+	// are server errors
+	if errors.Is(err, domain.ErrUnauthorized) {
+		newErrorResponse(c, http.StatusUnauthorized, "invalid token")
+		return
+	}
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Set(identityCtx, identity)
+	c.Set(userCtx, identity.UserId)
+	c.Set(sessionCtx, identity.SessionId)
+}
+
+// requireScope rejects personal access tokens that were not granted the
+// scope. Sessions started with a password are not limited.
+func (h *Handler) requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := c.Get(identityCtx)
+		if !ok {
+			newErrorResponse(c, http.StatusUnauthorized, "identity not found")
+			return
+		}
+
+		if !identity.(domain.Identity).HasScope(scope) {
+			newErrorResponse(c, http.StatusForbidden, "token is missing scope "+scope)
+			return
+		}
+	}
+}
+
+// requireSession rejects personal access tokens, for the routes managing
+// sessions started with a password.
+func (h *Handler) requireSession(c *gin.Context) {
+	identity, err := getIdentity(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
 	}
 
-	c.Set(userCtx, userId)
+	if identity.AccessTokenId != 0 {
+		newErrorResponse(c, http.StatusForbidden, "access tokens can not manage sessions")
+		return
+	}
+}
+
+func getIdentity(c *gin.Context) (domain.Identity, error) {
+	identity, ok := c.Get(identityCtx)
+	if !ok {
+		return domain.Identity{}, errors.New("identity not found")
+	}
+
+	identityValue, ok := identity.(domain.Identity)
+	if !ok {
+		return domain.Identity{}, errors.New("identity is of invalid type")
+	}
+
+	return identityValue, nil
 }
 
 func getUserId(c *gin.Context) (int, error) {
@@ -57,3 +122,17 @@ func getUserId(c *gin.Context) (int, error) {
 
 	return idInt, nil
 }
+
+func getSessionId(c *gin.Context) (int, error) {
+	id, ok := c.Get(sessionCtx)
+	if !ok {
+		return 0, errors.New("session id not found")
+	}
+
+	idInt, ok := id.(int)
+	if !ok {
+		return 0, errors.New("session id is of invalid type")
+	}
+
+	return idInt, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
 	"github.com/pavel-trbv/go-todo-app/internal/service"
 	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
 	"github.com/stretchr/testify/assert"
@@ -13,7 +14,7 @@ import (
 )
 
 func TestHandler_userIdentity(t *testing.T) {
-	type mockBehavior func(s *mock_service.MockAuthorization, token string)
+	type mockBehavior func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken, token string)
 
 	testTable := []struct {
 		name                 string
@@ -29,45 +30,82 @@ func TestHandler_userIdentity(t *testing.T) {
 			headerName:  "Authorization",
 			headerValue: "Bearer token",
 			token:       "token",
-			mockBehavior: func(s *mock_service.MockAuthorization, token string) {
-				s.EXPECT().ParseToken(token).Return(1, nil)
+			mockBehavior: func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken, token string) {
+				s.EXPECT().ParseToken(token).Return(domain.Identity{UserId: 1, SessionId: 1}, nil)
 			},
 			expectedStatusCode:   200,
 			expectedResponseBody: "1",
 		},
+		{
+			name:        "Access Token",
+			headerName:  "Authorization",
+			headerValue: "Bearer tdp_token",
+			token:       "tdp_token",
+			mockBehavior: func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken, token string) {
+				a.EXPECT().ParseToken(token).Return(domain.Identity{
+					UserId:        2,
+					AccessTokenId: 1,
+					Scopes:        domain.Scopes{domain.ScopeListsRead},
+				}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: "2",
+		},
 		{
 			name:                 "No Header",
 			headerName:           "",
-			mockBehavior:         func(s *mock_service.MockAuthorization, token string) {},
+			mockBehavior:         func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken, token string) {},
 			expectedStatusCode:   401,
-			expectedResponseBody: `{"message":"empty auth header"}`,
+			expectedResponseBody: `{"code":"unauthorized","message":"empty auth header"}`,
 		},
 		{
 			name:                 "Invalid Bearer",
 			headerName:           "Authorization",
 			headerValue:          "Bearr token",
-			mockBehavior:         func(s *mock_service.MockAuthorization, token string) {},
+			mockBehavior:         func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken, token string) {},
 			expectedStatusCode:   401,
-			expectedResponseBody: `{"message":"invalid auth header"}`,
+			expectedResponseBody: `{"code":"unauthorized","message":"invalid auth header"}`,
 		},
 		{
 			name:                 "Invalid Token",
 			headerName:           "Authorization",
 			headerValue:          "Bearer ",
-			mockBehavior:         func(s *mock_service.MockAuthorization, token string) {},
+			mockBehavior:         func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken, token string) {},
 			expectedStatusCode:   401,
-			expectedResponseBody: `{"message":"token is empty"}`,
+			expectedResponseBody: `{"code":"unauthorized","message":"token is empty"}`,
 		},
 		{
-			name:        "Service Failure",
+			name:        "Rejected Token",
 			headerName:  "Authorization",
 			headerValue: "Bearer token",
 			token:       "token",
-			mockBehavior: func(s *mock_service.MockAuthorization, token string) {
-				s.EXPECT().ParseToken(token).Return(0, errors.New("failed to parse token"))
+			mockBehavior: func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken, token string) {
+				s.EXPECT().ParseToken(token).Return(domain.Identity{}, service.ErrSessionRevoked)
 			},
 			expectedStatusCode:   401,
-			expectedResponseBody: `{"message":"failed to parse token"}`,
+			expectedResponseBody: `{"code":"unauthorized","message":"invalid token"}`,
+		},
+		{
+			name:        "Rejected Access Token",
+			headerName:  "Authorization",
+			headerValue: "Bearer tdp_token",
+			token:       "tdp_token",
+			mockBehavior: func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken, token string) {
+				a.EXPECT().ParseToken(token).Return(domain.Identity{}, service.ErrInvalidAccessToken)
+			},
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"code":"unauthorized","message":"invalid token"}`,
+		},
+		{
+			name:        "Service Failure",
+			headerName:  "Authorization",
+			headerValue: "Bearer token",
+			token:       "token",
+			mockBehavior: func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken, token string) {
+				s.EXPECT().ParseToken(token).Return(domain.Identity{}, errors.New("connection refused"))
+			},
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"code":"internal_error","message":"internal server error"}`,
 		},
 	}
 
@@ -78,13 +116,15 @@ func TestHandler_userIdentity(t *testing.T) {
 			defer c.Finish()
 
 			auth := mock_service.NewMockAuthorization(c)
-			testCase.mockBehavior(auth, testCase.token)
+			accessToken := mock_service.NewMockAccessToken(c)
+			testCase.mockBehavior(auth, accessToken, testCase.token)
 
-			services := &service.Service{Authorization: auth}
+			services := &service.Service{Authorization: auth, AccessToken: accessToken}
 			handler := NewHandler(services)
 
 			// Test Server
 			r := gin.New()
+			r.Use(errorHandler)
 			r.POST("/protected", handler.userIdentity, func(c *gin.Context) {
 				id, _ := c.Get(userCtx)
 				c.String(200, fmt.Sprintf("%d", id.(int)))
@@ -105,6 +145,139 @@ func TestHandler_userIdentity(t *testing.T) {
 	}
 }
 
+func TestHandler_requireScope(t *testing.T) {
+	testTable := []struct {
+		name                 string
+		identity             interface{}
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "Session",
+			identity:             domain.Identity{UserId: 1, SessionId: 1},
+			expectedStatusCode:   200,
+			expectedResponseBody: "ok",
+		},
+		{
+			name: "Token With Scope",
+			identity: domain.Identity{
+				UserId:        1,
+				AccessTokenId: 1,
+				Scopes:        domain.Scopes{domain.ScopeItemsRead, domain.ScopeListsRead},
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: "ok",
+		},
+		{
+			name: "Token Without Scope",
+			identity: domain.Identity{
+				UserId:        1,
+				AccessTokenId: 1,
+				Scopes:        domain.Scopes{domain.ScopeItemsRead},
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"code":"forbidden","message":"token is missing scope lists:read"}`,
+		},
+		{
+			name:                 "No Identity",
+			expectedStatusCode:   401,
+			expectedResponseBody: `{"code":"unauthorized","message":"identity not found"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			handler := NewHandler(&service.Service{})
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.GET("/protected", func(c *gin.Context) {
+				if testCase.identity != nil {
+					c.Set(identityCtx, testCase.identity)
+				}
+			}, handler.requireScope(domain.ScopeListsRead), func(c *gin.Context) {
+				c.String(200, "ok")
+			})
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/protected", nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_requireSession(t *testing.T) {
+	testTable := []struct {
+		name                 string
+		token                string
+		mockBehavior         func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken)
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "Session",
+			token: "token",
+			mockBehavior: func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken) {
+				s.EXPECT().ParseToken("token").Return(domain.Identity{UserId: 1, SessionId: 1}, nil).Times(2)
+				s.EXPECT().Logout(1).Return(nil)
+				s.EXPECT().LogoutAll(1).Return(nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"status":"ok"}`,
+		},
+		{
+			name:  "Access Token",
+			token: "tdp_token",
+			mockBehavior: func(s *mock_service.MockAuthorization, a *mock_service.MockAccessToken) {
+				a.EXPECT().ParseToken("tdp_token").Return(domain.Identity{
+					UserId:        1,
+					AccessTokenId: 1,
+					Scopes:        domain.AllScopes,
+				}, nil).Times(2)
+			},
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"code":"forbidden","message":"access tokens can not manage sessions"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mock_service.NewMockAuthorization(c)
+			accessToken := mock_service.NewMockAccessToken(c)
+			testCase.mockBehavior(auth, accessToken)
+
+			handler := NewHandler(&service.Service{Authorization: auth, AccessToken: accessToken})
+			r := handler.InitRoutes()
+
+			for _, path := range []string{"/auth/logout", "/auth/logout-all"} {
+				// Test Request
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest("POST", path, nil)
+				req.Header.Set("Authorization", "Bearer "+testCase.token)
+
+				// Make Request
+				r.ServeHTTP(w, req)
+
+				// Assert
+				assert.Equal(t, testCase.expectedStatusCode, w.Code)
+				assert.Equal(t, testCase.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestGetUserId(t *testing.T) {
 	getContext := func(id interface{}) *gin.Context {
 		ctx := new(gin.Context)
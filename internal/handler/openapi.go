@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	_ "embed"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ghodss/yaml"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+)
+
+// openAPISpec describes every route of InitRoutes, the tests keep the two in
+// line.
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+var (
+	openAPIOnce sync.Once
+	openAPIJSON []byte
+	openAPIErr  error
+)
+
+// loadOpenAPI parses and validates the OpenAPI document.
+func loadOpenAPI() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func (h *Handler) openAPI(c *gin.Context) {
+	openAPIOnce.Do(func() {
+		openAPIJSON, openAPIErr = yaml.YAMLToJSON(openAPISpec)
+	})
+	if openAPIErr != nil {
+		newErrorResponse(c, http.StatusInternalServerError, openAPIErr.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPIJSON)
+}
+
+// docs renders the OpenAPI document with Redoc.
+func (h *Handler) docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
@@ -0,0 +1,897 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/keys"
+	"github.com/pavel-trbv/go-todo-app/internal/service"
+	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var ginParam = regexp.MustCompile(`:([a-z_]+)`)
+
+func TestOpenAPI_routes(t *testing.T) {
+	doc, err := loadOpenAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := make(map[string]bool)
+	for _, route := range NewHandler(&service.Service{}).InitRoutes().Routes() {
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		routes[route.Method+" "+path] = true
+
+		item := doc.Paths.Find(path)
+		if !assert.NotNil(t, item, "%s is missing from the spec", path) {
+			continue
+		}
+		assert.NotNil(t, item.GetOperation(route.Method), "%s %s is missing from the spec", route.Method, path)
+	}
+
+	for path, item := range doc.Paths {
+		for method := range item.Operations() {
+			assert.True(t, routes[method+" "+path], "%s %s of the spec is not routed", method, path)
+		}
+	}
+}
+
+type openAPIMocks struct {
+	auth      *mock_service.MockAuthorization
+	tokens    *mock_service.MockAccessToken
+	lists     *mock_service.MockTodoList
+	members   *mock_service.MockListMember
+	items     *mock_service.MockTodoItem
+	labels    *mock_service.MockLabel
+	reminders *mock_service.MockReminder
+	webhooks  *mock_service.MockWebhook
+	search    *mock_service.MockSearch
+	sync      *mock_service.MockSync
+}
+
+// TestOpenAPI_payloads sends requests through the routes and checks both the
+// requests and the responses against the spec. Every operation needs at least
+// one successful case.
+func TestOpenAPI_payloads(t *testing.T) {
+	doc, err := loadOpenAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, contentType := range []string{domain.MergePatchType, domain.JSONPatchType} {
+		openapi3filter.RegisterBodyDecoder(contentType, openapi3filter.RegisteredBodyDecoder("application/json"))
+	}
+	openapi3filter.RegisterBodyDecoder("text/html", openapi3filter.RegisteredBodyDecoder("text/plain"))
+
+	description := "weekly"
+	now := time.Date(2021, 3, 22, 10, 0, 0, 0, time.UTC)
+	list := domain.TodoList{Id: 1, Title: "home", Description: &description, Role: domain.RoleOwner, Position: "i",
+		Version: 2}
+	item := domain.TodoItem{Id: 2, Title: "chore", Priority: domain.PriorityNone, CreatedAt: now, UpdatedAt: now,
+		Position: "i", Timezone: domain.DefaultTimezone, Version: 1,
+		Labels: []domain.Label{{Id: 3, Name: "home", Color: domain.DefaultLabelColor}}}
+	done := true
+	offset := 30
+	statusCode := 200
+	bulk := domain.BulkResponse{Committed: true, Results: []domain.BulkResult{
+		{Index: 0, Id: 2, Status: domain.BulkApplied},
+	}}
+	label := domain.Label{Id: 3, Name: "home", Color: domain.DefaultLabelColor}
+	webhook := domain.Webhook{Id: 4, URL: "https://example.com/hooks", Events: []string{domain.EventItemCreated},
+		Active: true, CreatedAt: now}
+	accessToken := domain.AccessToken{Id: 5, Name: "cli", Scopes: domain.Scopes{domain.ScopeListsRead},
+		CreatedAt: now}
+
+	testTable := []struct {
+		name               string
+		method             string
+		path               string
+		contentType        string
+		body               string
+		header             map[string]string
+		mockBehavior       func(m openAPIMocks)
+		expectedStatusCode int
+	}{
+		{
+			name:   "JWKS",
+			method: "GET",
+			path:   "/.well-known/jwks.json",
+			mockBehavior: func(m openAPIMocks) {
+				m.auth.EXPECT().JWKS().Return(keys.JWKS{Keys: []keys.JWK{
+					{Kty: "OKP", Kid: "k1", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: "x"},
+				}})
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "OpenAPI",
+			method:             "GET",
+			path:               "/openapi.json",
+			mockBehavior:       func(m openAPIMocks) {},
+			expectedStatusCode: 200,
+		},
+		{
+			name:               "Docs",
+			method:             "GET",
+			path:               "/docs",
+			mockBehavior:       func(m openAPIMocks) {},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Sign In",
+			method:      "POST",
+			path:        "/auth/sign-in",
+			contentType: "application/json",
+			body:        `{"username":"alice","password":"secret"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.auth.EXPECT().GenerateTokens("alice", "secret").
+					Return(domain.Tokens{AccessToken: "a", RefreshToken: "r", ExpiresIn: 900}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Sign In With Wrong Password",
+			method:      "POST",
+			path:        "/auth/sign-in",
+			contentType: "application/json",
+			body:        `{"username":"alice","password":"wrong"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.auth.EXPECT().GenerateTokens("alice", "wrong").
+					Return(domain.Tokens{}, domain.NewError(domain.ErrUnauthorized, "invalid username or password"))
+			},
+			expectedStatusCode: 401,
+		},
+		{
+			name:        "Sign Up",
+			method:      "POST",
+			path:        "/auth/sign-up",
+			contentType: "application/json",
+			body:        `{"name":"Alice","username":"alice","password":"secret"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.auth.EXPECT().CreateUser(gomock.Any()).Return(1, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Refresh Tokens",
+			method:      "POST",
+			path:        "/auth/refresh",
+			contentType: "application/json",
+			body:        `{"refresh_token":"r"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.auth.EXPECT().RefreshTokens("r").
+					Return(domain.Tokens{AccessToken: "a", RefreshToken: "r2", ExpiresIn: 900}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Logout",
+			method: "POST",
+			path:   "/auth/logout",
+			mockBehavior: func(m openAPIMocks) {
+				m.auth.EXPECT().Logout(1).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Logout All",
+			method: "POST",
+			path:   "/auth/logout-all",
+			mockBehavior: func(m openAPIMocks) {
+				m.auth.EXPECT().LogoutAll(1).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Create List",
+			method:      "POST",
+			path:        "/api/lists/",
+			contentType: "application/json",
+			body:        `{"title":"home","description":null}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.lists.EXPECT().Create(1, domain.TodoList{Title: "home"}).Return(1, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get All Lists",
+			method: "GET",
+			path:   "/api/lists/?sort=title&limit=10",
+			mockBehavior: func(m openAPIMocks) {
+				m.lists.EXPECT().GetAll(1, domain.ListFilter{Page: domain.Page{Sort: "title", Limit: 10}}).
+					Return(domain.ListPage{Data: []domain.TodoList{list}, NextCursor: "c"}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get List",
+			method: "GET",
+			path:   "/api/lists/1",
+			mockBehavior: func(m openAPIMocks) {
+				m.lists.EXPECT().GetById(1, 1).Return(list, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get List Not Modified",
+			method: "GET",
+			path:   "/api/lists/1",
+			header: map[string]string{"If-None-Match": `"2"`},
+			mockBehavior: func(m openAPIMocks) {
+				m.lists.EXPECT().GetById(1, 1).Return(list, nil)
+			},
+			expectedStatusCode: 304,
+		},
+		{
+			name:        "Update List",
+			method:      "PUT",
+			path:        "/api/lists/1",
+			contentType: "application/json",
+			body:        `{"title":"work"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.lists.EXPECT().Update(1, 1, gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Delete List",
+			method: "DELETE",
+			path:   "/api/lists/1",
+			mockBehavior: func(m openAPIMocks) {
+				m.lists.EXPECT().Delete(1, 1, nil).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Leave List",
+			method: "POST",
+			path:   "/api/lists/1/leave",
+			mockBehavior: func(m openAPIMocks) {
+				m.members.EXPECT().Leave(1, 1).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Move List",
+			method:      "POST",
+			path:        "/api/lists/1/move",
+			contentType: "application/json",
+			body:        `{"after_id":2}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.lists.EXPECT().Move(1, 1, gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Duplicate List",
+			method:      "POST",
+			path:        "/api/lists/1/duplicate",
+			contentType: "application/json",
+			body:        `{"title":"home copy"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.lists.EXPECT().Duplicate(1, 1, gomock.Any()).Return(6, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get All List Members",
+			method: "GET",
+			path:   "/api/lists/1/members/",
+			mockBehavior: func(m openAPIMocks) {
+				m.members.EXPECT().GetAll(1, 1).Return([]domain.ListMember{
+					{UserId: 1, Name: "Alice", Username: "alice", Role: domain.RoleOwner},
+				}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Invite List Member",
+			method:      "POST",
+			path:        "/api/lists/1/members/",
+			contentType: "application/json",
+			body:        `{"username":"bob","role":"editor"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.members.EXPECT().Invite(1, 1, gomock.Any()).Return(7, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Update List Member",
+			method:      "PUT",
+			path:        "/api/lists/1/members/2",
+			contentType: "application/json",
+			body:        `{"role":"viewer"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.members.EXPECT().UpdateRole(1, 1, 2, gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Remove List Member",
+			method: "DELETE",
+			path:   "/api/lists/1/members/2",
+			mockBehavior: func(m openAPIMocks) {
+				m.members.EXPECT().Remove(1, 1, 2).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Create Item",
+			method:      "POST",
+			path:        "/api/lists/1/items/",
+			contentType: "application/json",
+			body:        `{"title":"chore","priority":"high","due_at":"2021-03-23T18:00:00+03:00"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().Create(1, 1, gomock.Any()).Return(2, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Bulk Create Items",
+			method:      "POST",
+			path:        "/api/lists/1/items/bulk/create",
+			contentType: "application/json",
+			body:        `{"mode":"atomic","items":[{"title":"chore"}]}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().BulkCreate(1, 1, gomock.Any()).Return(bulk, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Bulk Delete Items",
+			method:      "POST",
+			path:        "/api/lists/1/items/bulk/delete",
+			contentType: "application/json",
+			body:        `{"ids":[2]}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().BulkDelete(1, 1, gomock.Any()).Return(bulk, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Clear Completed Items",
+			method: "POST",
+			path:   "/api/lists/1/items/clear-completed",
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().ClearCompleted(1, 1).Return(bulk, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get Item",
+			method: "GET",
+			path:   "/api/items/2",
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().GetById(1, 2).Return(item, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Item Not Found",
+			method: "GET",
+			path:   "/api/items/3",
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().GetById(1, 3).
+					Return(domain.TodoItem{}, domain.NewError(domain.ErrNotFound, "item not found"))
+			},
+			expectedStatusCode: 404,
+		},
+		{
+			name:   "Get All Items",
+			method: "GET",
+			path:   "/api/lists/1/items/?done=false&priority=high,urgent",
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().GetAll(1, 1, gomock.Any()).
+					Return(domain.ItemPage{Data: []domain.TodoItem{item}}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Update Item",
+			method:      "PUT",
+			path:        "/api/items/2",
+			contentType: "application/json",
+			body:        `{"done":true}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().Update(1, 2, domain.UpdateItemInput{Done: &done}).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Update Item Version Mismatch",
+			method:      "PUT",
+			path:        "/api/items/2",
+			contentType: "application/json",
+			body:        `{"done":true}`,
+			header:      map[string]string{"If-Match": `"1"`},
+			mockBehavior: func(m openAPIMocks) {
+				version := 1
+				m.items.EXPECT().Update(1, 2, domain.UpdateItemInput{Done: &done, Version: &version}).
+					Return(domain.NewError(domain.ErrVersionMismatch, "item was changed, its version is 2"))
+			},
+			expectedStatusCode: 412,
+		},
+		{
+			name:   "Delete Item",
+			method: "DELETE",
+			path:   "/api/items/2",
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().Delete(1, 2, nil).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Move Item",
+			method:      "POST",
+			path:        "/api/items/2/move",
+			contentType: "application/json",
+			body:        `{"list_id":1,"before_id":3}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().Move(1, 2, gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Copy Item",
+			method:      "POST",
+			path:        "/api/items/2/copy",
+			contentType: "application/json",
+			body:        `{"list_id":1}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().Copy(1, 2, gomock.Any()).Return(8, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Create Child Item",
+			method:      "POST",
+			path:        "/api/items/2/children",
+			contentType: "application/json",
+			body:        `{"title":"step"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().CreateChild(1, 2, gomock.Any()).Return(9, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get Child Items",
+			method: "GET",
+			path:   "/api/items/2/children",
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().GetChildren(1, 2).Return([]domain.TodoItem{item}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Reorder Child Items",
+			method:      "PUT",
+			path:        "/api/items/2/children/order",
+			contentType: "application/json",
+			body:        `{"ids":[10,9]}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().ReorderChildren(1, 2, []int{10, 9}).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Attach Label",
+			method: "POST",
+			path:   "/api/items/2/labels/3",
+			mockBehavior: func(m openAPIMocks) {
+				m.labels.EXPECT().Attach(1, 2, 3).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Detach Label",
+			method: "DELETE",
+			path:   "/api/items/2/labels/3",
+			mockBehavior: func(m openAPIMocks) {
+				m.labels.EXPECT().Detach(1, 2, 3).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Create Reminder",
+			method:      "POST",
+			path:        "/api/items/2/reminders",
+			contentType: "application/json",
+			body:        `{"offset_minutes":30,"channel":"log"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.reminders.EXPECT().Create(1, 2, gomock.Any()).Return(11, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get All Reminders",
+			method: "GET",
+			path:   "/api/items/2/reminders",
+			mockBehavior: func(m openAPIMocks) {
+				m.reminders.EXPECT().GetAll(1, 2).Return([]domain.Reminder{
+					{Id: 11, ItemId: 2, OffsetMinutes: &offset, Channel: domain.ChannelLog},
+				}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Delete Reminder",
+			method: "DELETE",
+			path:   "/api/reminders/11",
+			mockBehavior: func(m openAPIMocks) {
+				m.reminders.EXPECT().Delete(1, 11).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Create Label",
+			method:      "POST",
+			path:        "/api/labels/",
+			contentType: "application/json",
+			body:        `{"name":"home","color":"#ff0000"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.labels.EXPECT().Create(1, gomock.Any()).Return(3, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get All Labels",
+			method: "GET",
+			path:   "/api/labels/",
+			mockBehavior: func(m openAPIMocks) {
+				m.labels.EXPECT().GetAll(1).Return([]domain.Label{label}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get Label",
+			method: "GET",
+			path:   "/api/labels/3",
+			mockBehavior: func(m openAPIMocks) {
+				m.labels.EXPECT().GetById(1, 3).Return(label, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Update Label",
+			method:      "PUT",
+			path:        "/api/labels/3",
+			contentType: "application/json",
+			body:        `{"color":"#00ff00"}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.labels.EXPECT().Update(1, 3, gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Delete Label",
+			method: "DELETE",
+			path:   "/api/labels/3",
+			mockBehavior: func(m openAPIMocks) {
+				m.labels.EXPECT().Delete(1, 3).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get Label Items",
+			method: "GET",
+			path:   "/api/labels/3/items?limit=10",
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().GetAllByLabel(1, 3, gomock.Any()).
+					Return(domain.ItemPage{Data: []domain.TodoItem{item}}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Search",
+			method: "GET",
+			path:   "/api/search?q=chore&limit=5",
+			mockBehavior: func(m openAPIMocks) {
+				m.search.EXPECT().Search(1, domain.SearchInput{Query: "chore", Limit: 5}).
+					Return([]domain.SearchResult{{Type: domain.SearchResultItem, Id: 2, Title: "chore",
+						Snippet: "<mark>chore</mark>", Rank: 0.1, List: domain.SearchList{Id: 1, Title: "home"}}}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get All Invitations",
+			method: "GET",
+			path:   "/api/invitations/",
+			mockBehavior: func(m openAPIMocks) {
+				m.members.EXPECT().GetInvitations(1).Return([]domain.Invitation{
+					{Id: 7, ListId: 1, ListTitle: "home", InviterUsername: "bob", Role: domain.RoleEditor,
+						Status: domain.InvitationPending, CreatedAt: now},
+				}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Accept Invitation",
+			method: "POST",
+			path:   "/api/invitations/7/accept",
+			mockBehavior: func(m openAPIMocks) {
+				m.members.EXPECT().AcceptInvitation(1, 7).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Decline Invitation",
+			method: "POST",
+			path:   "/api/invitations/7/decline",
+			mockBehavior: func(m openAPIMocks) {
+				m.members.EXPECT().DeclineInvitation(1, 7).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Create Webhook",
+			method:      "POST",
+			path:        "/api/webhooks/",
+			contentType: "application/json",
+			body:        `{"url":"https://example.com/hooks","events":["item.created"]}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.webhooks.EXPECT().Create(1, gomock.Any()).Return(domain.CreatedWebhook{Id: 4, Secret: "s"}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get All Webhooks",
+			method: "GET",
+			path:   "/api/webhooks/",
+			mockBehavior: func(m openAPIMocks) {
+				m.webhooks.EXPECT().GetAll(1).Return([]domain.Webhook{webhook}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get Webhook",
+			method: "GET",
+			path:   "/api/webhooks/4",
+			mockBehavior: func(m openAPIMocks) {
+				m.webhooks.EXPECT().GetById(1, 4).Return(webhook, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Update Webhook",
+			method:      "PUT",
+			path:        "/api/webhooks/4",
+			contentType: "application/json",
+			body:        `{"active":false}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.webhooks.EXPECT().Update(1, 4, gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Delete Webhook",
+			method: "DELETE",
+			path:   "/api/webhooks/4",
+			mockBehavior: func(m openAPIMocks) {
+				m.webhooks.EXPECT().Delete(1, 4).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get Webhook Deliveries",
+			method: "GET",
+			path:   "/api/webhooks/4/deliveries",
+			mockBehavior: func(m openAPIMocks) {
+				m.webhooks.EXPECT().GetDeliveries(1, 4).Return([]domain.WebhookDelivery{
+					{Id: 12, WebhookId: 4, Event: domain.EventItemCreated, Payload: []byte(`{"id":2}`),
+						Status: domain.DeliveryDelivered, Attempts: 1, LastStatusCode: &statusCode, CreatedAt: now,
+						DeliveredAt: &now},
+				}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Redeliver Webhook",
+			method: "POST",
+			path:   "/api/webhooks/4/deliveries/12/redeliver",
+			mockBehavior: func(m openAPIMocks) {
+				m.webhooks.EXPECT().Redeliver(1, 4, 12).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Create Access Token",
+			method:      "POST",
+			path:        "/api/tokens/",
+			contentType: "application/json",
+			body:        `{"name":"cli","scopes":["lists:read"]}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.tokens.EXPECT().Create(domain.Identity{UserId: 1, SessionId: 1}, gomock.Any()).
+					Return(domain.CreatedAccessToken{AccessToken: accessToken, Token: "t"}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Get All Access Tokens",
+			method: "GET",
+			path:   "/api/tokens/",
+			mockBehavior: func(m openAPIMocks) {
+				m.tokens.EXPECT().GetAll(1).Return([]domain.AccessToken{accessToken}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Revoke Access Token",
+			method: "DELETE",
+			path:   "/api/tokens/5",
+			mockBehavior: func(m openAPIMocks) {
+				m.tokens.EXPECT().Revoke(1, 5).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Merge Patch Item",
+			method:      "PATCH",
+			path:        "/api/items/2",
+			contentType: domain.MergePatchType,
+			body:        `{"description":null}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().Patch(1, 2, gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "JSON Patch List",
+			method:      "PATCH",
+			path:        "/api/lists/1",
+			contentType: domain.JSONPatchType,
+			body:        `[{"op":"replace","path":"/title","value":"work"}]`,
+			mockBehavior: func(m openAPIMocks) {
+				m.lists.EXPECT().Patch(1, 1, gomock.Any()).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Bulk Update Items",
+			method:      "POST",
+			path:        "/api/lists/1/items/bulk/update",
+			contentType: "application/json",
+			body:        `{"ids":[2,3],"update":{"done":true}}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.items.EXPECT().BulkUpdate(1, 1, gomock.Any()).Return(domain.BulkResponse{
+					Results: []domain.BulkResult{
+						{Index: 0, Id: 2, Status: domain.BulkApplied},
+						{Index: 1, Id: 3, Status: domain.BulkFailed, Error: "item not found"},
+					},
+				}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:   "Sync Changes",
+			method: "GET",
+			path:   "/api/sync?since=MTA",
+			mockBehavior: func(m openAPIMocks) {
+				m.sync.EXPECT().GetChanges(1, "MTA").Return(domain.SyncChanges{
+					Token:   "MTI",
+					Lists:   []domain.TodoList{list},
+					Items:   []domain.SyncItem{{TodoItem: item, ListId: 1}},
+					Deleted: domain.SyncDeleted{Lists: []int{}, Items: []int{4}},
+				}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "Apply Sync",
+			method:      "POST",
+			path:        "/api/sync",
+			contentType: "application/json",
+			body:        `{"mutations":[{"ref":"m1","op":"update","entity":"list","id":1,"version":1,"data":{"title":"x"}}]}`,
+			mockBehavior: func(m openAPIMocks) {
+				m.sync.EXPECT().Apply(1, gomock.Any()).Return([]domain.SyncResult{
+					{Ref: "m1", Status: domain.SyncConflict, Id: 1, Error: "list was changed", Current: list},
+				}, nil)
+			},
+			expectedStatusCode: 200,
+		},
+	}
+
+	covered := make(map[string]bool)
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			m := openAPIMocks{
+				auth:      mock_service.NewMockAuthorization(c),
+				tokens:    mock_service.NewMockAccessToken(c),
+				lists:     mock_service.NewMockTodoList(c),
+				members:   mock_service.NewMockListMember(c),
+				items:     mock_service.NewMockTodoItem(c),
+				labels:    mock_service.NewMockLabel(c),
+				reminders: mock_service.NewMockReminder(c),
+				webhooks:  mock_service.NewMockWebhook(c),
+				search:    mock_service.NewMockSearch(c),
+				sync:      mock_service.NewMockSync(c),
+			}
+			m.auth.EXPECT().ParseToken("token").Return(domain.Identity{UserId: 1, SessionId: 1}, nil).AnyTimes()
+			testCase.mockBehavior(m)
+
+			services := &service.Service{
+				Authorization: m.auth,
+				AccessToken:   m.tokens,
+				TodoList:      m.lists,
+				ListMember:    m.members,
+				TodoItem:      m.items,
+				Label:         m.labels,
+				Reminder:      m.reminders,
+				Webhook:       m.webhooks,
+				Search:        m.search,
+				Sync:          m.sync,
+			}
+			handler := NewHandler(services)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, testCase.path, bytes.NewBufferString(testCase.body))
+			req.Header.Set("Authorization", "Bearer token")
+			if testCase.contentType != "" {
+				req.Header.Set("Content-Type", testCase.contentType)
+			}
+			for key, value := range testCase.header {
+				req.Header.Set(key, value)
+			}
+
+			route, pathParams, err := router.FindRoute(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+			}
+			assert.NoError(t, openapi3filter.ValidateRequest(context.Background(), input))
+
+			// Perform Request
+			handler.InitRoutes().ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+
+			responseInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 w.Code,
+				Header:                 w.Header(),
+				Body:                   ioutil.NopCloser(strings.NewReader(w.Body.String())),
+			}
+			assert.NoError(t, openapi3filter.ValidateResponse(context.Background(), responseInput))
+
+			if w.Code < 400 {
+				covered[route.Operation.OperationID] = true
+			}
+		})
+	}
+
+	// the stream does not end, TestHandler_stream covers it
+	for path, item := range doc.Paths {
+		for method, operation := range item.Operations() {
+			if operation.OperationID != "stream" {
+				assert.True(t, covered[operation.OperationID], "%s %s has no successful case", method, path)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"io/ioutil"
+	"net/http"
+)
+
+// bindPatch reads a JSON merge patch or a JSON patch from the request, along
+// with the version the If-Match header requires.
+func bindPatch(c *gin.Context) (domain.PatchInput, bool) {
+	contentType := c.ContentType()
+	if contentType != domain.MergePatchType && contentType != domain.JSONPatchType {
+		newErrorResponse(c, http.StatusUnsupportedMediaType,
+			"content type must be "+domain.MergePatchType+" or "+domain.JSONPatchType)
+		return domain.PatchInput{}, false
+	}
+
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return domain.PatchInput{}, false
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		newErrorResponse(c, http.StatusBadRequest, "invalid If-Match header")
+		return domain.PatchInput{}, false
+	}
+
+	return domain.PatchInput{ContentType: contentType, Body: body, Version: version}, true
+}
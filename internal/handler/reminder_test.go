@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/service"
+	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_createReminder(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockReminder, reminder domain.Reminder)
+
+	offset := 30
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		inputReminder        domain.Reminder
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"offset_minutes":30,"channel":"email","target":"alice@example.com"}`,
+			inputReminder: domain.Reminder{
+				OffsetMinutes: &offset,
+				Channel:       domain.ChannelEmail,
+				Target:        "alice@example.com",
+			},
+			mockBehavior: func(s *mock_service.MockReminder, reminder domain.Reminder) {
+				s.EXPECT().Create(1, 2, reminder).Return(4, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"id":4}`,
+		},
+		{
+			name:      "Invalid Target",
+			inputBody: `{"offset_minutes":30,"channel":"webhook","target":"ftp://example.com"}`,
+			inputReminder: domain.Reminder{
+				OffsetMinutes: &offset,
+				Channel:       domain.ChannelWebhook,
+				Target:        "ftp://example.com",
+			},
+			mockBehavior: func(s *mock_service.MockReminder, reminder domain.Reminder) {
+				s.EXPECT().Create(1, 2, reminder).
+					Return(0, domain.NewError(domain.ErrValidation, "target must be an http or https URL"))
+			},
+			expectedStatusCode:   422,
+			expectedResponseBody: `{"code":"validation_error","message":"target must be an http or https URL"}`,
+		},
+		{
+			name:      "Item Not Found",
+			inputBody: `{"offset_minutes":30,"channel":"log"}`,
+			inputReminder: domain.Reminder{
+				OffsetMinutes: &offset,
+				Channel:       domain.ChannelLog,
+			},
+			mockBehavior: func(s *mock_service.MockReminder, reminder domain.Reminder) {
+				s.EXPECT().Create(1, 2, reminder).Return(0, domain.NewError(domain.ErrNotFound, "item not found"))
+			},
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"code":"not_found","message":"item not found"}`,
+		},
+		{
+			name:                 "Missing Channel",
+			inputBody:            `{"offset_minutes":30}`,
+			mockBehavior:         func(s *mock_service.MockReminder, reminder domain.Reminder) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			s := mock_service.NewMockReminder(c)
+			testCase.mockBehavior(s, testCase.inputReminder)
+
+			services := &service.Service{Reminder: s}
+			handler := NewHandler(services)
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.Use(withUserId(1))
+			r.POST("/api/items/:id/reminders", handler.createReminder)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/items/2/reminders", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.JSONEq(t, testCase.expectedResponseBody, w.Body.String())
+		})
+	}
+}
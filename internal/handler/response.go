@@ -1,11 +1,43 @@
 package handler
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
 	"github.com/sirupsen/logrus"
+	"net/http"
 )
 
+// Error codes are part of the API contract, clients match on them instead of
+// on messages.
+const (
+	codeBadRequest         = "bad_request"
+	codeUnauthorized       = "unauthorized"
+	codeForbidden          = "forbidden"
+	codeNotFound           = "not_found"
+	codeConflict           = "conflict"
+	codeGone               = "gone"
+	codePreconditionFailed = "precondition_failed"
+	codeUnsupportedMedia   = "unsupported_media_type"
+	codeValidationError    = "validation_error"
+	codeInternalError      = "internal_error"
+)
+
+var statusCodes = map[int]string{
+	http.StatusBadRequest:           codeBadRequest,
+	http.StatusUnauthorized:         codeUnauthorized,
+	http.StatusForbidden:            codeForbidden,
+	http.StatusNotFound:             codeNotFound,
+	http.StatusConflict:             codeConflict,
+	http.StatusGone:                 codeGone,
+	http.StatusPreconditionFailed:   codePreconditionFailed,
+	http.StatusUnsupportedMediaType: codeUnsupportedMedia,
+	http.StatusUnprocessableEntity:  codeValidationError,
+	http.StatusInternalServerError:  codeInternalError,
+}
+
 type errorResponse struct {
+	Code    string `json:"code"`
 	Message string `json:"message"`
 }
 
@@ -15,5 +47,54 @@ type statusResponse struct {
 
 func newErrorResponse(c *gin.Context, statusCode int, message string) {
 	logrus.Error(message)
-	c.AbortWithStatusJSON(statusCode, errorResponse{message})
+	writeErrorResponse(c, statusCode, message)
+}
+
+// writeErrorResponse aborts with the error response without logging it.
+func writeErrorResponse(c *gin.Context, statusCode int, message string) {
+	code, ok := statusCodes[statusCode]
+	if !ok {
+		code = codeInternalError
+	}
+
+	c.AbortWithStatusJSON(statusCode, errorResponse{Code: code, Message: message})
+}
+
+// abortWithError hands a service error over to errorHandler.
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// errorHandler translates the error left by a handler into a response with
+// the status code matching its domain error kind. Unknown errors are logged
+// and reported without details, since they may carry driver messages.
+func errorHandler(c *gin.Context) {
+	c.Next()
+
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	err := c.Errors.Last().Err
+
+	switch {
+	case errors.Is(err, domain.ErrValidation):
+		newErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, domain.ErrNotFound):
+		newErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, domain.ErrForbidden):
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, domain.ErrVersionMismatch):
+		newErrorResponse(c, http.StatusPreconditionFailed, err.Error())
+	case errors.Is(err, domain.ErrSyncTokenExpired):
+		newErrorResponse(c, http.StatusGone, err.Error())
+	case errors.Is(err, domain.ErrConflict):
+		newErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrUnauthorized):
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+	default:
+		logrus.Error(err.Error())
+		writeErrorResponse(c, http.StatusInternalServerError, "internal server error")
+	}
 }
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorHandler(t *testing.T) {
+	testTable := []struct {
+		name                 string
+		err                  error
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:                 "Not Found",
+			err:                  domain.NewError(domain.ErrNotFound, "list not found"),
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"code":"not_found","message":"list not found"}`,
+		},
+		{
+			name:                 "Forbidden",
+			err:                  domain.NewError(domain.ErrForbidden, "not enough permissions for the list"),
+			expectedStatusCode:   403,
+			expectedResponseBody: `{"code":"forbidden","message":"not enough permissions for the list"}`,
+		},
+		{
+			name:                 "Conflict",
+			err:                  domain.NewError(domain.ErrConflict, "username is already taken"),
+			expectedStatusCode:   409,
+			expectedResponseBody: `{"code":"conflict","message":"username is already taken"}`,
+		},
+		{
+			name:                 "Validation",
+			err:                  domain.NewError(domain.ErrValidation, "update structure has no value"),
+			expectedStatusCode:   422,
+			expectedResponseBody: `{"code":"validation_error","message":"update structure has no value"}`,
+		},
+		{
+			name:                 "Wrapped",
+			err:                  fmt.Errorf("load list: %w", domain.NewError(domain.ErrNotFound, "list not found")),
+			expectedStatusCode:   404,
+			expectedResponseBody: `{"code":"not_found","message":"load list: list not found"}`,
+		},
+		{
+			name:                 "Internal",
+			err:                  errors.New(`pq: duplicate key value violates unique constraint "users_username_key"`),
+			expectedStatusCode:   500,
+			expectedResponseBody: `{"code":"internal_error","message":"internal server error"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.GET("/", func(c *gin.Context) {
+				abortWithError(c, testCase.err)
+			})
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/", nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"net/http"
+	"strconv"
+)
+
+type searchResponse struct {
+	Data []domain.SearchResult `json:"data"`
+}
+
+func (h *Handler) search(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	input := domain.SearchInput{Query: c.Query("q")}
+	if limit := c.Query("limit"); limit != "" {
+		if input.Limit, err = strconv.Atoi(limit); err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "invalid limit param")
+			return
+		}
+	}
+
+	results, err := h.services.Search.Search(userId, input)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, searchResponse{
+		Data: results,
+	})
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/service"
+	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_search(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockSearch, input domain.SearchInput)
+
+	testTable := []struct {
+		name                 string
+		query                string
+		input                domain.SearchInput
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "OK",
+			query: "?q=milk&limit=5",
+			input: domain.SearchInput{Query: "milk", Limit: 5},
+			mockBehavior: func(s *mock_service.MockSearch, input domain.SearchInput) {
+				s.EXPECT().Search(1, input).Return([]domain.SearchResult{
+					{
+						Type:    domain.SearchResultItem,
+						Id:      3,
+						Title:   "buy milk",
+						Snippet: "buy <mark>milk</mark>",
+						Rank:    0.6,
+						List:    domain.SearchList{Id: 1, Title: "groceries"},
+					},
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"data":[{"type":"item","id":3,"title":"buy milk","snippet":"buy <mark>milk</mark>",` +
+				`"rank":0.6,"list":{"id":1,"title":"groceries"}}]}`,
+		},
+		{
+			name:  "Empty Query",
+			input: domain.SearchInput{},
+			mockBehavior: func(s *mock_service.MockSearch, input domain.SearchInput) {
+				s.EXPECT().Search(1, input).Return(nil, domain.NewError(domain.ErrValidation, "search query is empty"))
+			},
+			expectedStatusCode:   422,
+			expectedResponseBody: `{"code":"validation_error","message":"search query is empty"}`,
+		},
+		{
+			name:                 "Invalid Limit",
+			query:                "?q=milk&limit=all",
+			mockBehavior:         func(s *mock_service.MockSearch, input domain.SearchInput) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"code":"bad_request","message":"invalid limit param"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			s := mock_service.NewMockSearch(c)
+			testCase.mockBehavior(s, testCase.input)
+
+			services := &service.Service{Search: s}
+			handler := NewHandler(services)
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.Use(withUserId(1))
+			r.GET("/api/search", handler.search)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/search"+testCase.query, nil)
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.JSONEq(t, testCase.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	lastEventIdHeader = "Last-Event-ID"
+	// heartbeats keep proxies from closing idle streams
+	streamHeartbeat = 15 * time.Second
+	// milliseconds clients wait before they reconnect
+	streamRetry = 3000
+)
+
+// stream sends the changes of the lists and items the user can see as
+// Server-Sent Events. A client reconnecting with the Last-Event-ID header
+// gets the events it missed first.
+func (h *Handler) stream(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var lastEventId int64
+	if header := c.GetHeader(lastEventIdHeader); header != "" {
+		lastEventId, err = strconv.ParseInt(header, 10, 64)
+		if err != nil || lastEventId < 0 {
+			newErrorResponse(c, http.StatusBadRequest, "invalid Last-Event-ID header")
+			return
+		}
+	}
+
+	sub, err := h.services.Stream.Subscribe(userId, lastEventId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry); err != nil {
+		return
+	}
+
+	lastId := lastEventId
+	for _, event := range sub.Backlog {
+		if err := writeEvent(c.Writer, event); err != nil {
+			return
+		}
+		lastId = event.Id
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	// every step writes at most one event, c.Stream flushes it
+	done := c.Request.Context().Done()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return false
+			}
+
+			// the backlog may overlap the first new events
+			if event.Id <= lastId {
+				return true
+			}
+
+			if err := writeEvent(w, event); err != nil {
+				return false
+			}
+			lastId = event.Id
+
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		case <-done:
+			return false
+		}
+	})
+}
+
+func writeEvent(w io.Writer, event domain.StreamEvent) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, event.Payload)
+	return err
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/service"
+	"github.com/pavel-trbv/go-todo-app/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// streamRepo keeps the events of user 1 in memory.
+type streamRepo struct {
+	mu     sync.Mutex
+	events []domain.StreamEvent
+}
+
+func (r *streamRepo) add(payload string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, domain.StreamEvent{
+		Id:         int64(len(r.events) + 1),
+		Type:       domain.EventItemUpdated,
+		Payload:    []byte(payload),
+		Recipients: []int{1},
+	})
+}
+
+func (r *streamRepo) Store(event domain.Event) error {
+	return nil
+}
+
+func (r *streamRepo) GetSince(userId int, afterId int64, limit int) ([]domain.StreamEvent, error) {
+	return r.GetAllSince(afterId, limit)
+}
+
+func (r *streamRepo) GetAllSince(afterId int64, limit int) ([]domain.StreamEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]domain.StreamEvent, 0)
+	for _, event := range r.events {
+		if event.Id > afterId && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (r *streamRepo) LastId() (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return int64(len(r.events)), nil
+}
+
+func (r *streamRepo) Prune(before time.Time) error {
+	return nil
+}
+
+func TestHandler_stream(t *testing.T) {
+	testTable := []struct {
+		name  string
+		http2 bool
+	}{
+		{name: "HTTP/1.1"},
+		{name: "HTTP/2", http2: true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testStream(t, testCase.http2)
+		})
+	}
+}
+
+func testStream(t *testing.T, http2 bool) {
+	repo := &streamRepo{}
+	repo.add(`{"id":1}`)
+	repo.add(`{"id":2}`)
+	repo.add(`{"id":3}`)
+
+	notifications := make(chan *pq.Notification)
+	broker, err := stream.NewBroker(repo, notifications, stream.Config{
+		BatchSize:    10,
+		PollInterval: time.Hour,
+		Retention:    time.Hour,
+		Buffer:       10,
+		ReplayLimit:  10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go broker.Run()
+	defer broker.Shutdown(context.Background())
+
+	handler := NewHandler(&service.Service{Stream: broker})
+
+	r := gin.New()
+	r.Use(errorHandler)
+	r.Use(withUserId(1))
+	r.GET("/api/stream", handler.stream)
+
+	server := httptest.NewUnstartedServer(r)
+	if http2 {
+		server.EnableHTTP2 = true
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/api/stream", nil)
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, 200, resp.StatusCode)
+	if http2 {
+		assert.Equal(t, 2, resp.ProtoMajor)
+	}
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	body := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		var lines []string
+		for {
+			line, err := body.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if line == "\n" {
+				return strings.Join(lines, "")
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	assert.Equal(t, "retry: 3000\n", readEvent())
+	assert.Equal(t, "id: 2\nevent: item.updated\ndata: {\"id\":2}\n", readEvent())
+	assert.Equal(t, "id: 3\nevent: item.updated\ndata: {\"id\":3}\n", readEvent())
+
+	repo.add(`{"id":4}`)
+	notifications <- &pq.Notification{Extra: "4"}
+
+	assert.Equal(t, "id: 4\nevent: item.updated\ndata: {\"id\":4}\n", readEvent())
+}
+
+func TestHandler_stream_InvalidLastEventId(t *testing.T) {
+	handler := NewHandler(&service.Service{})
+
+	r := gin.New()
+	r.Use(errorHandler)
+	r.Use(withUserId(1))
+	r.GET("/api/stream", handler.stream)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/stream", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 400, w.Code)
+	assert.JSONEq(t, `{"code":"bad_request","message":"invalid Last-Event-ID header"}`, w.Body.String())
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"net/http"
+)
+
+// getSyncChanges returns what changed since the token in the since param,
+// without one it returns everything the user can see.
+func (h *Handler) getSyncChanges(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	changes, err := h.services.Sync.GetChanges(userId, c.Query("since"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+type applySyncResponse struct {
+	Results []domain.SyncResult `json:"results"`
+}
+
+func (h *Handler) applySync(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var input domain.SyncInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	results, err := h.services.Sync.Apply(userId, input)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, applySyncResponse{
+		Results: results,
+	})
+}
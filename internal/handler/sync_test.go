@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/service"
+	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_getSyncChanges(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockSync, token string)
+
+	testTable := []struct {
+		name                 string
+		query                string
+		token                string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:  "OK",
+			query: "?since=MTA",
+			token: "MTA",
+			mockBehavior: func(s *mock_service.MockSync, token string) {
+				s.EXPECT().GetChanges(1, token).Return(domain.SyncChanges{
+					Token: "MTI",
+					Lists: []domain.TodoList{},
+					Items: []domain.SyncItem{{TodoItem: domain.TodoItem{Id: 5, Title: "milk", Version: 2}, ListId: 1}},
+					Deleted: domain.SyncDeleted{
+						Lists: []int{2},
+						Items: []int{},
+					},
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"token":"MTI","lists":[],"items":[{"id":5,"title":"milk","description":null,` +
+				`"done":false,"due_at":null,"priority":"","created_at":"0001-01-01T00:00:00Z",` +
+				`"updated_at":"0001-01-01T00:00:00Z","completed_at":null,"parent_id":null,"position":"",` +
+				`"auto_complete":false,"recurrence":"","timezone":"","version":2,"labels":null,"list_id":1}],` +
+				`"deleted":{"lists":[2],"items":[]}}`,
+		},
+		{
+			name:  "Invalid Token",
+			query: "?since=abc",
+			token: "abc",
+			mockBehavior: func(s *mock_service.MockSync, token string) {
+				s.EXPECT().GetChanges(1, token).Return(domain.SyncChanges{},
+					domain.NewError(domain.ErrValidation, "invalid sync token"))
+			},
+			expectedStatusCode:   422,
+			expectedResponseBody: `{"code":"validation_error","message":"invalid sync token"}`,
+		},
+		{
+			name:  "Expired Token",
+			query: "?since=MTA",
+			token: "MTA",
+			mockBehavior: func(s *mock_service.MockSync, token string) {
+				s.EXPECT().GetChanges(1, token).Return(domain.SyncChanges{},
+					domain.NewError(domain.ErrSyncTokenExpired, "sync token expired, sync again without a token"))
+			},
+			expectedStatusCode:   410,
+			expectedResponseBody: `{"code":"gone","message":"sync token expired, sync again without a token"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			s := mock_service.NewMockSync(c)
+			testCase.mockBehavior(s, testCase.token)
+
+			services := &service.Service{Sync: s}
+			handler := NewHandler(services)
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.Use(withUserId(1))
+			r.GET("/api/sync", handler.getSyncChanges)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/sync"+testCase.query, nil)
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.JSONEq(t, testCase.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_applySync(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockSync, input domain.SyncInput)
+
+	version := 2
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		input                domain.SyncInput
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			inputBody: `{"mutations":[{"ref":"a","op":"create","entity":"list","data":{"title":"groceries"}},` +
+				`{"ref":"b","op":"update","entity":"list","id":5,"version":2,"data":{"title":"food"}}]}`,
+			input: domain.SyncInput{Mutations: []domain.SyncMutation{
+				{Ref: "a", Op: domain.SyncCreate, Entity: domain.EntityList, Data: json.RawMessage(`{"title":"groceries"}`)},
+				{Ref: "b", Op: domain.SyncUpdate, Entity: domain.EntityList, Id: 5, Version: &version,
+					Data: json.RawMessage(`{"title":"food"}`)},
+			}},
+			mockBehavior: func(s *mock_service.MockSync, input domain.SyncInput) {
+				s.EXPECT().Apply(1, input).Return([]domain.SyncResult{
+					{Ref: "a", Status: domain.SyncApplied, Id: 3},
+					{Ref: "b", Status: domain.SyncConflict, Id: 5, Error: "list was changed, its version is 4",
+						Current: domain.TodoList{Id: 5, Title: "groceries", Version: 4}},
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"results":[{"ref":"a","status":"applied","id":3},` +
+				`{"ref":"b","status":"conflict","id":5,"error":"list was changed, its version is 4",` +
+				`"current":{"id":5,"title":"groceries","description":null,"position":"","version":4}}]}`,
+		},
+		{
+			name:      "Invalid Mutation",
+			inputBody: `{"mutations":[{"ref":"a","op":"delete","entity":"list"}]}`,
+			input: domain.SyncInput{Mutations: []domain.SyncMutation{
+				{Ref: "a", Op: domain.SyncDelete, Entity: domain.EntityList},
+			}},
+			mockBehavior: func(s *mock_service.MockSync, input domain.SyncInput) {
+				s.EXPECT().Apply(1, input).Return(nil,
+					domain.NewError(domain.ErrValidation, "mutation a: id and version are required"))
+			},
+			expectedStatusCode:   422,
+			expectedResponseBody: `{"code":"validation_error","message":"mutation a: id and version are required"}`,
+		},
+		{
+			name:                 "Empty Body",
+			inputBody:            `{}`,
+			mockBehavior:         func(s *mock_service.MockSync, input domain.SyncInput) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"code":"bad_request","message":"Key: 'SyncInput.Mutations' Error:Field validation for 'Mutations' failed on the 'required' tag"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			s := mock_service.NewMockSync(c)
+			testCase.mockBehavior(s, testCase.input)
+
+			services := &service.Service{Sync: s}
+			handler := NewHandler(services)
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.Use(withUserId(1))
+			r.POST("/api/sync", handler.applySync)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/sync", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.JSONEq(t, testCase.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -28,7 +28,7 @@ func (h *Handler) createItem(c *gin.Context) {
 
 	id, err := h.services.TodoItem.Create(userId, listId, input)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		abortWithError(c, err)
 		return
 	}
 
@@ -37,6 +37,102 @@ func (h *Handler) createItem(c *gin.Context) {
 	})
 }
 
+func (h *Handler) bulkCreateItems(c *gin.Context) {
+	userId, listId, ok := bulkParams(c)
+	if !ok {
+		return
+	}
+
+	var input domain.BulkCreateItemsInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.services.TodoItem.BulkCreate(userId, listId, input)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) bulkUpdateItems(c *gin.Context) {
+	userId, listId, ok := bulkParams(c)
+	if !ok {
+		return
+	}
+
+	var input domain.BulkUpdateItemsInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.services.TodoItem.BulkUpdate(userId, listId, input)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) bulkDeleteItems(c *gin.Context) {
+	userId, listId, ok := bulkParams(c)
+	if !ok {
+		return
+	}
+
+	var input domain.BulkDeleteItemsInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.services.TodoItem.BulkDelete(userId, listId, input)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) clearCompletedItems(c *gin.Context) {
+	userId, listId, ok := bulkParams(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.services.TodoItem.ClearCompleted(userId, listId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// bulkParams reads the user and the list of a bulk request, it responds with
+// an error when they are missing.
+func bulkParams(c *gin.Context) (int, int, bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return 0, 0, false
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid list id param")
+		return 0, 0, false
+	}
+
+	return userId, listId, true
+}
+
 func (h *Handler) getAllItems(c *gin.Context) {
 	userId, err := getUserId(c)
 	if err != nil {
@@ -50,9 +146,15 @@ func (h *Handler) getAllItems(c *gin.Context) {
 		return
 	}
 
-	items, err := h.services.TodoItem.GetAll(userId, listId)
+	filter, err := parseItemFilter(c)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, err := h.services.TodoItem.GetAll(userId, listId, filter)
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
@@ -74,7 +176,11 @@ func (h *Handler) getItemById(c *gin.Context) {
 
 	item, err := h.services.TodoItem.GetById(userId, itemId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		abortWithError(c, err)
+		return
+	}
+
+	if notModified(c, item.Version) {
 		return
 	}
 
@@ -96,15 +202,50 @@ func (h *Handler) updateItem(c *gin.Context) {
 
 	var input domain.UpdateItemInput
 	if err := c.BindJSON(&input); err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		newErrorResponse(c, http.StatusBadRequest, "invalid If-Match header")
 		return
 	}
+	if version != nil {
+		input.Version = version
+	}
 
 	if err := h.services.TodoItem.Update(userId, id, input); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) patchItem(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	input, ok := bindPatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.TodoItem.Patch(userId, id, input); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
 	c.Status(http.StatusOK)
 }
 
@@ -121,11 +262,161 @@ func (h *Handler) deleteItem(c *gin.Context) {
 		return
 	}
 
-	err = h.services.TodoItem.Delete(userId, itemId)
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		newErrorResponse(c, http.StatusBadRequest, "invalid If-Match header")
+		return
+	}
+
+	err = h.services.TodoItem.Delete(userId, itemId, version)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		abortWithError(c, err)
 		return
 	}
 
 	c.Status(http.StatusOK)
 }
+
+func (h *Handler) createChildItem(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	parentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.TodoItem
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	id, err := h.services.TodoItem.CreateChild(userId, parentId, input)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id": id,
+	})
+}
+
+type getChildItemsResponse struct {
+	Data []domain.TodoItem `json:"data"`
+}
+
+func (h *Handler) getChildItems(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	parentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	items, err := h.services.TodoItem.GetChildren(userId, parentId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getChildItemsResponse{
+		Data: items,
+	})
+}
+
+func (h *Handler) reorderChildItems(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	parentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.ReorderItemsInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.TodoItem.ReorderChildren(userId, parentId, input.Ids); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+func (h *Handler) moveItem(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.MoveInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+		return
+	}
+
+	if err := h.services.TodoItem.Move(userId, itemId, input); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+func (h *Handler) copyItem(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	itemId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	var input domain.CopyItemInput
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&input); err != nil {
+			newErrorResponse(c, http.StatusBadRequest, "invalid input body")
+			return
+		}
+	}
+
+	id, err := h.services.TodoItem.Copy(userId, itemId, input)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id": id,
+	})
+}
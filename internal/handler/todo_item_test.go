@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/service"
+	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_bulkUpdateItems(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTodoItem, input domain.BulkUpdateItemsInput)
+
+	done := true
+
+	testTable := []struct {
+		name                 string
+		listId               string
+		inputBody            string
+		input                domain.BulkUpdateItemsInput
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "OK",
+			listId:    "1",
+			inputBody: `{"mode":"best_effort","ids":[5,6],"update":{"done":true}}`,
+			input: domain.BulkUpdateItemsInput{
+				Mode:   domain.BulkBestEffort,
+				Ids:    []int{5, 6},
+				Update: domain.UpdateItemInput{Done: &done},
+			},
+			mockBehavior: func(s *mock_service.MockTodoItem, input domain.BulkUpdateItemsInput) {
+				s.EXPECT().BulkUpdate(1, 1, input).Return(domain.BulkResponse{
+					Committed: true,
+					Results: []domain.BulkResult{
+						{Index: 0, Id: 5, Status: domain.BulkApplied},
+						{Index: 1, Id: 6, Status: domain.BulkFailed, Error: "item not found"},
+					},
+				}, nil)
+			},
+			expectedStatusCode: 200,
+			expectedResponseBody: `{"committed":true,"results":[{"index":0,"id":5,"status":"applied"},` +
+				`{"index":1,"id":6,"status":"failed","error":"item not found"}]}`,
+		},
+		{
+			name:      "Invalid Mode",
+			listId:    "1",
+			inputBody: `{"mode":"some","ids":[5],"update":{"done":true}}`,
+			input: domain.BulkUpdateItemsInput{
+				Mode:   "some",
+				Ids:    []int{5},
+				Update: domain.UpdateItemInput{Done: &done},
+			},
+			mockBehavior: func(s *mock_service.MockTodoItem, input domain.BulkUpdateItemsInput) {
+				s.EXPECT().BulkUpdate(1, 1, input).Return(domain.BulkResponse{},
+					domain.NewError(domain.ErrValidation, "invalid mode"))
+			},
+			expectedStatusCode:   422,
+			expectedResponseBody: `{"code":"validation_error","message":"invalid mode"}`,
+		},
+		{
+			name:                 "Invalid List Id",
+			listId:               "abc",
+			inputBody:            `{"ids":[5],"update":{"done":true}}`,
+			mockBehavior:         func(s *mock_service.MockTodoItem, input domain.BulkUpdateItemsInput) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"code":"bad_request","message":"invalid list id param"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			s := mock_service.NewMockTodoItem(c)
+			testCase.mockBehavior(s, testCase.input)
+
+			services := &service.Service{TodoItem: s}
+			handler := NewHandler(services)
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.Use(withUserId(1))
+			r.POST("/api/lists/:id/items/bulk/update", handler.bulkUpdateItems)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/lists/"+testCase.listId+"/items/bulk/update",
+				bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.JSONEq(t, testCase.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_patchItem(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTodoItem)
+
+	version := 3
+
+	testTable := []struct {
+		name                 string
+		contentType          string
+		ifMatch              string
+		inputBody            string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "Merge Patch",
+			contentType: "application/merge-patch+json",
+			inputBody:   `{"description":null}`,
+			mockBehavior: func(s *mock_service.MockTodoItem) {
+				s.EXPECT().Patch(1, 2, domain.PatchInput{
+					ContentType: domain.MergePatchType,
+					Body:        []byte(`{"description":null}`),
+				}).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:        "JSON Patch",
+			contentType: "application/json-patch+json; charset=utf-8",
+			ifMatch:     `"3"`,
+			inputBody:   `[{"op":"replace","path":"/done","value":true}]`,
+			mockBehavior: func(s *mock_service.MockTodoItem) {
+				s.EXPECT().Patch(1, 2, domain.PatchInput{
+					ContentType: domain.JSONPatchType,
+					Body:        []byte(`[{"op":"replace","path":"/done","value":true}]`),
+					Version:     &version,
+				}).Return(domain.NewError(domain.ErrVersionMismatch, "item was changed, its version is 4"))
+			},
+			expectedStatusCode:   412,
+			expectedResponseBody: `{"code":"precondition_failed","message":"item was changed, its version is 4"}`,
+		},
+		{
+			name:        "Field Can Not Be Changed",
+			contentType: "application/merge-patch+json",
+			inputBody:   `{"id":5}`,
+			mockBehavior: func(s *mock_service.MockTodoItem) {
+				s.EXPECT().Patch(1, 2, domain.PatchInput{
+					ContentType: domain.MergePatchType,
+					Body:        []byte(`{"id":5}`),
+				}).Return(domain.NewError(domain.ErrValidation, "field id can not be changed"))
+			},
+			expectedStatusCode:   422,
+			expectedResponseBody: `{"code":"validation_error","message":"field id can not be changed"}`,
+		},
+		{
+			name:               "Unsupported Media Type",
+			contentType:        "application/json",
+			inputBody:          `{"title":"new title"}`,
+			mockBehavior:       func(s *mock_service.MockTodoItem) {},
+			expectedStatusCode: 415,
+			expectedResponseBody: `{"code":"unsupported_media_type",` +
+				`"message":"content type must be application/merge-patch+json or application/json-patch+json"}`,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			s := mock_service.NewMockTodoItem(c)
+			test.mockBehavior(s)
+
+			services := &service.Service{TodoItem: s}
+			handler := NewHandler(services)
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.Use(withUserId(1))
+			r.PATCH("/api/items/:id", handler.patchItem)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/api/items/2", bytes.NewBufferString(test.inputBody))
+			req.Header.Set("Content-Type", test.contentType)
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			if test.expectedResponseBody != "" {
+				assert.JSONEq(t, test.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -23,6 +23,9 @@ func withUserId(userId interface{}) gin.HandlerFunc {
 }
 
 func TestHandler_createList(t *testing.T) {
+	description := "list desc"
+	shortDescription := "desc"
+
 	type mockBehavior func(s *mock_service.MockTodoList, userId interface{}, list domain.TodoList)
 
 	testTable := []struct {
@@ -39,7 +42,7 @@ func TestHandler_createList(t *testing.T) {
 			inputBody: `{"title":"list title","description":"list desc"}`,
 			inputList: domain.TodoList{
 				Title:       "list title",
-				Description: "list desc",
+				Description: &description,
 			},
 			userId: 1,
 			mockBehavior: func(s *mock_service.MockTodoList, userId interface{}, list domain.TodoList) {
@@ -65,25 +68,25 @@ func TestHandler_createList(t *testing.T) {
 			name:      "Provide only description",
 			inputBody: `{"description":"desc"}`,
 			inputList: domain.TodoList{
-				Description: "desc",
+				Description: &shortDescription,
 			},
 			userId:              1,
 			mockBehavior:        func(s *mock_service.MockTodoList, userId interface{}, list domain.TodoList) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message": "invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:                "Missing user id",
 			mockBehavior:        func(s *mock_service.MockTodoList, userId interface{}, list domain.TodoList) {},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"user id not found"}`,
+			expectedRequestBody: `{"code":"internal_error","message":"user id not found"}`,
 		},
 		{
 			name:                "Invalid type of user id",
 			userId:              "1",
 			mockBehavior:        func(s *mock_service.MockTodoList, userId interface{}, list domain.TodoList) {},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"user id is of invalid type"}`,
+			expectedRequestBody: `{"code":"internal_error","message":"user id is of invalid type"}`,
 		},
 		{
 			name:                "Missing Fields",
@@ -91,7 +94,7 @@ func TestHandler_createList(t *testing.T) {
 			userId:              1,
 			mockBehavior:        func(s *mock_service.MockTodoList, userId interface{}, list domain.TodoList) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:                "Invalid input",
@@ -99,21 +102,21 @@ func TestHandler_createList(t *testing.T) {
 			userId:              1,
 			mockBehavior:        func(s *mock_service.MockTodoList, userId interface{}, list domain.TodoList) {},
 			expectedStatusCode:  400,
-			expectedRequestBody: `{"message":"invalid input body"}`,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid input body"}`,
 		},
 		{
 			name:      "Service Error",
 			inputBody: `{"title":"list title","description":"list desc"}`,
 			inputList: domain.TodoList{
 				Title:       "list title",
-				Description: "list desc",
+				Description: &description,
 			},
 			userId: 1,
 			mockBehavior: func(s *mock_service.MockTodoList, userId interface{}, list domain.TodoList) {
 				s.EXPECT().Create(userId, list).Return(0, errors.New("service error"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service error"}`,
+			expectedRequestBody: `{"code":"internal_error","message":"internal server error"}`,
 		},
 	}
 
@@ -131,6 +134,7 @@ func TestHandler_createList(t *testing.T) {
 
 			// Test Server
 			r := gin.New()
+			r.Use(errorHandler)
 			r.Use(func(ctx *gin.Context) {
 				if test.userId != nil {
 					ctx.Set(userCtx, test.userId)
@@ -154,19 +158,28 @@ func TestHandler_createList(t *testing.T) {
 }
 
 func TestHandler_getAllLists(t *testing.T) {
-	type mockBehavior func(s *mock_service.MockTodoList, userId interface{})
+	description := "desc"
 
-	lists := []domain.TodoList{
-		{
-			Id:          1,
-			Title:       "list",
-			Description: "desc",
+	type mockBehavior func(s *mock_service.MockTodoList, userId interface{}, filter domain.ListFilter)
+
+	lists := domain.ListPage{
+		Data: []domain.TodoList{
+			{
+				Id:          1,
+				Title:       "list",
+				Description: &description,
+				Role:        domain.RoleOwner,
+				Position:    "i",
+				Version:     3,
+			},
 		},
 	}
 
 	testTable := []struct {
 		name                string
 		userId              interface{}
+		query               string
+		filter              domain.ListFilter
 		mockBehavior        mockBehavior
 		expectedStatusCode  int
 		expectedRequestBody string
@@ -174,33 +187,68 @@ func TestHandler_getAllLists(t *testing.T) {
 		{
 			name:   "OK",
 			userId: 1,
-			mockBehavior: func(s *mock_service.MockTodoList, userId interface{}) {
-				s.EXPECT().GetAll(userId).Return(lists, nil)
+			mockBehavior: func(s *mock_service.MockTodoList, userId interface{}, filter domain.ListFilter) {
+				s.EXPECT().GetAll(userId, filter).Return(lists, nil)
 			},
 			expectedStatusCode:  200,
-			expectedRequestBody: `{"data": [{ "id": 1, "title": "list", "description": "desc" }]}`,
+			expectedRequestBody: `{"data": [{ "id": 1, "title": "list", "description": "desc", "role": "owner", "position": "i", "version": 3 }]}`,
+		},
+		{
+			name:   "With Filter",
+			userId: 1,
+			query:  "?q=work&sort=title&order=desc&limit=1&cursor=abc",
+			filter: domain.ListFilter{
+				Page:  domain.Page{Sort: "title", Order: "desc", Limit: 1, Cursor: "abc"},
+				Query: "work",
+			},
+			mockBehavior: func(s *mock_service.MockTodoList, userId interface{}, filter domain.ListFilter) {
+				page := lists
+				page.NextCursor = "next"
+				s.EXPECT().GetAll(userId, filter).Return(page, nil)
+			},
+			expectedStatusCode:  200,
+			expectedRequestBody: `{"data": [{ "id": 1, "title": "list", "description": "desc", "role": "owner", "position": "i", "version": 3 }], "next_cursor": "next"}`,
+		},
+		{
+			name:                "Invalid Limit",
+			userId:              1,
+			query:               "?limit=ten",
+			mockBehavior:        func(s *mock_service.MockTodoList, userId interface{}, filter domain.ListFilter) {},
+			expectedStatusCode:  400,
+			expectedRequestBody: `{"code":"bad_request","message":"invalid limit param"}`,
+		},
+		{
+			name:   "Invalid Sort",
+			userId: 1,
+			query:  "?sort=password",
+			filter: domain.ListFilter{Page: domain.Page{Sort: "password"}},
+			mockBehavior: func(s *mock_service.MockTodoList, userId interface{}, filter domain.ListFilter) {
+				s.EXPECT().GetAll(userId, filter).Return(domain.ListPage{}, domain.NewError(domain.ErrValidation, "invalid sort field"))
+			},
+			expectedStatusCode:  422,
+			expectedRequestBody: `{"code":"validation_error","message":"invalid sort field"}`,
 		},
 		{
 			name:                "Missing user id",
-			mockBehavior:        func(s *mock_service.MockTodoList, userId interface{}) {},
+			mockBehavior:        func(s *mock_service.MockTodoList, userId interface{}, filter domain.ListFilter) {},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"user id not found"}`,
+			expectedRequestBody: `{"code":"internal_error","message":"user id not found"}`,
 		},
 		{
 			name:                "Invalid type of user id",
 			userId:              "1",
-			mockBehavior:        func(s *mock_service.MockTodoList, userId interface{}) {},
+			mockBehavior:        func(s *mock_service.MockTodoList, userId interface{}, filter domain.ListFilter) {},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"user id is of invalid type"}`,
+			expectedRequestBody: `{"code":"internal_error","message":"user id is of invalid type"}`,
 		},
 		{
 			name:   "Service Error",
 			userId: 1,
-			mockBehavior: func(s *mock_service.MockTodoList, userId interface{}) {
-				s.EXPECT().GetAll(userId).Return(nil, errors.New("service error"))
+			mockBehavior: func(s *mock_service.MockTodoList, userId interface{}, filter domain.ListFilter) {
+				s.EXPECT().GetAll(userId, filter).Return(domain.ListPage{}, errors.New("service error"))
 			},
 			expectedStatusCode:  500,
-			expectedRequestBody: `{"message":"service error"}`,
+			expectedRequestBody: `{"code":"internal_error","message":"internal server error"}`,
 		},
 	}
 
@@ -211,19 +259,20 @@ func TestHandler_getAllLists(t *testing.T) {
 			defer c.Finish()
 
 			s := mock_service.NewMockTodoList(c)
-			test.mockBehavior(s, test.userId)
+			test.mockBehavior(s, test.userId, test.filter)
 
 			services := &service.Service{TodoList: s}
 			handler := NewHandler(services)
 
 			// Test Server
 			r := gin.New()
+			r.Use(errorHandler)
 			r.Use(withUserId(test.userId))
 			r.GET("/api/lists", handler.getAllLists)
 
 			// Test Request
 			w := httptest.NewRecorder()
-			req := httptest.NewRequest("GET", "/api/lists", nil)
+			req := httptest.NewRequest("GET", "/api/lists"+test.query, nil)
 
 			// Perform Request
 			r.ServeHTTP(w, req)
@@ -234,3 +283,168 @@ func TestHandler_getAllLists(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_getListById(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTodoList)
+
+	testTable := []struct {
+		name                 string
+		ifNoneMatch          string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedETag         string
+		expectedResponseBody string
+	}{
+		{
+			name: "OK",
+			mockBehavior: func(s *mock_service.MockTodoList) {
+				s.EXPECT().GetById(1, 2).Return(domain.TodoList{Id: 2, Title: "title", Version: 3}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedETag:         `"3"`,
+			expectedResponseBody: `{"id":2,"title":"title","description":null,"position":"","version":3}`,
+		},
+		{
+			name:        "Not Modified",
+			ifNoneMatch: `"2", W/"3"`,
+			mockBehavior: func(s *mock_service.MockTodoList) {
+				s.EXPECT().GetById(1, 2).Return(domain.TodoList{Id: 2, Title: "title", Version: 3}, nil)
+			},
+			expectedStatusCode: 304,
+			expectedETag:       `"3"`,
+		},
+		{
+			name:        "Modified",
+			ifNoneMatch: `"2"`,
+			mockBehavior: func(s *mock_service.MockTodoList) {
+				s.EXPECT().GetById(1, 2).Return(domain.TodoList{Id: 2, Title: "title", Version: 3}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedETag:         `"3"`,
+			expectedResponseBody: `{"id":2,"title":"title","description":null,"position":"","version":3}`,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			s := mock_service.NewMockTodoList(c)
+			test.mockBehavior(s)
+
+			services := &service.Service{TodoList: s}
+			handler := NewHandler(services)
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.Use(withUserId(1))
+			r.GET("/api/lists/:id", handler.getListById)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/lists/2", nil)
+			if test.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", test.ifNoneMatch)
+			}
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedETag, w.Header().Get("ETag"))
+			if test.expectedResponseBody == "" {
+				assert.Empty(t, w.Body.String())
+			} else {
+				assert.JSONEq(t, test.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_updateList(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockTodoList)
+
+	title := "new title"
+	version := 3
+
+	testTable := []struct {
+		name                 string
+		ifMatch              string
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:    "OK",
+			ifMatch: `"3"`,
+			mockBehavior: func(s *mock_service.MockTodoList) {
+				s.EXPECT().Update(1, 2, domain.UpdateListInput{Title: &title, Version: &version}).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name: "Without If-Match",
+			mockBehavior: func(s *mock_service.MockTodoList) {
+				s.EXPECT().Update(1, 2, domain.UpdateListInput{Title: &title}).Return(nil)
+			},
+			expectedStatusCode: 200,
+		},
+		{
+			name:    "Precondition Failed",
+			ifMatch: `"3"`,
+			mockBehavior: func(s *mock_service.MockTodoList) {
+				s.EXPECT().Update(1, 2, domain.UpdateListInput{Title: &title, Version: &version}).
+					Return(domain.NewError(domain.ErrVersionMismatch, "list was changed, its version is 4"))
+			},
+			expectedStatusCode:   412,
+			expectedResponseBody: `{"code":"precondition_failed","message":"list was changed, its version is 4"}`,
+		},
+		{
+			name:                 "Invalid If-Match",
+			ifMatch:              `3`,
+			mockBehavior:         func(s *mock_service.MockTodoList) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"code":"bad_request","message":"invalid If-Match header"}`,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			s := mock_service.NewMockTodoList(c)
+			test.mockBehavior(s)
+
+			services := &service.Service{TodoList: s}
+			handler := NewHandler(services)
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.Use(withUserId(1))
+			r.PUT("/api/lists/:id", handler.updateList)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/api/lists/2", bytes.NewBufferString(`{"title":"new title"}`))
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			if test.expectedResponseBody != "" {
+				assert.JSONEq(t, test.expectedResponseBody, w.Body.String())
+			}
+		})
+	}
+}
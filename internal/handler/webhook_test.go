@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/service"
+	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_createWebhook(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockWebhook, input domain.CreateWebhookInput)
+
+	testTable := []struct {
+		name                 string
+		inputBody            string
+		input                domain.CreateWebhookInput
+		mockBehavior         mockBehavior
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:      "OK",
+			inputBody: `{"url":"https://example.com/hooks","events":["item.created","item.completed"]}`,
+			input: domain.CreateWebhookInput{
+				URL:    "https://example.com/hooks",
+				Events: []string{domain.EventItemCreated, domain.EventItemCompleted},
+			},
+			mockBehavior: func(s *mock_service.MockWebhook, input domain.CreateWebhookInput) {
+				s.EXPECT().Create(1, input).Return(domain.CreatedWebhook{Id: 3, Secret: "s3cr3t"}, nil)
+			},
+			expectedStatusCode:   200,
+			expectedResponseBody: `{"id":3,"secret":"s3cr3t"}`,
+		},
+		{
+			name:      "Unknown Event",
+			inputBody: `{"url":"https://example.com/hooks","events":["item.renamed"]}`,
+			input: domain.CreateWebhookInput{
+				URL:    "https://example.com/hooks",
+				Events: []string{"item.renamed"},
+			},
+			mockBehavior: func(s *mock_service.MockWebhook, input domain.CreateWebhookInput) {
+				s.EXPECT().Create(1, input).
+					Return(domain.CreatedWebhook{}, domain.NewError(domain.ErrValidation, "invalid event type item.renamed"))
+			},
+			expectedStatusCode:   422,
+			expectedResponseBody: `{"code":"validation_error","message":"invalid event type item.renamed"}`,
+		},
+		{
+			name:                 "Missing Events",
+			inputBody:            `{"url":"https://example.com/hooks"}`,
+			mockBehavior:         func(s *mock_service.MockWebhook, input domain.CreateWebhookInput) {},
+			expectedStatusCode:   400,
+			expectedResponseBody: `{"code":"bad_request","message":"invalid input body"}`,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			// Init Deps
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			s := mock_service.NewMockWebhook(c)
+			testCase.mockBehavior(s, testCase.input)
+
+			services := &service.Service{Webhook: s}
+			handler := NewHandler(services)
+
+			// Test Server
+			r := gin.New()
+			r.Use(errorHandler)
+			r.Use(withUserId(1))
+			r.POST("/api/webhooks/", handler.createWebhook)
+
+			// Test Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/webhooks/", bytes.NewBufferString(testCase.inputBody))
+
+			// Perform Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.JSONEq(t, testCase.expectedResponseBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package hash
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash reports whether the encoded hash was produced by another
+	// algorithm or with weaker parameters than the hasher is configured with.
+	NeedsRehash(encoded string) bool
+}
+
+// Algorithm is a PasswordHasher that can recognize its own encoded hashes.
+type Algorithm interface {
+	PasswordHasher
+	Supports(encoded string) bool
+}
+
+// MultiHasher hashes new passwords with the preferred algorithm and verifies
+// hashes produced by any of the known ones, so that stored hashes can be
+// upgraded on the next successful login.
+type MultiHasher struct {
+	preferred  Algorithm
+	algorithms []Algorithm
+}
+
+func NewMultiHasher(preferred Algorithm, legacy ...Algorithm) *MultiHasher {
+	return &MultiHasher{
+		preferred:  preferred,
+		algorithms: append([]Algorithm{preferred}, legacy...),
+	}
+}
+
+func (h *MultiHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+func (h *MultiHasher) Verify(password, encoded string) (bool, error) {
+	for _, algorithm := range h.algorithms {
+		if algorithm.Supports(encoded) {
+			return algorithm.Verify(password, encoded)
+		}
+	}
+
+	return false, ErrUnknownAlgorithm
+}
+
+func (h *MultiHasher) NeedsRehash(encoded string) bool {
+	if !h.preferred.Supports(encoded) {
+		return true
+	}
+
+	return h.preferred.NeedsRehash(encoded)
+}
+
+// Config selects the algorithm new passwords are hashed with. Hashes of every
+// other supported algorithm are still verified, so that switching it does not
+// lock out users whose passwords were hashed under the previous setting.
+type Config struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2id   Argon2idParams
+	// LegacySalt is the salt of the SHA-1 hashes of the first releases.
+	LegacySalt string
+}
+
+func NewPasswordHasher(cfg Config) (*MultiHasher, error) {
+	bcrypt := NewBcryptHasher(cfg.BcryptCost)
+	argon2id := NewArgon2idHasher(cfg.Argon2id)
+	legacy := NewSHA1Hasher(cfg.LegacySalt)
+
+	switch cfg.Algorithm {
+	case "bcrypt":
+		return NewMultiHasher(bcrypt, argon2id, legacy), nil
+	case "argon2id":
+		return NewMultiHasher(argon2id, bcrypt, legacy), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, cfg.Algorithm)
+	}
+}
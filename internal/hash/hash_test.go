@@ -0,0 +1,134 @@
+package hash
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const legacySalt = "fsdf7ashagbsv789sa11"
+
+func TestAlgorithms(t *testing.T) {
+	testTable := []struct {
+		name      string
+		algorithm Algorithm
+	}{
+		{
+			name:      "bcrypt",
+			algorithm: NewBcryptHasher(4),
+		},
+		{
+			name: "argon2id",
+			algorithm: NewArgon2idHasher(Argon2idParams{
+				Memory:      1024,
+				Iterations:  1,
+				Parallelism: 1,
+			}),
+		},
+		{
+			name:      "sha1",
+			algorithm: NewSHA1Hasher(legacySalt),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			encoded, err := testCase.algorithm.Hash("qwerty")
+			assert.NoError(t, err)
+			assert.True(t, testCase.algorithm.Supports(encoded))
+
+			ok, err := testCase.algorithm.Verify("qwerty", encoded)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = testCase.algorithm.Verify("wrong", encoded)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestMultiHasher(t *testing.T) {
+	preferred := NewArgon2idHasher(Argon2idParams{Memory: 1024, Iterations: 2, Parallelism: 1})
+	legacy := NewSHA1Hasher(legacySalt)
+	h := NewMultiHasher(preferred, NewBcryptHasher(4), legacy)
+
+	legacyHash, _ := legacy.Hash("qwerty")
+	bcryptHash, _ := NewBcryptHasher(4).Hash("qwerty")
+	weakHash, _ := NewArgon2idHasher(Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}).Hash("qwerty")
+	currentHash, _ := h.Hash("qwerty")
+
+	testTable := []struct {
+		name        string
+		encoded     string
+		needsRehash bool
+		wantErr     bool
+	}{
+		{
+			name:        "Legacy SHA-1",
+			encoded:     legacyHash,
+			needsRehash: true,
+		},
+		{
+			name:        "Other Algorithm",
+			encoded:     bcryptHash,
+			needsRehash: true,
+		},
+		{
+			name:        "Weaker Params",
+			encoded:     weakHash,
+			needsRehash: true,
+		},
+		{
+			name:    "Preferred",
+			encoded: currentHash,
+		},
+		{
+			name:        "Unknown",
+			encoded:     "$md5$qwerty",
+			needsRehash: true,
+			wantErr:     true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ok, err := h.Verify("qwerty", testCase.encoded)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			}
+
+			assert.Equal(t, testCase.needsRehash, h.NeedsRehash(testCase.encoded))
+		})
+	}
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	argon2id := Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+	bcryptHash, _ := NewBcryptHasher(4).Hash("qwerty")
+	argon2idHash, _ := NewArgon2idHasher(argon2id).Hash("qwerty")
+	legacyHash, _ := NewSHA1Hasher(legacySalt).Hash("qwerty")
+
+	for _, algorithm := range []string{"bcrypt", "argon2id"} {
+		t.Run(algorithm, func(t *testing.T) {
+			h, err := NewPasswordHasher(Config{
+				Algorithm:  algorithm,
+				BcryptCost: 4,
+				Argon2id:   argon2id,
+				LegacySalt: legacySalt,
+			})
+			assert.NoError(t, err)
+
+			for _, encoded := range []string{bcryptHash, argon2idHash, legacyHash} {
+				ok, err := h.Verify("qwerty", encoded)
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			}
+		})
+	}
+
+	_, err := NewPasswordHasher(Config{Algorithm: "md5"})
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+}
@@ -0,0 +1,41 @@
+package hash
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// SHA1Hasher reproduces the original salted SHA-1 scheme. It is only kept to
+// verify hashes of users who have not logged in since the migration and must
+// never be used as the preferred algorithm.
+type SHA1Hasher struct {
+	salt string
+}
+
+func NewSHA1Hasher(salt string) *SHA1Hasher {
+	return &SHA1Hasher{salt: salt}
+}
+
+func (h *SHA1Hasher) Hash(password string) (string, error) {
+	hash := sha1.New()
+	hash.Write([]byte(password))
+
+	return fmt.Sprintf("%x", hash.Sum([]byte(h.salt))), nil
+}
+
+func (h *SHA1Hasher) Verify(password, encoded string) (bool, error) {
+	hash, _ := h.Hash(password)
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(encoded)) == 1, nil
+}
+
+func (h *SHA1Hasher) NeedsRehash(encoded string) bool {
+	return true
+}
+
+func (h *SHA1Hasher) Supports(encoded string) bool {
+	prefix := hex.EncodeToString([]byte(h.salt))
+	return strings.HasPrefix(encoded, prefix) && len(encoded) == len(prefix)+2*sha1.Size
+}
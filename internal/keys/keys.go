@@ -0,0 +1,134 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Key is a single JWT key identified by its kid. Keys created from a public
+// key only can verify tokens but can never be used for signing.
+type Key struct {
+	Id        string
+	Algorithm string
+
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("key %s: empty secret", id)
+	}
+
+	return &Key{
+		Id:        id,
+		Algorithm: AlgorithmHS256,
+		method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}, nil
+}
+
+func NewRSAKey(id string, privatePEM []byte) (*Key, error) {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", id, err)
+	}
+
+	return &Key{
+		Id:        id,
+		Algorithm: AlgorithmRS256,
+		method:    jwt.SigningMethodRS256,
+		signKey:   privateKey,
+		verifyKey: &privateKey.PublicKey,
+	}, nil
+}
+
+func NewRSAPublicKey(id string, publicPEM []byte) (*Key, error) {
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", id, err)
+	}
+
+	return &Key{
+		Id:        id,
+		Algorithm: AlgorithmRS256,
+		method:    jwt.SigningMethodRS256,
+		verifyKey: publicKey,
+	}, nil
+}
+
+func NewEd25519Key(id string, privatePEM []byte) (*Key, error) {
+	block, _ := pem.Decode(privatePEM)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: invalid PEM", id)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", id, err)
+	}
+
+	privateKey, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key %s: not an Ed25519 private key", id)
+	}
+
+	return &Key{
+		Id:        id,
+		Algorithm: AlgorithmEdDSA,
+		method:    SigningMethodEdDSA,
+		signKey:   privateKey,
+		verifyKey: privateKey.Public(),
+	}, nil
+}
+
+func NewEd25519PublicKey(id string, publicPEM []byte) (*Key, error) {
+	block, _ := pem.Decode(publicPEM)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: invalid PEM", id)
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", id, err)
+	}
+
+	publicKey, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("key %s: not an Ed25519 public key", id)
+	}
+
+	return &Key{
+		Id:        id,
+		Algorithm: AlgorithmEdDSA,
+		method:    SigningMethodEdDSA,
+		verifyKey: publicKey,
+	}, nil
+}
+
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+func (k *Key) sign(claims jwt.Claims) (string, error) {
+	if !k.CanSign() {
+		return "", errors.New("key can only verify tokens")
+	}
+
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.Id
+
+	return token.SignedString(k.signKey)
+}
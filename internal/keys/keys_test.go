@@ -0,0 +1,147 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newRSAKey(t *testing.T, id string) *Key {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := NewRSAKey(id, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func newEd25519Key(t *testing.T, id string) *Key {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := NewEd25519Key(id, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func newClaims() *jwt.StandardClaims {
+	return &jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Minute).Unix()}
+}
+
+func TestKeySet_SignAndVerify(t *testing.T) {
+	hmacKey, _ := NewHMACKey("hs", []byte("secret"))
+
+	testTable := []struct {
+		name string
+		key  *Key
+	}{
+		{
+			name: "HS256",
+			key:  hmacKey,
+		},
+		{
+			name: "RS256",
+			key:  newRSAKey(t, "rs"),
+		},
+		{
+			name: "EdDSA",
+			key:  newEd25519Key(t, "ed"),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			set, err := NewKeySet(testCase.key)
+			assert.NoError(t, err)
+
+			signed, err := set.Sign(newClaims())
+			assert.NoError(t, err)
+
+			token, err := jwt.ParseWithClaims(signed, &jwt.StandardClaims{}, set.Keyfunc)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.key.Id, token.Header["kid"])
+			assert.Equal(t, testCase.name, token.Method.Alg())
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey, _ := NewHMACKey("old", []byte("old secret"))
+	newKey := newEd25519Key(t, "new")
+
+	oldSet, _ := NewKeySet(oldKey)
+	oldToken, err := oldSet.Sign(newClaims())
+	assert.NoError(t, err)
+
+	rotatedSet, err := NewKeySet(oldKey, newKey)
+	assert.NoError(t, err)
+
+	newToken, err := rotatedSet.Sign(newClaims())
+	assert.NoError(t, err)
+
+	parsed, err := jwt.Parse(newToken, rotatedSet.Keyfunc)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", parsed.Header["kid"])
+
+	_, err = jwt.Parse(oldToken, rotatedSet.Keyfunc)
+	assert.NoError(t, err)
+
+	retiredSet, _ := NewKeySet(newKey)
+	_, err = jwt.Parse(oldToken, retiredSet.Keyfunc)
+	assert.Error(t, err)
+}
+
+func TestKeySet_RejectsAlgorithmMismatch(t *testing.T) {
+	rsaKey := newRSAKey(t, "rs")
+	set, _ := NewKeySet(rsaKey)
+
+	// a token signed with HS256 under the kid of an RSA key must not be
+	// verified with the public key used as an HMAC secret
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims())
+	forged.Header["kid"] = "rs"
+	signed, err := forged.SignedString([]byte("whatever"))
+	assert.NoError(t, err)
+
+	_, err = jwt.Parse(signed, set.Keyfunc)
+	assert.Error(t, err)
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	hmacKey, _ := NewHMACKey("hs", []byte("secret"))
+	set, err := NewKeySet(hmacKey, newRSAKey(t, "rs"), newEd25519Key(t, "ed"))
+	assert.NoError(t, err)
+
+	jwks := set.JWKS()
+	if assert.Len(t, jwks.Keys, 2) {
+		assert.Equal(t, "rs", jwks.Keys[0].Kid)
+		assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+		assert.Equal(t, "AQAB", jwks.Keys[0].E)
+		assert.Equal(t, "ed", jwks.Keys[1].Kid)
+		assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+		assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+	}
+}
@@ -0,0 +1,68 @@
+package keys
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	ErrMissingKeyId = errors.New("token has no kid header")
+	ErrUnknownKey   = errors.New("token is signed with an unknown key")
+)
+
+// KeySet verifies tokens with any of its keys and signs new ones with the
+// newest key, which is the last one able to sign. Rotation is done by
+// appending a new key and dropping the old one once the tokens it signed
+// have expired.
+type KeySet struct {
+	keys    map[string]*Key
+	ordered []*Key
+	signer  *Key
+}
+
+func NewKeySet(keys ...*Key) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key, len(keys))}
+
+	for _, key := range keys {
+		if _, ok := set.keys[key.Id]; ok {
+			return nil, fmt.Errorf("duplicate key id %s", key.Id)
+		}
+
+		set.keys[key.Id] = key
+		set.ordered = append(set.ordered, key)
+		if key.CanSign() {
+			set.signer = key
+		}
+	}
+
+	if set.signer == nil {
+		return nil, errors.New("key set has no signing key")
+	}
+
+	return set, nil
+}
+
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	return s.signer.sign(claims)
+}
+
+// Keyfunc resolves the verification key of a token by its kid header and
+// makes sure the token algorithm matches the one of the key.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok || kid == "" {
+		return nil, ErrMissingKeyId
+	}
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("invalid signing method")
+	}
+
+	return key.verifyKey, nil
+}
@@ -0,0 +1,65 @@
+package keys
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Config describes where a key comes from. HMAC secrets are read from an env
+// variable, asymmetric keys from PEM files. Setting only PublicKeyFile keeps a
+// retired key around to verify tokens it has already signed.
+type Config struct {
+	Id             string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"algorithm"`
+	SecretEnv      string `mapstructure:"secret_env"`
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+}
+
+func Load(configs []Config) (*KeySet, error) {
+	keys := make([]*Key, 0, len(configs))
+
+	for _, cfg := range configs {
+		key, err := loadKey(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(keys...)
+}
+
+func loadKey(cfg Config) (*Key, error) {
+	if cfg.Id == "" {
+		return nil, fmt.Errorf("key of algorithm %s has no kid", cfg.Algorithm)
+	}
+
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		return NewHMACKey(cfg.Id, []byte(os.Getenv(cfg.SecretEnv)))
+	case AlgorithmRS256:
+		if cfg.PrivateKeyFile == "" {
+			return loadPEM(cfg.Id, cfg.PublicKeyFile, NewRSAPublicKey)
+		}
+		return loadPEM(cfg.Id, cfg.PrivateKeyFile, NewRSAKey)
+	case AlgorithmEdDSA:
+		if cfg.PrivateKeyFile == "" {
+			return loadPEM(cfg.Id, cfg.PublicKeyFile, NewEd25519PublicKey)
+		}
+		return loadPEM(cfg.Id, cfg.PrivateKeyFile, NewEd25519Key)
+	default:
+		return nil, fmt.Errorf("key %s: unsupported algorithm %s", cfg.Id, cfg.Algorithm)
+	}
+}
+
+func loadPEM(id, path string, parse func(id string, data []byte) (*Key, error)) (*Key, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", id, err)
+	}
+
+	return parse(id, data)
+}
@@ -0,0 +1,150 @@
+// Package netguard keeps requests to URLs chosen by users away from the
+// networks of the server: loopback, private and link local addresses, the
+// latter including cloud metadata endpoints such as 169.254.169.254.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenAddress = errors.New("address is not public")
+
+// reserved are the ranges not covered by the net.IP predicates that must not
+// be reached either.
+var reserved = mustParseCIDRs(
+	"0.0.0.0/8",       // this network
+	"100.64.0.0/10",   // carrier grade NAT, also used by cloud metadata services
+	"192.0.0.0/24",    // protocol assignments
+	"192.0.2.0/24",    // documentation
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // documentation
+	"203.0.113.0/24",  // documentation
+	"240.0.0.0/4",     // reserved, including broadcast
+	"64:ff9b::/96",    // NAT64, reaches IPv4 addresses
+	"64:ff9b:1::/48",  // local NAT64
+	"2001:db8::/32",   // documentation
+	"2002::/16",       // 6to4, reaches IPv4 addresses
+	"2001::/32",       // Teredo
+	"100::/64",        // discard
+	"fec0::/10",       // deprecated site local
+)
+
+// Guard checks the addresses requests are sent to. AllowPrivate turns the
+// checks off, for development against receivers on the local network only.
+type Guard struct {
+	AllowPrivate bool
+}
+
+// Allowed reports whether ip may be connected to.
+func (g Guard) Allowed(ip net.IP) bool {
+	if g.AllowPrivate {
+		return true
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, network := range reserved {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckURL resolves the host of an http or https URL and fails when one of
+// its addresses is not allowed. The addresses may change later, requests
+// have to be sent with a client of the guard as well.
+func (g Guard) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if g.AllowPrivate {
+		return nil
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		return g.check(ip)
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if err := g.check(addr.IP); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Client returns an HTTP client that only connects to allowed addresses. The
+// address is checked once resolved, right before connecting, so a host
+// resolving to another address than it did when checked is still refused.
+// Redirects are not followed and proxies are not used.
+func (g Guard) Client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: g.control}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (g Guard) control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+
+	return g.check(ip)
+}
+
+func (g Guard) check(ip net.IP) error {
+	if !g.Allowed(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+	}
+
+	return nil
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+
+	return networks
+}
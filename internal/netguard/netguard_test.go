@@ -0,0 +1,88 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGuard_Allowed(t *testing.T) {
+	testTable := []struct {
+		ip      string
+		allowed bool
+	}{
+		{ip: "93.184.216.34", allowed: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", allowed: true},
+		{ip: "127.0.0.1"},
+		{ip: "::1"},
+		{ip: "::ffff:127.0.0.1"},
+		{ip: "10.1.2.3"},
+		{ip: "172.16.0.1"},
+		{ip: "192.168.1.1"},
+		{ip: "169.254.169.254"},
+		{ip: "100.100.100.200"},
+		{ip: "0.0.0.0"},
+		{ip: "fd00:ec2::254"},
+		{ip: "fe80::1"},
+		{ip: "64:ff9b::a9fe:a9fe"},
+		{ip: "255.255.255.255"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.ip, func(t *testing.T) {
+			assert.Equal(t, testCase.allowed, Guard{}.Allowed(net.ParseIP(testCase.ip)))
+			assert.True(t, Guard{AllowPrivate: true}.Allowed(net.ParseIP(testCase.ip)))
+		})
+	}
+}
+
+func TestGuard_CheckURL(t *testing.T) {
+	testTable := []struct {
+		name    string
+		url     string
+		allowed bool
+	}{
+		{name: "Public", url: "https://93.184.216.34/hook", allowed: true},
+		{name: "Loopback", url: "http://127.0.0.1:8000/hook"},
+		{name: "Localhost", url: "http://localhost/hook"},
+		{name: "Metadata", url: "http://169.254.169.254/latest/meta-data/"},
+		{name: "IPv6 Loopback", url: "http://[::1]/hook"},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := Guard{}.CheckURL(context.Background(), testCase.url)
+
+			if testCase.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, ErrForbiddenAddress))
+			}
+		})
+	}
+}
+
+func TestGuard_Client(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/target", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// the test server listens on loopback
+	_, err := Guard{}.Client(time.Second).Get(server.URL)
+	assert.True(t, errors.Is(err, ErrForbiddenAddress))
+
+	resp, err := Guard{AllowPrivate: true}.Client(time.Second).Get(server.URL + "/redirect")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+}
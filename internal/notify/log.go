@@ -0,0 +1,24 @@
+package notify
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+)
+
+// LogNotifier writes messages to the application log, it is meant for
+// development and as a sink that never fails.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, msg Message) error {
+	logrus.WithFields(logrus.Fields{
+		"item_id": msg.ItemId,
+		"due_at":  msg.DueAt,
+		"subject": msg.Subject,
+	}).Info(msg.Body)
+
+	return nil
+}
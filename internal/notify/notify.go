@@ -0,0 +1,36 @@
+// Package notify delivers messages to users over a channel: email, a
+// webhook or the application log.
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrUnknownChannel = errors.New("notify: no notifier for channel")
+
+type Message struct {
+	// To is the address on the channel, e.g. an email address or a URL.
+	To      string
+	Subject string
+	Body    string
+	ItemId  int
+	DueAt   *time.Time
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Channels routes messages to the notifier of their channel.
+type Channels map[string]Notifier
+
+func (c Channels) Notify(ctx context.Context, channel string, msg Message) error {
+	notifier, ok := c[channel]
+	if !ok {
+		return ErrUnknownChannel
+	}
+
+	return notifier.Notify(ctx, msg)
+}
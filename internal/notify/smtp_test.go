@@ -0,0 +1,170 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpStandIn is a minimal SMTP server accepting a single message, it offers
+// neither STARTTLS nor authentication.
+type smtpStandIn struct {
+	listener net.Listener
+	from     string
+	to       []string
+	data     string
+	done     chan struct{}
+}
+
+func newSMTPStandIn(t *testing.T, rcptReply string) *smtpStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &smtpStandIn{listener: listener, done: make(chan struct{})}
+	go s.serve(rcptReply)
+	t.Cleanup(func() { listener.Close() })
+
+	return s
+}
+
+func (s *smtpStandIn) port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+func (s *smtpStandIn) serve(rcptReply string) {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.to = append(s.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply(rcptReply)
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPNotifier_Notify(t *testing.T) {
+	testTable := []struct {
+		name      string
+		rcptReply string
+		msg       Message
+		wantErr   bool
+	}{
+		{
+			name:      "OK",
+			rcptReply: "250 OK",
+			msg: Message{
+				To:      "alice@example.com",
+				Subject: "Reminder: Pay rent",
+				Body:    "Pay rent is due.",
+			},
+		},
+		{
+			name:      "Header Injection",
+			rcptReply: "250 OK",
+			msg: Message{
+				To:      "alice@example.com",
+				Subject: "Pay rent\r\nBcc: mallory@example.com",
+				Body:    "Pay rent is due.",
+			},
+		},
+		{
+			name:      "Recipient Not Allowed",
+			rcptReply: "250 OK",
+			msg: Message{
+				To:      "mallory@example.org",
+				Subject: "Reminder",
+			},
+			wantErr: true,
+		},
+		{
+			name:      "Rejected Recipient",
+			rcptReply: "550 No such user",
+			msg: Message{
+				To:      "nobody@example.com",
+				Subject: "Reminder",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := newSMTPStandIn(t, testCase.rcptReply)
+
+			n := NewSMTPNotifier(SMTPConfig{
+				Host:           "127.0.0.1",
+				Port:           server.port(),
+				From:           "todo@example.com",
+				Timeout:        5 * time.Second,
+				AllowedDomains: []string{"example.com"},
+			})
+
+			err := n.Notify(context.Background(), testCase.msg)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			<-server.done
+
+			assert.Equal(t, "todo@example.com", server.from)
+			assert.Equal(t, []string{testCase.msg.To}, server.to)
+
+			headers := strings.SplitN(server.data, "\r\n\r\n", 2)[0]
+			assert.Contains(t, headers, "To: "+testCase.msg.To+"\r\n")
+			assert.NotContains(t, headers, "\r\nBcc:")
+			assert.Contains(t, server.data, testCase.msg.Body)
+		})
+	}
+}
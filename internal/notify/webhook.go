@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/netguard"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier posts messages as JSON to the URL they are addressed to,
+// as long as the guard allows its address.
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier(timeout time.Duration, guard netguard.Guard) *WebhookNotifier {
+	return &WebhookNotifier{client: guard.Client(timeout)}
+}
+
+type webhookPayload struct {
+	Subject string     `json:"subject"`
+	Body    string     `json:"body"`
+	ItemId  int        `json:"item_id"`
+	DueAt   *time.Time `json:"due_at"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(webhookPayload{
+		Subject: msg.Subject,
+		Body:    msg.Body,
+		ItemId:  msg.ItemId,
+		DueAt:   msg.DueAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.To, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notify: webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
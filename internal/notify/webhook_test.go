@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/pavel-trbv/go-todo-app/internal/netguard"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	dueAt := time.Date(2021, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{name: "OK", statusCode: http.StatusNoContent},
+		{name: "Server Error", statusCode: http.StatusInternalServerError, wantErr: true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var got webhookPayload
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.WriteHeader(testCase.statusCode)
+			}))
+			defer server.Close()
+
+			n := NewWebhookNotifier(time.Second, netguard.Guard{AllowPrivate: true})
+			err := n.Notify(context.Background(), Message{
+				To:      server.URL,
+				Subject: "Reminder: Pay rent",
+				Body:    "Pay rent is due.",
+				ItemId:  7,
+				DueAt:   &dueAt,
+			})
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, "Reminder: Pay rent", got.Subject)
+			assert.Equal(t, 7, got.ItemId)
+			assert.True(t, dueAt.Equal(*got.DueAt))
+		})
+	}
+}
+
+func TestWebhookNotifier_PrivateAddress(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(time.Second, netguard.Guard{})
+	err := n.Notify(context.Background(), Message{To: server.URL, Subject: "Reminder: Pay rent"})
+
+	assert.True(t, errors.Is(err, netguard.ErrForbiddenAddress))
+	assert.Zero(t, requests)
+}
+
+func TestChannels_Notify(t *testing.T) {
+	channels := Channels{"log": NewLogNotifier()}
+
+	assert.NoError(t, channels.Notify(context.Background(), "log", Message{Subject: "Reminder"}))
+	assert.ErrorIs(t, channels.Notify(context.Background(), "email", Message{}), ErrUnknownChannel)
+}
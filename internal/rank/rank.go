@@ -0,0 +1,137 @@
+// Package rank generates lexicographic sort keys. A key can always be
+// generated between any two distinct keys, so moving a row only ever rewrites
+// the rank of that row.
+//
+// Keys consist of the digits 0-9 and a-z and never end with a 0, otherwise
+// there would be no key right before them. They have to be compared byte by
+// byte, in Postgres the column needs the "C" collation.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+const digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+var ErrInvalidRange = errors.New("rank: lower bound is not below upper bound")
+
+var ErrInvalidKey = errors.New("rank: invalid key")
+
+// Initial is the key of the first row of an empty collection.
+const Initial = "i"
+
+// Between returns a key strictly between a and b. An empty a stands for the
+// start of the collection, an empty b for its end.
+func Between(a, b string) (string, error) {
+	if !Valid(a) || !Valid(b) {
+		return "", ErrInvalidKey
+	}
+
+	if b != "" && a >= b {
+		return "", ErrInvalidRange
+	}
+
+	return midpoint(a, b), nil
+}
+
+// After returns a key following a, keeping it as short as possible so that
+// appending rows one after another grows the keys slowly.
+func After(a string) (string, error) {
+	if !Valid(a) {
+		return "", ErrInvalidKey
+	}
+
+	for i := 0; i < len(a); i++ {
+		if d := strings.IndexByte(digits, a[i]); d < base-1 {
+			return a[:i] + string(digits[d+1]), nil
+		}
+	}
+
+	return a + Initial, nil
+}
+
+// Sequence returns n evenly spaced increasing keys of the same length,
+// used to renumber a whole collection at once.
+func Sequence(n int) []string {
+	width, space := 1, base
+	for space <= n {
+		width++
+		space *= base
+	}
+
+	keys := make([]string, n)
+	for i := range keys {
+		value := (i + 1) * space / (n + 1)
+
+		key := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			key[j] = digits[value%base]
+			value /= base
+		}
+		keys[i] = strings.TrimRight(string(key), "0")
+	}
+
+	return keys
+}
+
+// Valid reports whether the key only has known digits and no trailing 0.
+// The empty string is valid and means an open bound.
+func Valid(key string) bool {
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return false
+		}
+	}
+
+	return !strings.HasSuffix(key, "0")
+}
+
+// midpoint expects a < b, with an empty b meaning no upper bound.
+func midpoint(a, b string) string {
+	if b != "" {
+		// skip the common prefix, a is padded with zeros
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(suffix(a, n), b[n:])
+		}
+	}
+
+	da := strings.IndexByte(digits, digitAt(a, 0))
+	db := base
+	if b != "" {
+		db = strings.IndexByte(digits, b[0])
+	}
+
+	if db-da > 1 {
+		return string(digits[(da+db)/2])
+	}
+
+	// the first digits are consecutive
+	if len(b) > 1 {
+		return b[:1]
+	}
+
+	return string(digits[da]) + midpoint(suffix(a, 1), "")
+}
+
+func digitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+
+	return digits[0]
+}
+
+func suffix(key string, n int) string {
+	if n < len(key) {
+		return key[n:]
+	}
+
+	return ""
+}
@@ -0,0 +1,157 @@
+package rank
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	testTable := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+		wantErr  error
+	}{
+		{
+			name:     "Empty Collection",
+			expected: Initial,
+		},
+		{
+			name:     "Before",
+			b:        "i",
+			expected: "9",
+		},
+		{
+			name:     "After",
+			a:        "i",
+			expected: "r",
+		},
+		{
+			name:     "Wide Gap",
+			a:        "a",
+			b:        "c",
+			expected: "b",
+		},
+		{
+			name:     "Consecutive Digits",
+			a:        "a",
+			b:        "b",
+			expected: "ai",
+		},
+		{
+			name:     "Common Prefix",
+			a:        "ab",
+			b:        "ad",
+			expected: "ac",
+		},
+		{
+			name:     "Shorter Upper Bound",
+			a:        "a5",
+			b:        "b",
+			expected: "ak",
+		},
+		{
+			name:     "Longer Upper Bound",
+			a:        "a",
+			b:        "b5",
+			expected: "b",
+		},
+		{
+			name:     "Zero Padded Upper Bound",
+			b:        "001",
+			expected: "000i",
+		},
+		{
+			name:    "Equal Keys",
+			a:       "a",
+			b:       "a",
+			wantErr: ErrInvalidRange,
+		},
+		{
+			name:    "Trailing Zero",
+			a:       "a0",
+			wantErr: ErrInvalidKey,
+		},
+		{
+			name:    "Unknown Digit",
+			a:       "A",
+			wantErr: ErrInvalidKey,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := Between(testCase.a, testCase.b)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, got)
+			assert.True(t, Valid(got))
+		})
+	}
+}
+
+func TestBetween_RandomInserts(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	keys := []string{}
+
+	for i := 0; i < 2000; i++ {
+		pos := r.Intn(len(keys) + 1)
+
+		var a, b string
+		if pos > 0 {
+			a = keys[pos-1]
+		}
+		if pos < len(keys) {
+			b = keys[pos]
+		}
+
+		key, err := Between(a, b)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, Valid(key))
+
+		keys = append(keys[:pos], append([]string{key}, keys[pos:]...)...)
+	}
+
+	assert.True(t, sort.StringsAreSorted(keys))
+	for i := 1; i < len(keys); i++ {
+		assert.NotEqual(t, keys[i-1], keys[i])
+	}
+}
+
+func TestAfter(t *testing.T) {
+	key := Initial
+	for i := 0; i < 1000; i++ {
+		next, err := After(key)
+		assert.NoError(t, err)
+		assert.True(t, next > key, "%s > %s", next, key)
+		assert.True(t, Valid(next))
+		key = next
+	}
+
+	// appending grows keys by one digit every few dozen rows only
+	assert.Less(t, len(key), 64)
+}
+
+func TestSequence(t *testing.T) {
+	for _, n := range []int{0, 1, 35, 36, 1000} {
+		keys := Sequence(n)
+		assert.Len(t, keys, n)
+		assert.True(t, sort.StringsAreSorted(keys))
+
+		for i, key := range keys {
+			assert.True(t, Valid(key), key)
+			if i > 0 {
+				assert.NotEqual(t, keys[i-1], key)
+			}
+		}
+	}
+}
@@ -0,0 +1,403 @@
+// Package recurrence parses and expands a subset of RFC 5545 recurrence
+// rules, e.g. "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH".
+//
+// Supported parts are FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, BYDAY
+// without ordinals (DAILY and WEEKLY only), BYMONTHDAY (MONTHLY only,
+// negative days count from the end of the month), BYHOUR, BYMINUTE, COUNT
+// and UNTIL. The non standard X-FROM=COMPLETION makes occurrences follow the
+// completion of the previous one instead of its due date.
+//
+// Occurrences are computed on the wall clock of a location, so a task due at
+// 9:00 stays at 9:00 across daylight saving time changes.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+const untilLayout = "20060102T150405Z"
+
+// maxIterations bounds the search for the next occurrence, rules like
+// BYMONTHDAY=31 with INTERVAL=2 starting in an even month never match.
+const maxIterations = 1000
+
+var ErrNoOccurrence = errors.New("recurrence: rule has no further occurrence")
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	// ByHour and ByMinute are -1 when the time of day follows the previous
+	// occurrence, a missing ByMinute with ByHour set means the full hour.
+	ByHour         int
+	ByMinute       int
+	Count          int
+	Until          time.Time
+	FromCompletion bool
+}
+
+// Parse reads a rule, the "RRULE:" prefix is optional.
+func Parse(s string) (Rule, error) {
+	rule := Rule{Interval: 1, ByHour: -1, ByMinute: -1}
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return rule, errors.New("recurrence: empty rule")
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return rule, fmt.Errorf("recurrence: invalid part %q", part)
+		}
+
+		name, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		if seen[name] {
+			return rule, fmt.Errorf("recurrence: duplicate %s", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(value)
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly && rule.Freq != Yearly {
+				err = fmt.Errorf("recurrence: unsupported FREQ %s", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(name, value)
+		case "COUNT":
+			rule.Count, err = parsePositive(name, value)
+		case "BYDAY":
+			rule.ByDay, err = parseWeekdays(value)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseMonthDays(value)
+		case "BYHOUR":
+			rule.ByHour, err = parseRange(name, value, 0, 23)
+		case "BYMINUTE":
+			rule.ByMinute, err = parseRange(name, value, 0, 59)
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+		case "X-FROM":
+			if value != "COMPLETION" {
+				err = fmt.Errorf("recurrence: unsupported X-FROM %s", value)
+			}
+			rule.FromCompletion = true
+		default:
+			err = fmt.Errorf("recurrence: unsupported part %s", name)
+		}
+		if err != nil {
+			return rule, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return rule, errors.New("recurrence: FREQ is required")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != Daily && rule.Freq != Weekly {
+		return rule, errors.New("recurrence: BYDAY is only supported with DAILY and WEEKLY")
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq != Monthly {
+		return rule, errors.New("recurrence: BYMONTHDAY is only supported with MONTHLY")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return rule, errors.New("recurrence: COUNT and UNTIL are mutually exclusive")
+	}
+	if rule.ByMinute >= 0 && rule.ByHour < 0 {
+		return rule, errors.New("recurrence: BYMINUTE requires BYHOUR")
+	}
+
+	return rule, nil
+}
+
+// String formats the rule in a canonical form accepted by Parse.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = weekdayNames[day]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.ByHour >= 0 {
+		parts = append(parts, "BYHOUR="+strconv.Itoa(r.ByHour))
+	}
+	if r.ByMinute >= 0 {
+		parts = append(parts, "BYMINUTE="+strconv.Itoa(r.ByMinute))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayout))
+	}
+	if r.FromCompletion {
+		parts = append(parts, "X-FROM=COMPLETION")
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the occurrence following one that was due at due and
+// completed at completed, together with the rule the new occurrence carries:
+// its COUNT is decreased and its time of day pinned, so that it does not
+// drift after an occurrence fell into a daylight saving time gap.
+// ErrNoOccurrence is returned once COUNT or UNTIL are exhausted.
+func (r Rule) Next(due *time.Time, completed time.Time, loc *time.Location) (time.Time, Rule, error) {
+	if r.Count == 1 {
+		return time.Time{}, r, ErrNoOccurrence
+	}
+
+	anchor := completed.In(loc)
+	if due != nil {
+		d := due.In(loc)
+		if r.FromCompletion {
+			anchor = time.Date(anchor.Year(), anchor.Month(), anchor.Day(),
+				d.Hour(), d.Minute(), d.Second(), 0, loc)
+		} else {
+			anchor = d
+		}
+	}
+
+	next := r
+	if r.ByHour < 0 {
+		next.ByHour, next.ByMinute = anchor.Hour(), anchor.Minute()
+	}
+
+	occurrence, err := next.after(anchor)
+	if err != nil {
+		return time.Time{}, r, err
+	}
+
+	if !r.Until.IsZero() && occurrence.After(r.Until) {
+		return time.Time{}, r, ErrNoOccurrence
+	}
+
+	if next.Count > 0 {
+		next.Count--
+	}
+
+	return occurrence, next, nil
+}
+
+// after finds the first occurrence after the anchor, the rule must have its
+// time of day pinned.
+func (r Rule) after(anchor time.Time) (time.Time, error) {
+	loc := anchor.Location()
+	year, month, day := anchor.Date()
+
+	at := func(y int, m time.Month, d int) time.Time {
+		minute := r.ByMinute
+		if minute < 0 {
+			minute = 0
+		}
+		return time.Date(y, m, d, r.ByHour, minute, 0, 0, loc)
+	}
+
+	switch r.Freq {
+	case Daily, Weekly:
+		step := r.Interval
+		if r.Freq == Weekly && len(r.ByDay) == 0 {
+			step = 7 * r.Interval
+		}
+
+		for i := 1; i <= maxIterations; i++ {
+			offset := i
+			if len(r.ByDay) == 0 {
+				offset = i * step
+			}
+
+			candidate := at(year, month, day+offset)
+			if len(r.ByDay) > 0 && !r.matches(anchor, candidate) {
+				continue
+			}
+			if candidate.After(anchor) {
+				return candidate, nil
+			}
+		}
+	case Monthly:
+		days := r.ByMonthDay
+		if len(days) == 0 {
+			days = []int{day}
+		}
+
+		for i := 0; i <= maxIterations; i += r.Interval {
+			first := time.Date(year, month+time.Month(i), 1, 0, 0, 0, 0, loc)
+			for _, d := range resolveMonthDays(first.Year(), first.Month(), days) {
+				if candidate := at(first.Year(), first.Month(), d); candidate.After(anchor) {
+					return candidate, nil
+				}
+			}
+		}
+	case Yearly:
+		for i := r.Interval; i <= maxIterations; i += r.Interval {
+			// February 29 only occurs in leap years
+			if daysIn(year+i, month) < day {
+				continue
+			}
+			return at(year+i, month, day), nil
+		}
+	}
+
+	return time.Time{}, ErrNoOccurrence
+}
+
+// matches tells whether a day is one of BYDAY in a week that is a multiple
+// of INTERVAL weeks away from the anchor. Weeks start on Monday.
+func (r Rule) matches(anchor, candidate time.Time) bool {
+	found := false
+	for _, day := range r.ByDay {
+		if candidate.Weekday() == day {
+			found = true
+		}
+	}
+	if !found {
+		return false
+	}
+
+	if r.Freq == Daily {
+		days := daysBetween(anchor, candidate)
+		return days%r.Interval == 0
+	}
+
+	weeks := daysBetween(weekStart(anchor), weekStart(candidate)) / 7
+	return weeks%r.Interval == 0
+}
+
+func resolveMonthDays(year int, month time.Month, days []int) []int {
+	last := daysIn(year, month)
+
+	resolved := make([]int, 0, len(days))
+	for _, d := range days {
+		if d < 0 {
+			d = last + d + 1
+		}
+		// months without the day are skipped, like RFC 5545 does
+		if d >= 1 && d <= last {
+			resolved = append(resolved, d)
+		}
+	}
+	sort.Ints(resolved)
+
+	return resolved
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// daysBetween counts calendar days, ignoring the time of day and daylight
+// saving time.
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	da := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	db := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+
+	return int(db.Sub(da).Hours() / 24)
+}
+
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.Date()
+
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+}
+
+func parsePositive(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("recurrence: %s must be a positive number", name)
+	}
+
+	return n, nil
+}
+
+func parseRange(name, value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("recurrence: %s must be between %d and %d", name, min, max)
+	}
+
+	return n, nil
+}
+
+func parseWeekdays(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, name := range strings.Split(value, ",") {
+		day, ok := weekdays[name]
+		if !ok {
+			return nil, fmt.Errorf("recurrence: unsupported BYDAY %s", name)
+		}
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+func parseMonthDays(value string) ([]int, error) {
+	var days []int
+	for _, s := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(s)
+		if err != nil || day == 0 || day < -31 || day > 31 {
+			return nil, fmt.Errorf("recurrence: invalid BYMONTHDAY %s", s)
+		}
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse(untilLayout, value); err == nil {
+		return t, nil
+	}
+
+	// a date alone includes the whole day
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("recurrence: invalid UNTIL %s", value)
+	}
+
+	return t.Add(24*time.Hour - time.Second), nil
+}
@@ -0,0 +1,420 @@
+package recurrence
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return loc
+}
+
+func at(loc *time.Location, year int, month time.Month, day, hour, minute int) *time.Time {
+	t := time.Date(year, month, day, hour, minute, 0, 0, loc)
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	testTable := []struct {
+		name      string
+		rule      string
+		canonical string
+		wantErr   bool
+	}{
+		{
+			name:      "Daily",
+			rule:      "FREQ=DAILY",
+			canonical: "FREQ=DAILY",
+		},
+		{
+			name:      "Prefix And Lower Case",
+			rule:      "RRULE:freq=weekly;byday=mo,th",
+			canonical: "FREQ=WEEKLY;BYDAY=MO,TH",
+		},
+		{
+			name:      "Interval One Is Dropped",
+			rule:      "FREQ=MONTHLY;INTERVAL=1;BYMONTHDAY=-1",
+			canonical: "FREQ=MONTHLY;BYMONTHDAY=-1",
+		},
+		{
+			name:      "All Parts",
+			rule:      "X-FROM=COMPLETION;COUNT=5;BYMINUTE=30;BYHOUR=9;BYDAY=MO,TU,WE,TH,FR;INTERVAL=2;FREQ=DAILY",
+			canonical: "FREQ=DAILY;INTERVAL=2;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9;BYMINUTE=30;COUNT=5;X-FROM=COMPLETION",
+		},
+		{
+			name:      "Until Date",
+			rule:      "FREQ=YEARLY;UNTIL=20301231",
+			canonical: "FREQ=YEARLY;UNTIL=20301231T235959Z",
+		},
+		{
+			name:      "Until Time",
+			rule:      "FREQ=WEEKLY;UNTIL=20300101T120000Z",
+			canonical: "FREQ=WEEKLY;UNTIL=20300101T120000Z",
+		},
+		{name: "Empty", rule: "", wantErr: true},
+		{name: "Missing Freq", rule: "INTERVAL=2", wantErr: true},
+		{name: "Unsupported Freq", rule: "FREQ=HOURLY", wantErr: true},
+		{name: "Unsupported Part", rule: "FREQ=DAILY;BYSETPOS=1", wantErr: true},
+		{name: "Malformed Part", rule: "FREQ=DAILY;INTERVAL", wantErr: true},
+		{name: "Duplicate Part", rule: "FREQ=DAILY;FREQ=WEEKLY", wantErr: true},
+		{name: "Zero Interval", rule: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{name: "Ordinal Weekday", rule: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{name: "Weekday With Monthly", rule: "FREQ=MONTHLY;BYDAY=MO", wantErr: true},
+		{name: "Month Day With Weekly", rule: "FREQ=WEEKLY;BYMONTHDAY=1", wantErr: true},
+		{name: "Invalid Month Day", rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{name: "Zero Month Day", rule: "FREQ=MONTHLY;BYMONTHDAY=0", wantErr: true},
+		{name: "Invalid Hour", rule: "FREQ=DAILY;BYHOUR=24", wantErr: true},
+		{name: "Minute Without Hour", rule: "FREQ=DAILY;BYMINUTE=30", wantErr: true},
+		{name: "Count And Until", rule: "FREQ=DAILY;COUNT=2;UNTIL=20300101", wantErr: true},
+		{name: "Invalid Until", rule: "FREQ=DAILY;UNTIL=tomorrow", wantErr: true},
+		{name: "Invalid From", rule: "FREQ=DAILY;X-FROM=DUE", wantErr: true},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			rule, err := Parse(testCase.rule)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.canonical, rule.String())
+
+			again, err := Parse(rule.String())
+			assert.NoError(t, err)
+			assert.Equal(t, rule, again)
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	newYork := mustLoad(t, "America/New_York")
+	utc := time.UTC
+
+	testTable := []struct {
+		name      string
+		rule      string
+		due       *time.Time
+		completed time.Time
+		loc       *time.Location
+		expected  *time.Time
+		nextRule  string
+		wantErr   error
+	}{
+		{
+			name:      "Daily",
+			rule:      "FREQ=DAILY",
+			due:       at(utc, 2021, 6, 1, 9, 0),
+			completed: *at(utc, 2021, 6, 1, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 6, 2, 9, 0),
+			nextRule:  "FREQ=DAILY;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Every Three Days",
+			rule:      "FREQ=DAILY;INTERVAL=3",
+			due:       at(utc, 2021, 6, 30, 9, 0),
+			completed: *at(utc, 2021, 6, 30, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 7, 3, 9, 0),
+			nextRule:  "FREQ=DAILY;INTERVAL=3;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Weekdays Skip Weekend",
+			rule:      "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			due:       at(utc, 2021, 6, 4, 9, 0),
+			completed: *at(utc, 2021, 6, 4, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 6, 7, 9, 0),
+			nextRule:  "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Weekly",
+			rule:      "FREQ=WEEKLY",
+			due:       at(utc, 2021, 12, 28, 9, 0),
+			completed: *at(utc, 2021, 12, 28, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2022, 1, 4, 9, 0),
+			nextRule:  "FREQ=WEEKLY;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Weekly On Days Within Week",
+			rule:      "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			due:       at(utc, 2021, 6, 7, 9, 0),
+			completed: *at(utc, 2021, 6, 7, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 6, 10, 9, 0),
+			nextRule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Weekly On Days Skips Interval",
+			rule:      "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			due:       at(utc, 2021, 6, 10, 9, 0),
+			completed: *at(utc, 2021, 6, 10, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 6, 21, 9, 0),
+			nextRule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Monthly Same Day",
+			rule:      "FREQ=MONTHLY",
+			due:       at(utc, 2021, 1, 15, 9, 0),
+			completed: *at(utc, 2021, 1, 15, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 2, 15, 9, 0),
+			nextRule:  "FREQ=MONTHLY;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Monthly Several Days",
+			rule:      "FREQ=MONTHLY;BYMONTHDAY=1,15",
+			due:       at(utc, 2021, 1, 15, 9, 0),
+			completed: *at(utc, 2021, 1, 15, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 2, 1, 9, 0),
+			nextRule:  "FREQ=MONTHLY;BYMONTHDAY=1,15;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Monthly Last Day",
+			rule:      "FREQ=MONTHLY;BYMONTHDAY=-1",
+			due:       at(utc, 2021, 1, 31, 9, 0),
+			completed: *at(utc, 2021, 1, 31, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 2, 28, 9, 0),
+			nextRule:  "FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Monthly Last Day Of Leap February",
+			rule:      "FREQ=MONTHLY;BYMONTHDAY=-1",
+			due:       at(utc, 2024, 1, 31, 9, 0),
+			completed: *at(utc, 2024, 1, 31, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2024, 2, 29, 9, 0),
+			nextRule:  "FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Monthly Skips Short Months",
+			rule:      "FREQ=MONTHLY;BYMONTHDAY=31",
+			due:       at(utc, 2021, 1, 31, 9, 0),
+			completed: *at(utc, 2021, 1, 31, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 3, 31, 9, 0),
+			nextRule:  "FREQ=MONTHLY;BYMONTHDAY=31;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Quarterly Across Year",
+			rule:      "FREQ=MONTHLY;INTERVAL=3",
+			due:       at(utc, 2021, 11, 5, 9, 0),
+			completed: *at(utc, 2021, 11, 5, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2022, 2, 5, 9, 0),
+			nextRule:  "FREQ=MONTHLY;INTERVAL=3;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Yearly Leap Day",
+			rule:      "FREQ=YEARLY",
+			due:       at(utc, 2020, 2, 29, 9, 0),
+			completed: *at(utc, 2020, 2, 29, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2024, 2, 29, 9, 0),
+			nextRule:  "FREQ=YEARLY;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Pinned Time Of Day",
+			rule:      "FREQ=DAILY;BYHOUR=7;BYMINUTE=15",
+			due:       at(utc, 2021, 6, 1, 9, 0),
+			completed: *at(utc, 2021, 6, 1, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 6, 2, 7, 15),
+			nextRule:  "FREQ=DAILY;BYHOUR=7;BYMINUTE=15",
+		},
+		{
+			name:      "From Completion Keeps Time Of Day",
+			rule:      "FREQ=DAILY;INTERVAL=3;X-FROM=COMPLETION",
+			due:       at(utc, 2021, 6, 1, 9, 0),
+			completed: *at(utc, 2021, 6, 5, 18, 30),
+			loc:       utc,
+			expected:  at(utc, 2021, 6, 8, 9, 0),
+			nextRule:  "FREQ=DAILY;INTERVAL=3;BYHOUR=9;BYMINUTE=0;X-FROM=COMPLETION",
+		},
+		{
+			name:      "From Due When Completed Late",
+			rule:      "FREQ=DAILY;INTERVAL=3",
+			due:       at(utc, 2021, 6, 1, 9, 0),
+			completed: *at(utc, 2021, 6, 5, 18, 30),
+			loc:       utc,
+			expected:  at(utc, 2021, 6, 4, 9, 0),
+			nextRule:  "FREQ=DAILY;INTERVAL=3;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Without Due Date",
+			rule:      "FREQ=WEEKLY",
+			completed: *at(utc, 2021, 6, 5, 18, 30),
+			loc:       utc,
+			expected:  at(utc, 2021, 6, 12, 18, 30),
+			nextRule:  "FREQ=WEEKLY;BYHOUR=18;BYMINUTE=30",
+		},
+		{
+			name:      "Count Decreases",
+			rule:      "FREQ=DAILY;COUNT=3",
+			due:       at(utc, 2021, 6, 1, 9, 0),
+			completed: *at(utc, 2021, 6, 1, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 6, 2, 9, 0),
+			nextRule:  "FREQ=DAILY;BYHOUR=9;BYMINUTE=0;COUNT=2",
+		},
+		{
+			name:      "Count Exhausted",
+			rule:      "FREQ=DAILY;COUNT=1",
+			due:       at(utc, 2021, 6, 1, 9, 0),
+			completed: *at(utc, 2021, 6, 1, 8, 0),
+			loc:       utc,
+			wantErr:   ErrNoOccurrence,
+		},
+		{
+			name:      "Until Includes Last Day",
+			rule:      "FREQ=DAILY;UNTIL=20210605",
+			due:       at(utc, 2021, 6, 4, 9, 0),
+			completed: *at(utc, 2021, 6, 4, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 6, 5, 9, 0),
+			nextRule:  "FREQ=DAILY;BYHOUR=9;BYMINUTE=0;UNTIL=20210605T235959Z",
+		},
+		{
+			name:      "Until Passed",
+			rule:      "FREQ=DAILY;UNTIL=20210605",
+			due:       at(utc, 2021, 6, 5, 9, 0),
+			completed: *at(utc, 2021, 6, 5, 8, 0),
+			loc:       utc,
+			wantErr:   ErrNoOccurrence,
+		},
+		{
+			name:      "Interval Skips Months Without Day",
+			rule:      "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=31",
+			due:       at(utc, 2021, 4, 30, 9, 0),
+			completed: *at(utc, 2021, 4, 30, 8, 0),
+			loc:       utc,
+			expected:  at(utc, 2021, 8, 31, 9, 0),
+			nextRule:  "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=31;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Local Time Zone",
+			rule:      "FREQ=DAILY",
+			due:       at(utc, 2021, 6, 1, 22, 30),
+			completed: *at(utc, 2021, 6, 1, 20, 0),
+			loc:       berlin,
+			// 00:30 on June 2nd in Berlin, the day is counted locally
+			expected: at(berlin, 2021, 6, 3, 0, 30),
+			nextRule: "FREQ=DAILY;BYHOUR=0;BYMINUTE=30",
+		},
+		{
+			name:      "Spring Forward Keeps Wall Clock",
+			rule:      "FREQ=WEEKLY",
+			due:       at(berlin, 2021, 3, 22, 9, 0),
+			completed: *at(berlin, 2021, 3, 22, 8, 0),
+			loc:       berlin,
+			expected:  at(utc, 2021, 3, 29, 7, 0),
+			nextRule:  "FREQ=WEEKLY;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Fall Back Keeps Wall Clock",
+			rule:      "FREQ=DAILY",
+			due:       at(berlin, 2021, 10, 30, 9, 0),
+			completed: *at(berlin, 2021, 10, 30, 8, 0),
+			loc:       berlin,
+			expected:  at(utc, 2021, 10, 31, 8, 0),
+			nextRule:  "FREQ=DAILY;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Monthly Across Daylight Saving Time",
+			rule:      "FREQ=MONTHLY;BYMONTHDAY=-1",
+			due:       at(newYork, 2021, 2, 28, 9, 0),
+			completed: *at(newYork, 2021, 2, 28, 8, 0),
+			loc:       newYork,
+			expected:  at(utc, 2021, 3, 31, 13, 0),
+			nextRule:  "FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Due In Utc Expanded In Zone",
+			rule:      "FREQ=DAILY",
+			due:       at(utc, 2021, 11, 6, 13, 0),
+			completed: *at(utc, 2021, 11, 6, 12, 0),
+			loc:       newYork,
+			// 9:00 EDT stays 9:00 EST
+			expected: at(utc, 2021, 11, 7, 14, 0),
+			nextRule: "FREQ=DAILY;BYHOUR=9;BYMINUTE=0",
+		},
+		{
+			name:      "Occurrence In Gap Is Shifted",
+			rule:      "FREQ=DAILY",
+			due:       at(berlin, 2021, 3, 27, 2, 30),
+			completed: *at(berlin, 2021, 3, 27, 2, 0),
+			loc:       berlin,
+			// 02:30 does not exist on March 28th
+			expected: at(utc, 2021, 3, 28, 1, 30),
+			nextRule: "FREQ=DAILY;BYHOUR=2;BYMINUTE=30",
+		},
+		{
+			name:      "Pinned Rule Recovers After Gap",
+			rule:      "FREQ=DAILY;BYHOUR=2;BYMINUTE=30",
+			due:       at(utc, 2021, 3, 28, 1, 30),
+			completed: *at(utc, 2021, 3, 28, 1, 0),
+			loc:       berlin,
+			expected:  at(berlin, 2021, 3, 29, 2, 30),
+			nextRule:  "FREQ=DAILY;BYHOUR=2;BYMINUTE=30",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			rule, err := Parse(testCase.rule)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			next, nextRule, err := rule.Next(testCase.due, testCase.completed, testCase.loc)
+
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, testCase.expected.Equal(next), "expected %s, got %s", testCase.expected, next)
+			assert.Equal(t, testCase.nextRule, nextRule.String())
+		})
+	}
+}
+
+func TestNextSeriesAcrossDaylightSavingTime(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=SA,SU")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	due := at(berlin, 2021, 3, 20, 10, 0)
+	for i := 0; i < 30; i++ {
+		next, nextRule, err := rule.Next(due, *due, berlin)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		local := next.In(berlin)
+		assert.Equal(t, 10, local.Hour())
+		assert.Equal(t, 0, local.Minute())
+		assert.Contains(t, []time.Weekday{time.Saturday, time.Sunday}, local.Weekday())
+		assert.True(t, next.After(*due))
+
+		rule, due = nextRule, &next
+	}
+}
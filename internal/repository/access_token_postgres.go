@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"time"
+)
+
+type AccessTokenPostgres struct {
+	db *sqlx.DB
+}
+
+func NewAccessTokenPostgres(db *sqlx.DB) *AccessTokenPostgres {
+	return &AccessTokenPostgres{db: db}
+}
+
+func (r *AccessTokenPostgres) Create(userId int, tokenHash string, input domain.CreateAccessTokenInput) (domain.AccessToken, error) {
+	var token domain.AccessToken
+
+	query := fmt.Sprintf(
+		`INSERT INTO %s (user_id, name, token_hash, scopes, expires_at) VALUES ($1, $2, $3, $4, $5)
+				RETURNING id, name, scopes, expires_at, last_used_at, created_at`,
+		accessTokensTable,
+	)
+	err := r.db.Get(&token, query, userId, input.Name, tokenHash, input.Scopes, input.ExpiresAt)
+
+	return token, err
+}
+
+func (r *AccessTokenPostgres) GetAll(userId int) ([]domain.AccessToken, error) {
+	var tokens []domain.AccessToken
+
+	query := fmt.Sprintf(
+		`SELECT id, name, scopes, expires_at, last_used_at, created_at FROM %s
+				WHERE user_id = $1 AND revoked_at IS NULL ORDER BY id`,
+		accessTokensTable,
+	)
+	err := r.db.Select(&tokens, query, userId)
+
+	return tokens, err
+}
+
+func (r *AccessTokenPostgres) Revoke(userId, tokenId int) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = now() WHERE user_id = $1 AND id = $2 AND revoked_at IS NULL",
+		accessTokensTable)
+	res, err := r.db.Exec(query, userId, tokenId)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res, "access token")
+}
+
+// Use looks up an active token by its hash and records the time it was used.
+func (r *AccessTokenPostgres) Use(tokenHash string) (domain.Identity, error) {
+	var token struct {
+		Id     int           `db:"id"`
+		UserId int           `db:"user_id"`
+		Scopes domain.Scopes `db:"scopes"`
+	}
+
+	query := fmt.Sprintf(
+		`UPDATE %s SET last_used_at = $2
+				WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+				RETURNING id, user_id, scopes`,
+		accessTokensTable,
+	)
+	if err := r.db.Get(&token, query, tokenHash, time.Now()); err != nil {
+		return domain.Identity{}, wrapError(err, "access token")
+	}
+
+	return domain.Identity{UserId: token.UserId, AccessTokenId: token.Id, Scopes: token.Scopes}, nil
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pavel-trbv/go-todo-app/internal/domain"
 )
 
@@ -18,19 +20,31 @@ func (r *AuthPostgres) CreateUser(user domain.User) (int, error) {
 	var id int
 	query := fmt.Sprintf("INSERT INTO %s (name, username, password_hash) VALUES ($1, $2, $3) RETURNING id", usersTable)
 
-	row := r.db.QueryRow(query, user.Name, user.Username, user.Password)
+	row := r.db.QueryRow(query, user.Name, user.Username, user.PasswordHash)
 	if err := row.Scan(&id); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return 0, domain.NewError(domain.ErrConflict, "username is already taken")
+		}
 		return 0, err
 	}
 
 	return id, nil
 }
 
-func (r *AuthPostgres) GetUser(username, password string) (domain.User, error) {
+func (r *AuthPostgres) GetUser(username string) (domain.User, error) {
 	var user domain.User
-	query := fmt.Sprintf("SELECT id FROM %s WHERE username=$1 AND password_hash=$2", usersTable)
+	query := fmt.Sprintf("SELECT id, name, username, password_hash FROM %s WHERE username=$1", usersTable)
 
-	err := r.db.Get(&user, query, username, password)
+	err := r.db.Get(&user, query, username)
 
-	return user, err
+	return user, wrapError(err, "user")
+}
+
+func (r *AuthPostgres) UpdatePasswordHash(userId int, passwordHash string) error {
+	query := fmt.Sprintf("UPDATE %s SET password_hash=$1 WHERE id=$2", usersTable)
+
+	_, err := r.db.Exec(query, passwordHash, userId)
+
+	return err
 }
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+)
+
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+	checkViolation      = "23514"
+)
+
+// wrapError translates driver errors into domain errors, so that no raw
+// driver message reaches the client. The resource names what the query was
+// about, e.g. "list" gives "list not found".
+func wrapError(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.NewError(domain.ErrNotFound, resource+" not found")
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case uniqueViolation:
+			return domain.NewError(domain.ErrConflict, resource+" already exists")
+		case foreignKeyViolation:
+			return domain.NewError(domain.ErrNotFound, "referenced "+resource+" not found")
+		case checkViolation:
+			return domain.NewError(domain.ErrValidation, "invalid "+resource)
+		}
+	}
+
+	return err
+}
+
+// requireAffected reports a not found error when a statement did not touch
+// any row.
+func requireAffected(res sql.Result, resource string) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.NewError(domain.ErrNotFound, resource+" not found")
+	}
+
+	return nil
+}
+
+// checkVersion reports a conflict when a row was changed since the given
+// version, and locks it for the rest of the transaction. A nil version skips
+// the check.
+func checkVersion(tx *sqlx.Tx, table string, id int, version *int, resource string) error {
+	if version == nil {
+		return nil
+	}
+
+	var current int
+	query := fmt.Sprintf("SELECT version FROM %s WHERE id = $1 FOR UPDATE", table)
+	if err := tx.Get(&current, query, id); err != nil {
+		return wrapError(err, resource)
+	}
+
+	if current != *version {
+		return domain.NewError(domain.ErrVersionMismatch, fmt.Sprintf("%s was changed, its version is %d", resource, current))
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"time"
+)
+
+// EventsChannel is the NOTIFY channel announcing the id of every stored
+// event.
+const EventsChannel = "events"
+
+// eventsLockKey serializes storing events, see Store.
+const eventsLockKey = 0x6576656e7473
+
+// streamEventRow scans the recipients, which domain.StreamEvent keeps as a
+// plain slice.
+type streamEventRow struct {
+	domain.StreamEvent
+	Recipients pq.Int64Array `db:"recipients"`
+}
+
+func (r streamEventRow) event() domain.StreamEvent {
+	event := r.StreamEvent
+	event.Recipients = make([]int, len(r.Recipients))
+	for i, id := range r.Recipients {
+		event.Recipients[i] = int(id)
+	}
+
+	return event
+}
+
+type EventPostgres struct {
+	db *sqlx.DB
+}
+
+func NewEventPostgres(db *sqlx.DB) *EventPostgres {
+	return &EventPostgres{db: db}
+}
+
+// Store saves an event for the members of its list and the user who caused
+// it, and notifies every instance.
+func (r *EventPostgres) Store(event domain.Event) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := storeEvent(tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// storeEvent saves an event within the transaction. Events are stored one
+// transaction at a time, so ids are taken in commit order and a reader that
+// has seen an id has seen every event before it.
+func storeEvent(tx *sqlx.Tx, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", eventsLockKey); err != nil {
+		return err
+	}
+
+	members, membersArg := eventMembers(event, 6)
+	query := fmt.Sprintf(
+		`WITH e AS (
+					INSERT INTO %s (type, list_id, recipients, payload)
+					VALUES ($1, $2, ARRAY(%s UNION SELECT $3::int), $4)
+					RETURNING id
+				)
+				SELECT pg_notify($5, e.id::text) FROM e`,
+		eventsTable,
+		members,
+	)
+	_, err = tx.Exec(query, event.Type, event.ListId, event.ActorId, string(payload), EventsChannel, membersArg)
+
+	return err
+}
+
+// GetSince returns the events of a user after the given id, oldest first.
+func (r *EventPostgres) GetSince(userId int, afterId int64, limit int) ([]domain.StreamEvent, error) {
+	events := make([]domain.StreamEvent, 0)
+	query := fmt.Sprintf(
+		"SELECT id, type, payload FROM %s WHERE id > $1 AND $2 = ANY(recipients) ORDER BY id LIMIT $3",
+		eventsTable)
+	err := r.db.Select(&events, query, afterId, userId, limit)
+
+	return events, err
+}
+
+// GetAllSince returns the events of all users after the given id, oldest
+// first.
+func (r *EventPostgres) GetAllSince(afterId int64, limit int) ([]domain.StreamEvent, error) {
+	var rows []streamEventRow
+	query := fmt.Sprintf("SELECT id, type, payload, recipients FROM %s WHERE id > $1 ORDER BY id LIMIT $2",
+		eventsTable)
+	if err := r.db.Select(&rows, query, afterId, limit); err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.StreamEvent, len(rows))
+	for i, row := range rows {
+		events[i] = row.event()
+	}
+
+	return events, nil
+}
+
+func (r *EventPostgres) LastId() (int64, error) {
+	var id int64
+	query := fmt.Sprintf("SELECT COALESCE(MAX(id), 0) FROM %s", eventsTable)
+	err := r.db.Get(&id, query)
+
+	return id, err
+}
+
+// Prune deletes the events created before the given time, clients can not
+// resume from them anymore.
+func (r *EventPostgres) Prune(before time.Time) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE created_at < $1", eventsTable)
+	_, err := r.db.Exec(query, before)
+
+	return err
+}
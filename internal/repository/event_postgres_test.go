@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestEventPostgres_Store(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewEventPostgres(db)
+
+	createdAt := time.Date(2021, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name            string
+		event           domain.Event
+		expectedPayload string
+		expectedMembers string
+		membersArg      interface{}
+	}{
+		{
+			name: "List Members",
+			event: domain.Event{
+				Type:      domain.EventItemDeleted,
+				ActorId:   1,
+				ListId:    2,
+				Data:      map[string]int{"id": 3},
+				CreatedAt: createdAt,
+			},
+			expectedPayload: `{"event":"item.deleted","actor_id":1,"list_id":2,"data":{"id":3},` +
+				`"created_at":"2021-10-01T09:00:00Z"}`,
+			expectedMembers: `SELECT ul.user_id FROM users_lists ul WHERE ul.list_id = \$6`,
+			membersArg:      2,
+		},
+		{
+			name: "Recipients",
+			event: domain.Event{
+				Type:       domain.EventListDeleted,
+				ActorId:    1,
+				ListId:     2,
+				Data:       map[string]int{"id": 2},
+				CreatedAt:  createdAt,
+				Recipients: []int{1, 3},
+			},
+			expectedPayload: `{"event":"list.deleted","actor_id":1,"list_id":2,"data":{"id":2},` +
+				`"created_at":"2021-10-01T09:00:00Z"}`,
+			expectedMembers: `SELECT unnest\(\$6::int\[\]\)`,
+			membersArg:      "{1,3}",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1\)`).
+				WithArgs(eventsLockKey).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(`INSERT INTO events \(type, list_id, recipients, payload\) `+
+				`VALUES \(\$1, \$2, ARRAY\(`+testCase.expectedMembers+` UNION SELECT \$3::int\), \$4\) .* `+
+				`SELECT pg_notify\(\$5, e.id::text\) FROM e`).
+				WithArgs(testCase.event.Type, 2, 1, testCase.expectedPayload, EventsChannel, testCase.membersArg).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			assert.NoError(t, r.Store(testCase.event))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestEventPostgres_GetAllSince(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewEventPostgres(db)
+
+	mock.ExpectQuery(`SELECT id, type, payload, recipients FROM events WHERE id > \$1 ORDER BY id LIMIT \$2`).
+		WithArgs(4, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "payload", "recipients"}).
+			AddRow(5, domain.EventListCreated, []byte(`{"id":1}`), "{1,2}").
+			AddRow(6, domain.EventListDeleted, []byte(`{"id":1}`), "{1}"))
+
+	events, err := r.GetAllSince(4, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.StreamEvent{
+		{Id: 5, Type: domain.EventListCreated, Payload: []byte(`{"id":1}`), Recipients: []int{1, 2}},
+		{Id: 6, Type: domain.EventListDeleted, Payload: []byte(`{"id":1}`), Recipients: []int{1}},
+	}, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type IdempotencyPostgres struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyPostgres(db *sqlx.DB) *IdempotencyPostgres {
+	return &IdempotencyPostgres{db: db}
+}
+
+// Lock waits until no other request with the key of the user is running and
+// keeps them waiting until unlock is called, ctx only bounds the wait. The
+// lock is held by a transaction of its own, so it is released even if its
+// connection is lost.
+func (r *IdempotencyPostgres) Lock(ctx context.Context, userId int, key string) (func(), error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, hashtext($2))", userId, key); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return func() {
+		if err := tx.Rollback(); err != nil {
+			logrus.Errorf("failed to release idempotency key of user %d: %s", userId, err.Error())
+		}
+	}, nil
+}
+
+// Get returns the response stored for the key within the last ttl, measured
+// by the clock of the database like the creation times.
+func (r *IdempotencyPostgres) Get(userId int, key string, ttl time.Duration) (domain.IdempotentResponse, error) {
+	var response domain.IdempotentResponse
+
+	query := fmt.Sprintf(
+		`SELECT request_hash, status_code, content_type, body FROM %s
+				WHERE user_id = $1 AND key = $2 AND created_at >= now() - $3 * interval '1 millisecond'`,
+		idempotencyKeysTable,
+	)
+	err := r.db.Get(&response, query, userId, key, ttl.Milliseconds())
+
+	return response, wrapError(err, "idempotency key")
+}
+
+// Save stores the response for the key, replacing an expired one, and drops
+// the other keys of the user older than ttl.
+func (r *IdempotencyPostgres) Save(userId int, key string, response domain.IdempotentResponse,
+	ttl time.Duration) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND created_at < now() - $2 * interval '1 millisecond'",
+		idempotencyKeysTable)
+	if _, err := tx.Exec(deleteQuery, userId, ttl.Milliseconds()); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		`INSERT INTO %s (user_id, key, request_hash, status_code, content_type, body)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				ON CONFLICT (user_id, key) DO NOTHING`,
+		idempotencyKeysTable,
+	)
+	if _, err := tx.Exec(query, userId, key, response.RequestHash, response.StatusCode, response.ContentType,
+		response.Body); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
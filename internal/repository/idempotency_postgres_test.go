@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyPostgres_Lock(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewIdempotencyPostgres(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1, hashtext\(\$2\)\)`).
+		WithArgs(1, "abc").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	unlock, err := r.Lock(context.Background(), 1, "abc")
+	assert.NoError(t, err)
+	unlock()
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyPostgres_Get(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewIdempotencyPostgres(db)
+
+	mock.ExpectQuery(`SELECT request_hash, status_code, content_type, body FROM idempotency_keys (.+) `+
+		`created_at >= now\(\) - \$3 \* interval '1 millisecond'`).
+		WithArgs(1, "abc", 86400000).
+		WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "content_type", "body"}).
+			AddRow("hash", 200, "application/json", []byte(`{"id":2}`)))
+
+	got, err := r.Get(1, "abc", 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.IdempotentResponse{
+		RequestHash: "hash",
+		StatusCode:  200,
+		ContentType: "application/json",
+		Body:        []byte(`{"id":2}`),
+	}, got)
+
+	mock.ExpectQuery(`SELECT request_hash, status_code, content_type, body FROM idempotency_keys`).
+		WithArgs(1, "other", 86400000).
+		WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "content_type", "body"}))
+
+	_, err = r.Get(1, "other", 24*time.Hour)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyPostgres_Save(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewIdempotencyPostgres(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM idempotency_keys WHERE user_id = \$1 `+
+		`AND created_at < now\(\) - \$2 \* interval '1 millisecond'`).
+		WithArgs(1, 86400000).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO idempotency_keys (.+) ON CONFLICT \(user_id, key\) DO NOTHING`).
+		WithArgs(1, "abc", "hash", 201, "application/json", []byte(`{"id":2}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = r.Save(1, "abc", domain.IdempotentResponse{
+		RequestHash: "hash",
+		StatusCode:  201,
+		ContentType: "application/json",
+		Body:        []byte(`{"id":2}`),
+	}, 24*time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
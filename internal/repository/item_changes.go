@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"time"
+)
+
+// itemChanges collects the items a write changes on its own, besides the one
+// it was asked to change: parents completed or reopened along with their
+// children, the next occurrence of a recurring item, the subtasks deleted
+// with their parent. The service only announces the latter, so these are
+// announced by the repository in the same transaction, see emit.
+type itemChanges struct {
+	changes []itemChange
+}
+
+type itemChange struct {
+	eventType string
+	listId    int
+	itemId    int
+}
+
+func (c *itemChanges) add(eventType string, listId int, ids ...int) {
+	for _, id := range ids {
+		c.changes = append(c.changes, itemChange{eventType: eventType, listId: listId, itemId: id})
+	}
+}
+
+// drop forgets the changes of the given type of the items.
+func (c *itemChanges) drop(eventType string, ids ...int) {
+	dropped := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		dropped[id] = true
+	}
+
+	kept := c.changes[:0]
+	for _, change := range c.changes {
+		if change.eventType != eventType || !dropped[change.itemId] {
+			kept = append(kept, change)
+		}
+	}
+	c.changes = kept
+}
+
+// mark and reset undo the changes of a bulk entry rolled back to its
+// savepoint.
+func (c *itemChanges) mark() int {
+	return len(c.changes)
+}
+
+func (c *itemChanges) reset(mark int) {
+	c.changes = c.changes[:mark]
+}
+
+// emit stores an event of every change and queues its webhook deliveries.
+// An item changed more than once is announced once, as it is at the end of
+// the transaction, and not at all when it was deleted afterwards.
+func (c *itemChanges) emit(tx *sqlx.Tx, actorId int) error {
+	deleted := make(map[int]bool)
+	for _, change := range c.changes {
+		if change.eventType == domain.EventItemDeleted {
+			deleted[change.itemId] = true
+		}
+	}
+
+	createdAt := time.Now().UTC()
+	seen := make(map[itemChange]bool)
+	for _, change := range c.changes {
+		key := itemChange{eventType: change.eventType, itemId: change.itemId}
+		if seen[key] || (deleted[change.itemId] && change.eventType != domain.EventItemDeleted) {
+			continue
+		}
+		seen[key] = true
+
+		event := domain.Event{
+			Type:      change.eventType,
+			ActorId:   actorId,
+			ListId:    change.listId,
+			Data:      map[string]int{"id": change.itemId},
+			CreatedAt: createdAt,
+		}
+
+		if change.eventType != domain.EventItemDeleted {
+			var item domain.TodoItem
+			query := fmt.Sprintf("SELECT %s FROM %s ti WHERE ti.id = $1", itemColumns, todoItemsTable)
+			if err := tx.Get(&item, query, change.itemId); err != nil {
+				return err
+			}
+			event.Data = item
+		}
+
+		if err := storeEvent(tx, event); err != nil {
+			return err
+		}
+
+		if err := enqueueEvent(tx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"strings"
+)
+
+type LabelPostgres struct {
+	db *sqlx.DB
+}
+
+func NewLabelPostgres(db *sqlx.DB) *LabelPostgres {
+	return &LabelPostgres{db: db}
+}
+
+func (r *LabelPostgres) Create(userId int, label domain.Label) (int, error) {
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (user_id, name, color) VALUES ($1, $2, $3) RETURNING id", labelsTable)
+	if err := r.db.QueryRow(query, userId, label.Name, label.Color).Scan(&id); err != nil {
+		return 0, wrapError(err, "label")
+	}
+
+	return id, nil
+}
+
+func (r *LabelPostgres) GetAll(userId int) ([]domain.Label, error) {
+	labels := make([]domain.Label, 0)
+	query := fmt.Sprintf("SELECT id, name, color FROM %s WHERE user_id = $1 ORDER BY name", labelsTable)
+	err := r.db.Select(&labels, query, userId)
+
+	return labels, err
+}
+
+func (r *LabelPostgres) GetById(userId, labelId int) (domain.Label, error) {
+	return getLabel(r.db, userId, labelId)
+}
+
+func getLabel(q sqlx.Queryer, userId, labelId int) (domain.Label, error) {
+	var label domain.Label
+	query := fmt.Sprintf("SELECT id, name, color FROM %s WHERE id = $1 AND user_id = $2", labelsTable)
+	err := sqlx.Get(q, &label, query, labelId, userId)
+
+	return label, wrapError(err, "label")
+}
+
+func (r *LabelPostgres) Update(userId, labelId int, patch domain.Patch) error {
+	setValues, args, err := setClause(patch, labelFieldColumns)
+	if err != nil {
+		return err
+	}
+	if len(setValues) == 0 {
+		return domain.NewError(domain.ErrValidation, "update structure has no value")
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d AND user_id = $%d",
+		labelsTable, strings.Join(setValues, ", "), len(args)+1, len(args)+2)
+	args = append(args, labelId, userId)
+
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return wrapError(err, "label")
+	}
+
+	return requireAffected(res, "label")
+}
+
+func (r *LabelPostgres) Delete(userId, labelId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND user_id = $2", labelsTable)
+	res, err := r.db.Exec(query, labelId, userId)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res, "label")
+}
+
+// Attach labels an item. Any member of the item's list may do so, labels are
+// private to their owner and do not change the item itself.
+func (r *LabelPostgres) Attach(userId, itemId, labelId int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, _, err := itemListRole(tx, userId, itemId); err != nil {
+		return err
+	}
+
+	if _, err := getLabel(tx, userId, labelId); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (item_id, label_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		itemsLabelsTable)
+	if _, err := tx.Exec(query, itemId, labelId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *LabelPostgres) Detach(userId, itemId, labelId int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, _, err := itemListRole(tx, userId, itemId); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(
+		`DELETE FROM %s il USING %s l
+				WHERE l.id = il.label_id AND il.item_id = $1 AND il.label_id = $2 AND l.user_id = $3`,
+		itemsLabelsTable,
+		labelsTable,
+	)
+	res, err := tx.Exec(query, itemId, labelId, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res, "label"); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// loadItemLabels fills in the labels the user has put on the given items.
+func loadItemLabels(q sqlx.Queryer, userId int, items []domain.TodoItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(items))
+	for i, item := range items {
+		ids[i] = int64(item.Id)
+	}
+
+	var rows []struct {
+		domain.Label
+		ItemId int `db:"item_id"`
+	}
+
+	query := fmt.Sprintf(
+		`SELECT il.item_id, l.id, l.name, l.color FROM %s il INNER JOIN %s l ON l.id = il.label_id
+				WHERE il.item_id = ANY($1) AND l.user_id = $2 ORDER BY l.name`,
+		itemsLabelsTable,
+		labelsTable,
+	)
+	if err := sqlx.Select(q, &rows, query, pq.Array(ids), userId); err != nil {
+		return err
+	}
+
+	labels := make(map[int][]domain.Label)
+	for _, row := range rows {
+		labels[row.ItemId] = append(labels[row.ItemId], row.Label)
+	}
+
+	for i := range items {
+		items[i].Labels = labels[items[i].Id]
+		if items[i].Labels == nil {
+			items[i].Labels = []domain.Label{}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+)
+
+func TestLabelPostgres_Attach(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewLabelPostgres(db)
+
+	type args struct {
+		userId  int
+		itemId  int
+		labelId int
+	}
+	type mockBehavior func(args args)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{userId: 1, itemId: 2, labelId: 3},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists .* FOR SHARE OF ul").
+					WithArgs(args.userId, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleViewer, 1))
+
+				mock.ExpectQuery("SELECT id, name, color FROM labels").
+					WithArgs(args.labelId, args.userId).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "color"}).AddRow(args.labelId, "@home", "#00ff00"))
+
+				mock.ExpectExec("INSERT INTO items_labels").
+					WithArgs(args.itemId, args.labelId).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Label Of Another User",
+			args: args{userId: 1, itemId: 2, labelId: 3},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(args.userId, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleOwner, 1))
+
+				mock.ExpectQuery("SELECT id, name, color FROM labels").
+					WithArgs(args.labelId, args.userId).
+					WillReturnError(sql.ErrNoRows)
+
+				mock.ExpectRollback()
+			},
+			wantErr: domain.ErrNotFound,
+		},
+		{
+			name: "Not A Member",
+			args: args{userId: 1, itemId: 2, labelId: 3},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(args.userId, args.itemId).
+					WillReturnError(sql.ErrNoRows)
+
+				mock.ExpectRollback()
+			},
+			wantErr: domain.ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args)
+
+			err := r.Attach(testCase.args.userId, testCase.args.itemId, testCase.args.labelId)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLabelPostgres_Update(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewLabelPostgres(db)
+
+	testTable := []struct {
+		name         string
+		patch        domain.Patch
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name:  "OK",
+			patch: domain.Patch{"color": "#00ff00", "name": "@work"},
+			mockBehavior: func() {
+				mock.ExpectExec(`UPDATE labels SET name = \$1, color = \$2 WHERE id = \$3 AND user_id = \$4`).
+					WithArgs("@work", "#00ff00", 2, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:         "Unknown Field",
+			patch:        domain.Patch{"user_id": 3},
+			mockBehavior: func() {},
+			wantErr:      domain.ErrValidation,
+		},
+		{
+			name:  "Not Found",
+			patch: domain.Patch{"name": "@work"},
+			mockBehavior: func() {
+				mock.ExpectExec("UPDATE labels SET name = \\$1").
+					WithArgs("@work", 2, 1).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: domain.ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			err := r.Update(1, 2, testCase.patch)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
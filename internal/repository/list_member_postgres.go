@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+)
+
+type ListMemberPostgres struct {
+	db *sqlx.DB
+}
+
+func NewListMemberPostgres(db *sqlx.DB) *ListMemberPostgres {
+	return &ListMemberPostgres{db: db}
+}
+
+func (r *ListMemberPostgres) GetAll(userId, listId int) ([]domain.ListMember, error) {
+	if _, err := listRole(r.db, userId, listId); err != nil {
+		return nil, err
+	}
+
+	var members []domain.ListMember
+	query := fmt.Sprintf(
+		`SELECT u.id AS user_id, u.name, u.username, ul.role FROM %s ul
+				INNER JOIN %s u ON u.id = ul.user_id
+				WHERE ul.list_id = $1 ORDER BY ul.id`,
+		usersListsTable,
+		usersTable,
+	)
+	err := r.db.Select(&members, query, listId)
+
+	return members, err
+}
+
+func (r *ListMemberPostgres) Invite(userId, listId int, input domain.InviteMemberInput) (int, error) {
+	if err := requireListRole(r.db, userId, listId, domain.RoleOwner); err != nil {
+		return 0, err
+	}
+
+	var inviteeId int
+	getUserQuery := fmt.Sprintf("SELECT id FROM %s WHERE username = $1", usersTable)
+	if err := r.db.Get(&inviteeId, getUserQuery, input.Username); err != nil {
+		return 0, wrapError(err, "user")
+	}
+
+	if _, err := listRole(r.db, inviteeId, listId); err == nil {
+		return 0, domain.ErrAlreadyMember
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return 0, err
+	}
+
+	var id int
+	query := fmt.Sprintf(
+		"INSERT INTO %s (list_id, inviter_id, invitee_id, role) VALUES ($1, $2, $3, $4) RETURNING id",
+		listInvitationsTable,
+	)
+	err := r.db.Get(&id, query, listId, userId, inviteeId, input.Role)
+
+	return id, wrapError(err, "invitation")
+}
+
+func (r *ListMemberPostgres) GetInvitations(userId int) ([]domain.Invitation, error) {
+	var invitations []domain.Invitation
+
+	query := fmt.Sprintf(
+		`SELECT li.id, li.list_id, tl.title AS list_title, u.username AS inviter_username,
+				li.role, li.status, li.created_at
+				FROM %s li
+				INNER JOIN %s tl ON tl.id = li.list_id
+				INNER JOIN %s u ON u.id = li.inviter_id
+				WHERE li.invitee_id = $1 AND li.status = $2 ORDER BY li.id`,
+		listInvitationsTable,
+		todoListsTable,
+		usersTable,
+	)
+	err := r.db.Select(&invitations, query, userId, domain.InvitationPending)
+
+	return invitations, err
+}
+
+func (r *ListMemberPostgres) AcceptInvitation(userId, invitationId int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	var invitation struct {
+		ListId int    `db:"list_id"`
+		Role   string `db:"role"`
+	}
+	getInvitationQuery := fmt.Sprintf(
+		"SELECT list_id, role FROM %s WHERE id = $1 AND invitee_id = $2 AND status = $3 FOR UPDATE",
+		listInvitationsTable,
+	)
+	if err := tx.Get(&invitation, getInvitationQuery, invitationId, userId, domain.InvitationPending); err != nil {
+		tx.Rollback()
+		return wrapError(err, "invitation")
+	}
+
+	if err := lockUser(tx, userId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	position, err := nextListPosition(tx, userId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	addMemberQuery := fmt.Sprintf(
+		`INSERT INTO %s (user_id, list_id, role, position) VALUES ($1, $2, $3, $4)
+				ON CONFLICT (user_id, list_id) DO NOTHING`,
+		usersListsTable,
+	)
+	if _, err := tx.Exec(addMemberQuery, userId, invitation.ListId, invitation.Role, position); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	updateInvitationQuery := fmt.Sprintf("UPDATE %s SET status = $1 WHERE id = $2", listInvitationsTable)
+	if _, err := tx.Exec(updateInvitationQuery, domain.InvitationAccepted, invitationId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ListMemberPostgres) DeclineInvitation(userId, invitationId int) error {
+	query := fmt.Sprintf(
+		"UPDATE %s SET status = $1 WHERE id = $2 AND invitee_id = $3 AND status = $4",
+		listInvitationsTable,
+	)
+	res, err := r.db.Exec(query, domain.InvitationDeclined, invitationId, userId, domain.InvitationPending)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res, "invitation")
+}
+
+func (r *ListMemberPostgres) UpdateRole(userId, listId, memberId int, role string) error {
+	if err := requireListRole(r.db, userId, listId, domain.RoleOwner); err != nil {
+		return err
+	}
+
+	return r.changeMember(listId, memberId, func(tx *sqlx.Tx) (sql.Result, error) {
+		query := fmt.Sprintf("UPDATE %s SET role = $1 WHERE list_id = $2 AND user_id = $3", usersListsTable)
+		return tx.Exec(query, role, listId, memberId)
+	})
+}
+
+func (r *ListMemberPostgres) Remove(userId, listId, memberId int) error {
+	if err := requireListRole(r.db, userId, listId, domain.RoleOwner); err != nil {
+		return err
+	}
+
+	return r.changeMember(listId, memberId, func(tx *sqlx.Tx) (sql.Result, error) {
+		query := fmt.Sprintf("DELETE FROM %s WHERE list_id = $1 AND user_id = $2", usersListsTable)
+		return tx.Exec(query, listId, memberId)
+	})
+}
+
+func (r *ListMemberPostgres) Leave(userId, listId int) error {
+	return r.changeMember(listId, userId, func(tx *sqlx.Tx) (sql.Result, error) {
+		query := fmt.Sprintf("DELETE FROM %s WHERE list_id = $1 AND user_id = $2", usersListsTable)
+		return tx.Exec(query, listId, userId)
+	})
+}
+
+// changeMember applies the change to a membership and rolls it back if the
+// list would be left without an owner. The memberships of the list are locked
+// so that two owners cannot demote each other at the same time.
+func (r *ListMemberPostgres) changeMember(listId, memberId int, change func(tx *sqlx.Tx) (sql.Result, error)) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	lockQuery := fmt.Sprintf("SELECT id FROM %s WHERE list_id = $1 FOR UPDATE", usersListsTable)
+	if _, err := tx.Exec(lockQuery, listId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	res, err := change(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := requireAffected(res, "member"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var owners int
+	countOwnersQuery := fmt.Sprintf("SELECT count(*) FROM %s WHERE list_id = $1 AND role = $2", usersListsTable)
+	if err := tx.Get(&owners, countOwnersQuery, listId, domain.RoleOwner); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if owners == 0 {
+		tx.Rollback()
+		return domain.ErrLastOwner
+	}
+
+	return tx.Commit()
+}
+
+// listRole returns the role of the user in the list. Lists that are not shared
+// with the user are reported as not found rather than forbidden, so that their
+// existence is not disclosed. Within a transaction the membership is locked,
+// the role can not change or be revoked before it ends.
+func listRole(q sqlx.Queryer, userId, listId int) (string, error) {
+	var role string
+
+	query := fmt.Sprintf("SELECT role FROM %s WHERE user_id = $1 AND list_id = $2 FOR SHARE", usersListsTable)
+	err := sqlx.Get(q, &role, query, userId, listId)
+
+	return role, wrapError(err, "list")
+}
+
+// itemListRole returns the role of the user in the list the item belongs to
+// along with the id of that list. Like listRole it locks the membership within
+// a transaction.
+func itemListRole(q sqlx.Queryer, userId, itemId int) (string, int, error) {
+	var membership struct {
+		Role   string `db:"role"`
+		ListId int    `db:"list_id"`
+	}
+
+	query := fmt.Sprintf(
+		`SELECT ul.role, ul.list_id FROM %s ul INNER JOIN %s li ON li.list_id = ul.list_id
+				WHERE ul.user_id = $1 AND li.item_id = $2 FOR SHARE OF ul`,
+		usersListsTable,
+		listsItemsTable,
+	)
+	err := sqlx.Get(q, &membership, query, userId, itemId)
+
+	return membership.Role, membership.ListId, wrapError(err, "item")
+}
+
+func requireListRole(q sqlx.Queryer, userId, listId int, roles ...string) error {
+	role, err := listRole(q, userId, listId)
+	if err != nil {
+		return err
+	}
+
+	return checkRole(role, roles)
+}
+
+func requireItemRole(q sqlx.Queryer, userId, itemId int, roles ...string) error {
+	role, _, err := itemListRole(q, userId, itemId)
+	if err != nil {
+		return err
+	}
+
+	return checkRole(role, roles)
+}
+
+func checkRole(role string, roles []string) error {
+	for _, r := range roles {
+		if r == role {
+			return nil
+		}
+	}
+
+	return domain.NewError(domain.ErrForbidden, "not enough permissions for the list")
+}
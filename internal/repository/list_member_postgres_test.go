@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+)
+
+func TestListMemberPostgres_Leave(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewListMemberPostgres(db)
+
+	type args struct {
+		userId int
+		listId int
+	}
+
+	type mockBehavior func(args args)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			args: args{userId: 2, listId: 1},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT id FROM users_lists").
+					WithArgs(args.listId).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM users_lists").
+					WithArgs(args.listId, args.userId).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT count").
+					WithArgs(args.listId, domain.RoleOwner).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Last Owner",
+			args: args{userId: 1, listId: 1},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SELECT id FROM users_lists").
+					WithArgs(args.listId).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("DELETE FROM users_lists").
+					WithArgs(args.listId, args.userId).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT count").
+					WithArgs(args.listId, domain.RoleOwner).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectRollback()
+			},
+			wantErr: domain.ErrLastOwner,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args)
+
+			err := r.Leave(testCase.args.userId, testCase.args.listId)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
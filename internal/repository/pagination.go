@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"strings"
+	"time"
+)
+
+// sortKey is the SQL expression behind a whitelisted sort field together
+// with the type its cursor value is cast to.
+type sortKey struct {
+	expr string
+	cast string
+}
+
+const cursorTimeLayout = "2006-01-02 15:04:05.999999Z07:00"
+
+// cursor points right after the last row of a page. It records the sort it
+// was issued for, so that it can not be replayed against another ordering.
+type cursor struct {
+	Sort  string `json:"s"`
+	Order string `json:"o"`
+	Value string `json:"v"`
+	Id    int    `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(page domain.Page) (*cursor, error) {
+	if page.Cursor == "" {
+		return nil, nil
+	}
+
+	invalid := domain.NewError(domain.ErrValidation, "invalid cursor")
+
+	b, err := base64.RawURLEncoding.DecodeString(page.Cursor)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, invalid
+	}
+
+	if c.Sort != page.Sort || c.Order != page.Order {
+		return nil, invalid
+	}
+
+	return &c, nil
+}
+
+// queryBuilder collects WHERE conditions written with ? placeholders and
+// numbers them in the order they are added, so user input only ever reaches
+// the database as arguments.
+type queryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (b *queryBuilder) where(condition string, args ...interface{}) {
+	for _, arg := range args {
+		b.args = append(b.args, arg)
+		condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(b.args)), 1)
+	}
+
+	b.conditions = append(b.conditions, condition)
+}
+
+// contains matches a text expression against a search string, treating the
+// LIKE wildcards in it literally.
+func (b *queryBuilder) contains(query string, exprs ...string) {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	pattern := "%" + replacer.Replace(query) + "%"
+
+	matches := make([]string, len(exprs))
+	for i, expr := range exprs {
+		matches[i] = expr + " ILIKE ?"
+	}
+
+	condition := "(" + strings.Join(matches, " OR ") + ")"
+	args := make([]interface{}, len(exprs))
+	for i := range args {
+		args[i] = pattern
+	}
+
+	b.where(condition, args...)
+}
+
+// paginate adds the keyset condition for the cursor and returns the
+// ORDER BY and LIMIT clauses. One extra row is requested to find out whether
+// there is a next page.
+func (b *queryBuilder) paginate(page domain.Page, key sortKey, idExpr string) (string, error) {
+	c, err := decodeCursor(page)
+	if err != nil {
+		return "", err
+	}
+
+	direction, operator := "ASC", ">"
+	if page.Order == domain.SortDesc {
+		direction, operator = "DESC", "<"
+	}
+
+	if c != nil {
+		b.where(fmt.Sprintf("(%s, %s) %s (?::%s, ?)", key.expr, idExpr, operator, key.cast), c.Value, c.Id)
+	}
+
+	return fmt.Sprintf("ORDER BY %s %s, %s %s LIMIT %d", key.expr, direction, idExpr, direction, page.Limit+1), nil
+}
+
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+var listSortKeys = map[string]sortKey{
+	domain.SortByPosition: {expr: "ul.position", cast: "text"},
+	domain.SortById:       {expr: "tl.id", cast: "int"},
+	domain.SortByTitle:    {expr: "tl.title", cast: "text"},
+}
+
+func listSortValue(list domain.TodoList, sort string) string {
+	switch sort {
+	case domain.SortByPosition:
+		return list.Position
+	case domain.SortByTitle:
+		return list.Title
+	default:
+		return fmt.Sprint(list.Id)
+	}
+}
+
+var itemSortKeys = map[string]sortKey{
+	domain.SortByPosition: {expr: "ti.position", cast: "text"},
+	domain.SortById:       {expr: "ti.id", cast: "int"},
+	domain.SortByTitle:    {expr: "ti.title", cast: "text"},
+	domain.SortByPriority: {
+		expr: "CASE ti.priority WHEN 'none' THEN 0 WHEN 'low' THEN 1 WHEN 'medium' THEN 2 " +
+			"WHEN 'high' THEN 3 WHEN 'urgent' THEN 4 END",
+		cast: "int",
+	},
+	// items without a due date go last in ascending order
+	domain.SortByDueAt:     {expr: "COALESCE(ti.due_at, 'infinity')", cast: "timestamptz"},
+	domain.SortByCreatedAt: {expr: "ti.created_at", cast: "timestamptz"},
+	domain.SortByUpdatedAt: {expr: "ti.updated_at", cast: "timestamptz"},
+}
+
+func itemSortValue(item domain.TodoItem, sort string) string {
+	switch sort {
+	case domain.SortByPosition:
+		return item.Position
+	case domain.SortByTitle:
+		return item.Title
+	case domain.SortByPriority:
+		for i, priority := range domain.Priorities {
+			if priority == item.Priority {
+				return fmt.Sprint(i)
+			}
+		}
+		return "0"
+	case domain.SortByDueAt:
+		if item.DueAt == nil {
+			return "infinity"
+		}
+		return formatCursorTime(*item.DueAt)
+	case domain.SortByCreatedAt:
+		return formatCursorTime(item.CreatedAt)
+	case domain.SortByUpdatedAt:
+		return formatCursorTime(item.UpdatedAt)
+	default:
+		return fmt.Sprint(item.Id)
+	}
+}
+
+func formatCursorTime(t time.Time) string {
+	return t.UTC().Format(cursorTimeLayout)
+}
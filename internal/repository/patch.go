@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+)
+
+// fieldColumn maps a field of a patch to the column it is stored in. Also is
+// an assignment made along with it, formatted with the placeholder of the
+// value.
+type fieldColumn struct {
+	field  string
+	column string
+	also   string
+}
+
+// The fields an update may set, in the order they are set. Anything else is
+// rejected.
+var (
+	listFieldColumns = []fieldColumn{
+		{field: "title", column: "title"},
+		{field: "description", column: "description"},
+	}
+	itemFieldColumns = []fieldColumn{
+		{field: "title", column: "title"},
+		{field: "description", column: "description"},
+		// completed_at keeps its original value while the item stays done
+		{field: "done", column: "done",
+			also: "completed_at = CASE WHEN %s THEN COALESCE(ti.completed_at, now()) ELSE NULL END"},
+		{field: "due_at", column: "due_at"},
+		{field: "priority", column: "priority"},
+		{field: "auto_complete", column: "auto_complete"},
+		{field: "recurrence", column: "recurrence"},
+		{field: "timezone", column: "timezone"},
+	}
+	labelFieldColumns = []fieldColumn{
+		{field: "name", column: "name"},
+		{field: "color", column: "color"},
+	}
+	webhookFieldColumns = []fieldColumn{
+		{field: "url", column: "url"},
+		{field: "secret", column: "secret"},
+		{field: "events", column: "events"},
+		{field: "active", column: "active"},
+	}
+)
+
+// setClause turns a patch into the assignments of an update, numbering the
+// placeholders of the values from $1.
+func setClause(patch domain.Patch, columns []fieldColumn) ([]string, []interface{}, error) {
+	setValues := make([]string, 0, len(patch))
+	args := make([]interface{}, 0, len(patch))
+
+	known := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		known[c.field] = true
+
+		value, ok := patch[c.field]
+		if !ok {
+			continue
+		}
+
+		args = append(args, value)
+		placeholder := fmt.Sprintf("$%d", len(args))
+		setValues = append(setValues, c.column+" = "+placeholder)
+		if c.also != "" {
+			setValues = append(setValues, fmt.Sprintf(c.also, placeholder))
+		}
+	}
+
+	for field := range patch {
+		if !known[field] {
+			return nil, nil, domain.NewError(domain.ErrValidation, fmt.Sprintf("field %s can not be changed", field))
+		}
+	}
+
+	return setValues, args, nil
+}
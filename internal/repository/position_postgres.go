@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/rank"
+)
+
+// Positions are computed from the current maximum or the neighbours of a row,
+// so concurrent writers are serialized by locking the row owning the
+// collection: the user for their lists, the list for its items. NO KEY
+// UPDATE does not block inserts referencing the locked row.
+
+func lockUser(tx *sqlx.Tx, userId int) error {
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id = $1 FOR NO KEY UPDATE", usersTable)
+	_, err := tx.Exec(query, userId)
+
+	return err
+}
+
+func lockList(tx *sqlx.Tx, listId int) error {
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id = $1 FOR NO KEY UPDATE", todoListsTable)
+	_, err := tx.Exec(query, listId)
+
+	return err
+}
+
+// lockLists locks two lists in a fixed order, so that moves in opposite
+// directions do not deadlock.
+func lockLists(tx *sqlx.Tx, listId, otherListId int) error {
+	if otherListId < listId {
+		listId, otherListId = otherListId, listId
+	}
+
+	if err := lockList(tx, listId); err != nil {
+		return err
+	}
+
+	if otherListId == listId {
+		return nil
+	}
+
+	return lockList(tx, otherListId)
+}
+
+// nextListPosition returns the position right after the last list of the
+// user. The user has to be locked.
+func nextListPosition(tx *sqlx.Tx, userId int) (string, error) {
+	query := fmt.Sprintf("SELECT MAX(position) FROM %s WHERE user_id = $1", usersListsTable)
+
+	return nextPosition(tx, query, userId)
+}
+
+// nextItemPosition returns the position right after the last item of the
+// list with the given parent, nil standing for top level items. The list has
+// to be locked.
+func nextItemPosition(tx *sqlx.Tx, listId int, parentId *int) (string, error) {
+	query := fmt.Sprintf(
+		`SELECT MAX(ti.position) FROM %s ti INNER JOIN %s li ON li.item_id = ti.id
+				WHERE li.list_id = $1 AND ti.parent_id IS NOT DISTINCT FROM $2`,
+		todoItemsTable,
+		listsItemsTable,
+	)
+
+	return nextPosition(tx, query, listId, parentId)
+}
+
+func nextPosition(tx *sqlx.Tx, query string, args ...interface{}) (string, error) {
+	var last sql.NullString
+	if err := tx.Get(&last, query, args...); err != nil {
+		return "", err
+	}
+
+	if !last.Valid {
+		return rank.Initial, nil
+	}
+
+	return rank.After(last.String)
+}
+
+// movePosition finds the position between the neighbours named by the input.
+// siblingsQuery selects id and position of every sibling of the moved row,
+// the row itself excluded, using args as its parameters.
+func movePosition(tx *sqlx.Tx, siblingsQuery string, args []interface{}, input domain.MoveInput) (string, error) {
+	next := len(args) + 1
+
+	siblingPosition := func(id int, name string) (string, error) {
+		var position string
+		query := fmt.Sprintf("SELECT s.position FROM (%s) s WHERE s.id = $%d", siblingsQuery, next)
+		if err := tx.Get(&position, query, append(args, id)...); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return "", domain.NewError(domain.ErrValidation, name+" must be a sibling")
+			}
+			return "", err
+		}
+
+		return position, nil
+	}
+
+	var lower, upper string
+	var err error
+
+	if input.AfterId != nil {
+		if lower, err = siblingPosition(*input.AfterId, "after_id"); err != nil {
+			return "", err
+		}
+	}
+
+	if input.BeforeId != nil {
+		if upper, err = siblingPosition(*input.BeforeId, "before_id"); err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case input.BeforeId == nil:
+		var position sql.NullString
+		query := fmt.Sprintf("SELECT MIN(s.position) FROM (%s) s WHERE s.position > $%d", siblingsQuery, next)
+		if err := tx.Get(&position, query, append(args, lower)...); err != nil {
+			return "", err
+		}
+		upper = position.String
+	case input.AfterId == nil:
+		var position sql.NullString
+		query := fmt.Sprintf("SELECT MAX(s.position) FROM (%s) s WHERE s.position < $%d", siblingsQuery, next)
+		if err := tx.Get(&position, query, append(args, upper)...); err != nil {
+			return "", err
+		}
+		lower = position.String
+	case lower >= upper:
+		return "", domain.NewError(domain.ErrValidation, "after_id must come before before_id")
+	}
+
+	return rank.Between(lower, upper)
+}
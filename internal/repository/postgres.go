@@ -3,14 +3,30 @@ package repository
 import (
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"time"
 )
 
 const (
-	usersTable      = "users"
-	todoListsTable  = "todo_lists"
-	usersListsTable = "users_lists"
-	todoItemsTable  = "todo_items"
-	listsItemsTable = "lists_items"
+	usersTable             = "users"
+	todoListsTable         = "todo_lists"
+	usersListsTable        = "users_lists"
+	todoItemsTable         = "todo_items"
+	listsItemsTable        = "lists_items"
+	sessionsTable          = "sessions"
+	refreshTokensTable     = "refresh_tokens"
+	accessTokensTable      = "access_tokens"
+	listInvitationsTable   = "list_invitations"
+	labelsTable            = "labels"
+	itemsLabelsTable       = "items_labels"
+	remindersTable         = "reminders"
+	webhooksTable          = "webhooks"
+	webhookDeliveriesTable = "webhook_deliveries"
+	eventsTable            = "events"
+	changesTable           = "changes"
+	syncHorizonTable       = "sync_horizon"
+	idempotencyKeysTable   = "idempotency_keys"
 )
 
 type Config struct {
@@ -22,10 +38,13 @@ type Config struct {
 	SSLMode  string
 }
 
+func (cfg Config) dataSourceName() string {
+	return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.Username, cfg.DBName, cfg.Password, cfg.SSLMode)
+}
+
 func NewPostgresDB(cfg Config) (*sqlx.DB, error) {
-	db, err := sqlx.Connect("postgres",
-		fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
-			cfg.Host, cfg.Port, cfg.Username, cfg.DBName, cfg.Password, cfg.SSLMode))
+	db, err := sqlx.Connect("postgres", cfg.dataSourceName())
 
 	if err != nil {
 		return nil, err
@@ -33,3 +52,21 @@ func NewPostgresDB(cfg Config) (*sqlx.DB, error) {
 
 	return db, nil
 }
+
+// NewEventListener listens for stored events on a dedicated connection,
+// which is reestablished when it is lost.
+func NewEventListener(cfg Config) (*pq.Listener, error) {
+	listener := pq.NewListener(cfg.dataSourceName(), time.Second, time.Minute,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				logrus.Warnf("event listener: %s", err.Error())
+			}
+		})
+
+	if err := listener.Listen(EventsChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
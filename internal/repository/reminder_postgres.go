@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+)
+
+const reminderColumns = "r.id, r.item_id, r.remind_at, r.offset_minutes, r.channel, r.target, r.sent_at"
+
+type ReminderPostgres struct {
+	db *sqlx.DB
+}
+
+func NewReminderPostgres(db *sqlx.DB) *ReminderPostgres {
+	return &ReminderPostgres{db: db}
+}
+
+// Create adds a reminder for the user. Reminders are private like labels, so
+// any member of the item's list may set them.
+func (r *ReminderPostgres) Create(userId, itemId int, reminder domain.Reminder) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, _, err := itemListRole(tx, userId, itemId); err != nil {
+		return 0, err
+	}
+
+	var id int
+	query := fmt.Sprintf(
+		`INSERT INTO %s (item_id, user_id, remind_at, offset_minutes, channel, target)
+				VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		remindersTable,
+	)
+	err = tx.QueryRow(query, itemId, userId, reminder.RemindAt, reminder.OffsetMinutes, reminder.Channel,
+		reminder.Target).Scan(&id)
+	if err != nil {
+		return 0, wrapError(err, "reminder")
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *ReminderPostgres) GetAll(userId, itemId int) ([]domain.Reminder, error) {
+	if _, _, err := itemListRole(r.db, userId, itemId); err != nil {
+		return nil, err
+	}
+
+	reminders := make([]domain.Reminder, 0)
+	query := fmt.Sprintf("SELECT %s FROM %s r WHERE r.item_id = $1 AND r.user_id = $2 ORDER BY r.id",
+		reminderColumns, remindersTable)
+	err := r.db.Select(&reminders, query, itemId, userId)
+
+	return reminders, err
+}
+
+func (r *ReminderPostgres) Delete(userId, reminderId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND user_id = $2", remindersTable)
+	res, err := r.db.Exec(query, reminderId, userId)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res, "reminder")
+}
+
+// ProcessDue claims up to limit due reminders and hands them to send one by
+// one, recording the outcome. Claimed rows stay locked until the end, SKIP
+// LOCKED lets other instances claim the remaining ones meanwhile. Reminders
+// of done items, of items the user lost access to and those that failed
+// maxAttempts times are left alone. It returns how many were sent.
+func (r *ReminderPostgres) ProcessDue(limit, maxAttempts int, send func(domain.DueReminder) error) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	reminders := make([]domain.DueReminder, 0)
+	query := fmt.Sprintf(
+		`SELECT %s, r.user_id, ti.title AS item_title, ti.due_at, f.fire_at
+				FROM %s r
+					INNER JOIN %s ti ON ti.id = r.item_id
+					INNER JOIN %s li ON li.item_id = ti.id
+					INNER JOIN %s ul ON ul.list_id = li.list_id AND ul.user_id = r.user_id
+					CROSS JOIN LATERAL (
+						SELECT COALESCE(r.remind_at, ti.due_at - r.offset_minutes * interval '1 minute') AS fire_at
+					) f
+				WHERE NOT ti.done AND f.fire_at <= now() AND (r.sent_at IS NULL OR r.sent_at < f.fire_at)
+					AND r.attempts < $1
+				ORDER BY f.fire_at, r.id
+				LIMIT $2
+				FOR UPDATE OF r SKIP LOCKED`,
+		reminderColumns,
+		remindersTable,
+		todoItemsTable,
+		listsItemsTable,
+		usersListsTable,
+	)
+	if err := tx.Select(&reminders, query, maxAttempts, limit); err != nil {
+		return 0, err
+	}
+
+	sentQuery := fmt.Sprintf("UPDATE %s SET sent_at = now(), attempts = 0, last_error = NULL WHERE id = $1",
+		remindersTable)
+	failedQuery := fmt.Sprintf("UPDATE %s SET attempts = attempts + 1, last_error = $2 WHERE id = $1",
+		remindersTable)
+
+	sent := 0
+	for _, reminder := range reminders {
+		if err := send(reminder); err != nil {
+			if _, err := tx.Exec(failedQuery, reminder.Id, err.Error()); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		if _, err := tx.Exec(sentQuery, reminder.Id); err != nil {
+			return 0, err
+		}
+		sent++
+	}
+
+	return sent, tx.Commit()
+}
+
+// copyReminders carries the offset reminders of an item over to another one
+// in the list, absolute reminders belong to a single point in time. Only the
+// reminders of the members of the list are copied, the others could not see
+// the item they remind of.
+func copyReminders(tx *sqlx.Tx, listId, fromItemId, toItemId int) error {
+	query := fmt.Sprintf(
+		`INSERT INTO %[1]s (item_id, user_id, offset_minutes, channel, target)
+				SELECT $2, r.user_id, r.offset_minutes, r.channel, r.target FROM %[1]s r
+				WHERE r.item_id = $1 AND r.offset_minutes IS NOT NULL
+					AND r.user_id IN (SELECT ul.user_id FROM %[2]s ul WHERE ul.list_id = $3)`,
+		remindersTable,
+		usersListsTable,
+	)
+	_, err := tx.Exec(query, fromItemId, toItemId, listId)
+
+	return err
+}
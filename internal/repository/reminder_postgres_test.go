@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestReminderPostgres_ProcessDue(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewReminderPostgres(db)
+
+	columns := []string{"id", "item_id", "remind_at", "offset_minutes", "channel", "target", "sent_at", "user_id",
+		"item_title", "due_at", "fire_at"}
+	dueAt := time.Date(2021, 10, 1, 9, 0, 0, 0, time.UTC)
+	fireAt := dueAt.Add(-30 * time.Minute)
+
+	testTable := []struct {
+		name         string
+		mockBehavior func()
+		send         func(domain.DueReminder) error
+		sent         int
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(`WHERE NOT ti.done AND f.fire_at <= now\(\) .* AND r.attempts < \$1 `+
+					`ORDER BY f.fire_at, r.id LIMIT \$2 FOR UPDATE OF r SKIP LOCKED`).
+					WithArgs(5, 10).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, 2, nil, 30, domain.ChannelEmail, "alice@example.com", nil, 1, "Pay rent", dueAt, fireAt).
+						AddRow(3, 4, fireAt, nil, domain.ChannelWebhook, "https://example.com", nil, 1, "Call", nil,
+							fireAt))
+
+				mock.ExpectExec("UPDATE reminders SET sent_at = now\\(\\), attempts = 0, last_error = NULL").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE reminders SET attempts = attempts \\+ 1, last_error = \\$2").
+					WithArgs(3, "connection refused").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectCommit()
+			},
+			send: func(reminder domain.DueReminder) error {
+				if reminder.Channel == domain.ChannelWebhook {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+			sent: 1,
+		},
+		{
+			name: "Nothing Due",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("FOR UPDATE OF r SKIP LOCKED").
+					WithArgs(5, 10).
+					WillReturnRows(sqlmock.NewRows(columns))
+
+				mock.ExpectCommit()
+			},
+			send: func(reminder domain.DueReminder) error {
+				t.Error("nothing should be sent")
+				return nil
+			},
+		},
+		{
+			name: "Recording Fails",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("FOR UPDATE OF r SKIP LOCKED").
+					WithArgs(5, 10).
+					WillReturnRows(sqlmock.NewRows(columns).
+						AddRow(1, 2, nil, 30, domain.ChannelLog, "", nil, 1, "Pay rent", dueAt, fireAt))
+
+				mock.ExpectExec("UPDATE reminders SET sent_at").
+					WithArgs(1).
+					WillReturnError(errors.New("connection lost"))
+
+				mock.ExpectRollback()
+			},
+			send:    func(reminder domain.DueReminder) error { return nil },
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			sent, err := r.ProcessDue(10, 5, testCase.send)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.sent, sent)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -1,41 +1,158 @@
 package repository
 
 import (
+	"context"
 	"github.com/jmoiron/sqlx"
 	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"time"
 )
 
 type Authorization interface {
 	CreateUser(user domain.User) (int, error)
-	GetUser(username, password string) (domain.User, error)
+	GetUser(username string) (domain.User, error)
+	UpdatePasswordHash(userId int, passwordHash string) error
+}
+
+type Session interface {
+	Create(userId int, token domain.RefreshToken) (int, error)
+	GetRefreshToken(tokenHash string) (domain.RefreshToken, error)
+	Rotate(tokenId int, next domain.RefreshToken) (bool, error)
+	Revoke(sessionId int) error
+	RevokeAll(userId int) error
+	IsActive(sessionId int) (bool, error)
+}
+
+type AccessToken interface {
+	Create(userId int, tokenHash string, input domain.CreateAccessTokenInput) (domain.AccessToken, error)
+	GetAll(userId int) ([]domain.AccessToken, error)
+	Revoke(userId, tokenId int) error
+	Use(tokenHash string) (domain.Identity, error)
+}
+
+type ListMember interface {
+	GetAll(userId, listId int) ([]domain.ListMember, error)
+	Invite(userId, listId int, input domain.InviteMemberInput) (int, error)
+	GetInvitations(userId int) ([]domain.Invitation, error)
+	AcceptInvitation(userId, invitationId int) error
+	DeclineInvitation(userId, invitationId int) error
+	UpdateRole(userId, listId, memberId int, role string) error
+	Remove(userId, listId, memberId int) error
+	Leave(userId, listId int) error
 }
 
 type TodoList interface {
 	Create(userId int, list domain.TodoList) (int, error)
-	GetAll(userId int) ([]domain.TodoList, error)
+	GetAll(userId int, filter domain.ListFilter) (domain.ListPage, error)
 	GetById(userId, listId int) (domain.TodoList, error)
-	Delete(userId, listId int) error
-	Update(userId, listId int, input domain.UpdateListInput) error
+	GetRole(userId, listId int) (string, error)
+	Delete(userId, listId int, version *int) ([]int, error)
+	Update(userId, listId int, patch domain.Patch, version *int) error
+	Move(userId, listId int, input domain.MoveInput) error
+	Duplicate(userId, listId int, title string) (int, error)
 }
 
 type TodoItem interface {
-	Create(listId int, item domain.TodoItem) (int, error)
-	GetAll(userId, listId int) ([]domain.TodoItem, error)
+	Create(userId, listId int, item domain.TodoItem) (int, error)
+	GetAll(userId, listId int, filter domain.ItemFilter) (domain.ItemPage, error)
+	GetAllByLabel(userId, labelId int, filter domain.ItemFilter) (domain.ItemPage, error)
 	GetById(userId, itemId int) (domain.TodoItem, error)
-	Delete(userId, itemId int) error
-	Update(userId, itemId int, input domain.UpdateItemInput) error
+	Delete(userId, itemId int, version *int) error
+	Update(userId, itemId int, patch domain.Patch, version *int) error
+	CreateChild(userId, parentId int, item domain.TodoItem) (int, error)
+	GetChildren(userId, parentId int) ([]domain.TodoItem, error)
+	ReorderChildren(userId, parentId int, ids []int) error
+	Move(userId, itemId int, input domain.MoveInput) error
+	Copy(userId, itemId int, input domain.CopyItemInput) (int, error)
+	GetListId(userId, itemId int) (int, error)
+	BulkCreate(userId, listId int, items []domain.TodoItem, atomic bool) (domain.BulkResponse, error)
+	BulkUpdate(userId, listId int, ids []int, patch domain.Patch, atomic bool) (domain.BulkResponse, error)
+	BulkDelete(userId, listId int, ids []int, atomic bool) (domain.BulkResponse, error)
+	ClearCompleted(userId, listId int) ([]int, error)
+}
+
+type Label interface {
+	Create(userId int, label domain.Label) (int, error)
+	GetAll(userId int) ([]domain.Label, error)
+	GetById(userId, labelId int) (domain.Label, error)
+	Update(userId, labelId int, patch domain.Patch) error
+	Delete(userId, labelId int) error
+	Attach(userId, itemId, labelId int) error
+	Detach(userId, itemId, labelId int) error
+}
+
+type Reminder interface {
+	Create(userId, itemId int, reminder domain.Reminder) (int, error)
+	GetAll(userId, itemId int) ([]domain.Reminder, error)
+	Delete(userId, reminderId int) error
+	ProcessDue(limit, maxAttempts int, send func(domain.DueReminder) error) (int, error)
+}
+
+type Webhook interface {
+	Create(userId int, webhook domain.Webhook) (int, error)
+	GetAll(userId int) ([]domain.Webhook, error)
+	GetById(userId, webhookId int) (domain.Webhook, error)
+	Update(userId, webhookId int, patch domain.Patch) error
+	Delete(userId, webhookId int) error
+	Enqueue(event domain.Event) error
+	GetDeliveries(userId, webhookId, limit int) ([]domain.WebhookDelivery, error)
+	Redeliver(userId, webhookId, deliveryId int) error
+	ProcessPending(limit int, lease time.Duration,
+		deliver func(domain.WebhookDelivery) domain.DeliveryResult) (int, error)
+}
+
+type Event interface {
+	Store(event domain.Event) error
+	GetSince(userId int, afterId int64, limit int) ([]domain.StreamEvent, error)
+	GetAllSince(afterId int64, limit int) ([]domain.StreamEvent, error)
+	LastId() (int64, error)
+	Prune(before time.Time) error
+}
+
+type Sync interface {
+	GetChanges(userId int, token string) (domain.SyncChanges, error)
+	Prune(before time.Time) error
+}
+
+type Idempotency interface {
+	Lock(ctx context.Context, userId int, key string) (func(), error)
+	Get(userId int, key string, ttl time.Duration) (domain.IdempotentResponse, error)
+	Save(userId int, key string, response domain.IdempotentResponse, ttl time.Duration) error
+}
+
+type Search interface {
+	Search(userId int, input domain.SearchInput) ([]domain.SearchResult, error)
 }
 
 type Repository struct {
 	Authorization
+	Session
+	AccessToken
 	TodoList
+	ListMember
 	TodoItem
+	Label
+	Reminder
+	Webhook
+	Event
+	Sync
+	Idempotency
+	Search
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
 	return &Repository{
 		Authorization: NewAuthPostgres(db),
+		Session:       NewSessionPostgres(db),
+		AccessToken:   NewAccessTokenPostgres(db),
 		TodoList:      NewTodoListPostgres(db),
+		ListMember:    NewListMemberPostgres(db),
 		TodoItem:      NewTodoItemPostgres(db),
+		Label:         NewLabelPostgres(db),
+		Reminder:      NewReminderPostgres(db),
+		Webhook:       NewWebhookPostgres(db),
+		Event:         NewEventPostgres(db),
+		Sync:          NewSyncPostgres(db),
+		Idempotency:   NewIdempotencyPostgres(db),
+		Search:        NewSearchPostgres(db),
 	}
 }
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+)
+
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2"
+
+// escapeHTML escapes the text of a SQL expression for HTML. Headlines are
+// built from escaped text, so the only markup of a snippet is its <mark>
+// tags.
+func escapeHTML(expr string) string {
+	return fmt.Sprintf(
+		`replace(replace(replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), `+
+			`'''', '&#39;')`,
+		expr)
+}
+
+type SearchPostgres struct {
+	db *sqlx.DB
+}
+
+func NewSearchPostgres(db *sqlx.DB) *SearchPostgres {
+	return &SearchPostgres{db: db}
+}
+
+// Search matches the query against the search vectors of every list the user
+// is a member of and every item in those lists, best matches first.
+func (r *SearchPostgres) Search(userId int, input domain.SearchInput) ([]domain.SearchResult, error) {
+	results := make([]domain.SearchResult, 0)
+
+	query := fmt.Sprintf(
+		`SELECT '%s' AS type, tl.id, tl.title,
+					ts_headline('english', %s, q, $3) AS snippet,
+					ts_rank(tl.search_vector, q) AS rank, tl.id AS "list.id", tl.title AS "list.title"
+				FROM %s tl INNER JOIN %s ul ON ul.list_id = tl.id, websearch_to_tsquery('english', $1) q
+				WHERE ul.user_id = $2 AND tl.search_vector @@ q
+			UNION ALL
+			SELECT '%s' AS type, ti.id, ti.title,
+					ts_headline('english', %s, q, $3) AS snippet,
+					ts_rank(ti.search_vector, q) AS rank, tl.id AS "list.id", tl.title AS "list.title"
+				FROM %s ti INNER JOIN %s li ON li.item_id = ti.id
+					INNER JOIN %s tl ON tl.id = li.list_id
+					INNER JOIN %s ul ON ul.list_id = li.list_id, websearch_to_tsquery('english', $1) q
+				WHERE ul.user_id = $2 AND ti.search_vector @@ q
+			ORDER BY rank DESC, type, id
+			LIMIT $4`,
+		domain.SearchResultList,
+		escapeHTML("concat_ws(' ', tl.title, tl.description)"),
+		todoListsTable,
+		usersListsTable,
+		domain.SearchResultItem,
+		escapeHTML("concat_ws(' ', ti.title, ti.description)"),
+		todoItemsTable,
+		listsItemsTable,
+		todoListsTable,
+		usersListsTable,
+	)
+
+	err := r.db.Select(&results, query, input.Query, userId, searchHeadlineOptions, input.Limit)
+
+	return results, err
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+)
+
+func TestSearchPostgres_Search(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewSearchPostgres(db)
+
+	escaped := `ts_headline\('english', replace\(replace\(replace\(replace\(replace\(` +
+		`concat_ws\(' ', %s.title, %s.description\), '&', '&amp;'\), '<', '&lt;'\), '>', '&gt;'\), ` +
+		`'"', '&quot;'\), '''', '&#39;'\), q, \$3\) AS snippet`
+
+	mock.ExpectQuery(fmt.Sprintf(escaped, "tl", "tl")+".*"+fmt.Sprintf(escaped, "ti", "ti")).
+		WithArgs("report", 1, searchHeadlineOptions, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "id", "title", "snippet", "rank", "list.id", "list.title"}).
+			AddRow(domain.SearchResultItem, 3, "<b>Send</b> the report", "&lt;b&gt;Send&lt;/b&gt; the <mark>report</mark>",
+				0.1, 2, "Work"))
+
+	results, err := r.Search(1, domain.SearchInput{Query: "report", Limit: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.SearchResult{{
+		Type:    domain.SearchResultItem,
+		Id:      3,
+		Title:   "<b>Send</b> the report",
+		Snippet: "&lt;b&gt;Send&lt;/b&gt; the <mark>report</mark>",
+		Rank:    0.1,
+		List:    domain.SearchList{Id: 2, Title: "Work"},
+	}}, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
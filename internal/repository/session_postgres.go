@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+)
+
+type SessionPostgres struct {
+	db *sqlx.DB
+}
+
+func NewSessionPostgres(db *sqlx.DB) *SessionPostgres {
+	return &SessionPostgres{db: db}
+}
+
+func (r *SessionPostgres) Create(userId int, token domain.RefreshToken) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	var sessionId int
+	createSessionQuery := fmt.Sprintf("INSERT INTO %s (user_id) VALUES ($1) RETURNING id", sessionsTable)
+	row := tx.QueryRow(createSessionQuery, userId)
+	if err := row.Scan(&sessionId); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	createTokenQuery := fmt.Sprintf("INSERT INTO %s (session_id, token_hash, expires_at) VALUES ($1, $2, $3)",
+		refreshTokensTable)
+	if _, err := tx.Exec(createTokenQuery, sessionId, token.TokenHash, token.ExpiresAt); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return sessionId, tx.Commit()
+}
+
+func (r *SessionPostgres) GetRefreshToken(tokenHash string) (domain.RefreshToken, error) {
+	var token domain.RefreshToken
+
+	query := fmt.Sprintf(
+		`SELECT rt.id, rt.session_id, s.user_id, rt.token_hash, rt.expires_at, rt.used_at, s.revoked_at
+				FROM %s rt INNER JOIN %s s ON s.id = rt.session_id
+				WHERE rt.token_hash = $1`,
+		refreshTokensTable,
+		sessionsTable,
+	)
+	err := r.db.Get(&token, query, tokenHash)
+
+	return token, wrapError(err, "refresh token")
+}
+
+// Rotate marks the refresh token as used and stores its successor in the same
+// session. It returns false if the token has already been used, which means a
+// concurrent or replayed refresh request won the race.
+func (r *SessionPostgres) Rotate(tokenId int, next domain.RefreshToken) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	useTokenQuery := fmt.Sprintf("UPDATE %s SET used_at = now() WHERE id = $1 AND used_at IS NULL",
+		refreshTokensTable)
+	res, err := tx.Exec(useTokenQuery, tokenId)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if affected == 0 {
+		return false, tx.Rollback()
+	}
+
+	createTokenQuery := fmt.Sprintf("INSERT INTO %s (session_id, token_hash, expires_at) VALUES ($1, $2, $3)",
+		refreshTokensTable)
+	if _, err := tx.Exec(createTokenQuery, next.SessionId, next.TokenHash, next.ExpiresAt); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *SessionPostgres) Revoke(sessionId int) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL", sessionsTable)
+	_, err := r.db.Exec(query, sessionId)
+
+	return err
+}
+
+func (r *SessionPostgres) RevokeAll(userId int) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL", sessionsTable)
+	_, err := r.db.Exec(query, userId)
+
+	return err
+}
+
+func (r *SessionPostgres) IsActive(sessionId int) (bool, error) {
+	var active bool
+
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE id = $1 AND revoked_at IS NULL)", sessionsTable)
+	err := r.db.Get(&active, query, sessionId)
+
+	return active, err
+}
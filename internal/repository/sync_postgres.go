@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"strconv"
+	"time"
+)
+
+type SyncPostgres struct {
+	db *sqlx.DB
+}
+
+func NewSyncPostgres(db *sqlx.DB) *SyncPostgres {
+	return &SyncPostgres{db: db}
+}
+
+// A sync token is the oldest transaction still running when the changes were
+// read. Transactions commit out of order, so the next sync reads the changes
+// of that transaction onwards again, which may repeat a few changes but never
+// misses one.
+func encodeSyncToken(txId int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(txId, 10)))
+}
+
+func decodeSyncToken(token string) (int64, error) {
+	invalid := domain.NewError(domain.ErrValidation, "invalid sync token")
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, invalid
+	}
+
+	txId, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil || txId <= 0 {
+		return 0, invalid
+	}
+
+	return txId, nil
+}
+
+// GetChanges returns the lists and items that changed for the user since the
+// token, an empty token returns all of them. Labels are not part of the sync.
+// Tokens older than the pruned part of the change log are expired.
+func (r *SyncPostgres) GetChanges(userId int, token string) (domain.SyncChanges, error) {
+	var since int64
+	if token != "" {
+		var err error
+		if since, err = decodeSyncToken(token); err != nil {
+			return domain.SyncChanges{}, err
+		}
+	}
+
+	// all queries see the same snapshot, the one the token is taken from
+	tx, err := r.db.BeginTxx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return domain.SyncChanges{}, err
+	}
+	defer tx.Rollback()
+
+	var xmin int64
+	if err := tx.Get(&xmin, "SELECT txid_snapshot_xmin(txid_current_snapshot())"); err != nil {
+		return domain.SyncChanges{}, err
+	}
+
+	changes := domain.SyncChanges{
+		Token: encodeSyncToken(xmin),
+		Lists: make([]domain.TodoList, 0),
+		Items: make([]domain.SyncItem, 0),
+		Deleted: domain.SyncDeleted{
+			Lists: make([]int, 0),
+			Items: make([]int, 0),
+		},
+	}
+
+	listsQuery := fmt.Sprintf(
+		`SELECT %s, ul.role, ul.position FROM %s tl
+				INNER JOIN %s ul ON tl.id = ul.list_id
+				WHERE ul.user_id = $1`,
+		listColumns,
+		todoListsTable,
+		usersListsTable,
+	)
+	itemsQuery := fmt.Sprintf(
+		`SELECT %s, li.list_id FROM %s ti INNER JOIN %s li ON li.item_id = ti.id
+				INNER JOIN %s ul ON ul.list_id = li.list_id
+				WHERE ul.user_id = $1`,
+		itemColumns,
+		todoItemsTable,
+		listsItemsTable,
+		usersListsTable,
+	)
+
+	if token == "" {
+		if err := tx.Select(&changes.Lists, listsQuery+" ORDER BY tl.id", userId); err != nil {
+			return domain.SyncChanges{}, err
+		}
+		if err := tx.Select(&changes.Items, itemsQuery+" ORDER BY ti.id", userId); err != nil {
+			return domain.SyncChanges{}, err
+		}
+
+		return changes, tx.Commit()
+	}
+
+	var horizon int64
+	if err := tx.Get(&horizon, fmt.Sprintf("SELECT tx_id FROM %s", syncHorizonTable)); err != nil {
+		return domain.SyncChanges{}, err
+	}
+	if since <= horizon {
+		return domain.SyncChanges{}, domain.NewError(domain.ErrSyncTokenExpired,
+			"sync token expired, sync again without a token")
+	}
+
+	listIds, err := changedIds(tx, userId, domain.EntityList, since)
+	if err != nil {
+		return domain.SyncChanges{}, err
+	}
+	if err := tx.Select(&changes.Lists, listsQuery+" AND tl.id = ANY($2) ORDER BY tl.id",
+		userId, pq.Array(listIds)); err != nil {
+		return domain.SyncChanges{}, err
+	}
+
+	itemIds, err := changedIds(tx, userId, domain.EntityItem, since)
+	if err != nil {
+		return domain.SyncChanges{}, err
+	}
+	if err := tx.Select(&changes.Items, itemsQuery+" AND ti.id = ANY($2) ORDER BY ti.id",
+		userId, pq.Array(itemIds)); err != nil {
+		return domain.SyncChanges{}, err
+	}
+
+	// changed lists and items the user can not see anymore are gone for them
+	visible := make(map[int]bool, len(changes.Lists))
+	for _, list := range changes.Lists {
+		visible[list.Id] = true
+	}
+	for _, id := range listIds {
+		if !visible[id] {
+			changes.Deleted.Lists = append(changes.Deleted.Lists, id)
+		}
+	}
+
+	visible = make(map[int]bool, len(changes.Items))
+	for _, item := range changes.Items {
+		visible[item.Id] = true
+	}
+	for _, id := range itemIds {
+		if !visible[id] {
+			changes.Deleted.Items = append(changes.Deleted.Items, id)
+		}
+	}
+
+	return changes, tx.Commit()
+}
+
+// Prune deletes the changes made before the given time and moves the horizon
+// past them, in one statement so GetChanges never sees one without the other.
+func (r *SyncPostgres) Prune(before time.Time) error {
+	query := fmt.Sprintf(
+		`WITH pruned AS (DELETE FROM %s WHERE created_at < $1 RETURNING tx_id)
+				UPDATE %s SET tx_id = GREATEST(tx_id, (SELECT MAX(tx_id) FROM pruned))`,
+		changesTable,
+		syncHorizonTable,
+	)
+	_, err := r.db.Exec(query, before)
+
+	return err
+}
+
+func changedIds(tx *sqlx.Tx, userId int, entity string, since int64) ([]int, error) {
+	ids := make([]int, 0)
+	query := fmt.Sprintf(
+		"SELECT DISTINCT entity_id FROM %s WHERE user_id = $1 AND entity = $2 AND tx_id >= $3 ORDER BY entity_id",
+		changesTable)
+	err := tx.Select(&ids, query, userId, entity, since)
+
+	return ids, err
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestSyncPostgres_GetChanges(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewSyncPostgres(db)
+
+	type mockBehavior func()
+
+	testTable := []struct {
+		name         string
+		token        string
+		mockBehavior mockBehavior
+		want         domain.SyncChanges
+		wantErr      bool
+		errKind      error
+	}{
+		{
+			name:  "Full",
+			token: "",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT txid_snapshot_xmin\(txid_current_snapshot\(\)\)`).
+					WillReturnRows(sqlmock.NewRows([]string{"xmin"}).AddRow(12))
+				mock.ExpectQuery(`SELECT (.+), ul.role, ul.position FROM todo_lists tl (.+) WHERE ul.user_id = \$1 ORDER BY tl.id`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "version", "role", "position"}).
+						AddRow(1, "groceries", nil, 2, domain.RoleOwner, "a0"))
+				mock.ExpectQuery(`SELECT (.+), li.list_id FROM todo_items ti (.+) WHERE ul.user_id = \$1 ORDER BY ti.id`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "title", "version", "list_id"}).
+						AddRow(5, "milk", 1, 1))
+				mock.ExpectCommit()
+			},
+			want: domain.SyncChanges{
+				Token: "MTI",
+				Lists: []domain.TodoList{{Id: 1, Title: "groceries", Version: 2, Role: domain.RoleOwner, Position: "a0"}},
+				Items: []domain.SyncItem{{TodoItem: domain.TodoItem{Id: 5, Title: "milk", Version: 1}, ListId: 1}},
+				Deleted: domain.SyncDeleted{
+					Lists: []int{},
+					Items: []int{},
+				},
+			},
+		},
+		{
+			name:  "Since Token",
+			token: "MTA",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT txid_snapshot_xmin\(txid_current_snapshot\(\)\)`).
+					WillReturnRows(sqlmock.NewRows([]string{"xmin"}).AddRow(12))
+				mock.ExpectQuery(`SELECT tx_id FROM sync_horizon`).
+					WillReturnRows(sqlmock.NewRows([]string{"tx_id"}).AddRow(9))
+				mock.ExpectQuery(`SELECT DISTINCT entity_id FROM changes WHERE user_id = \$1 AND entity = \$2 AND tx_id >= \$3`).
+					WithArgs(1, domain.EntityList, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(1).AddRow(2))
+				mock.ExpectQuery(`SELECT (.+) FROM todo_lists tl (.+) WHERE ul.user_id = \$1 AND tl.id = ANY\(\$2\)`).
+					WithArgs(1, "{1,2}").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "version", "role", "position"}).
+						AddRow(1, "groceries", nil, 3, domain.RoleEditor, "a0"))
+				mock.ExpectQuery(`SELECT DISTINCT entity_id FROM changes WHERE user_id = \$1 AND entity = \$2 AND tx_id >= \$3`).
+					WithArgs(1, domain.EntityItem, 10).
+					WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(5).AddRow(6))
+				mock.ExpectQuery(`SELECT (.+) FROM todo_items ti (.+) WHERE ul.user_id = \$1 AND ti.id = ANY\(\$2\)`).
+					WithArgs(1, "{5,6}").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "title", "version", "list_id"}).
+						AddRow(5, "milk", 2, 1))
+				mock.ExpectCommit()
+			},
+			want: domain.SyncChanges{
+				Token: "MTI",
+				Lists: []domain.TodoList{{Id: 1, Title: "groceries", Version: 3, Role: domain.RoleEditor, Position: "a0"}},
+				Items: []domain.SyncItem{{TodoItem: domain.TodoItem{Id: 5, Title: "milk", Version: 2}, ListId: 1}},
+				Deleted: domain.SyncDeleted{
+					Lists: []int{2},
+					Items: []int{6},
+				},
+			},
+		},
+		{
+			name:  "Expired Token",
+			token: "MTA",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT txid_snapshot_xmin\(txid_current_snapshot\(\)\)`).
+					WillReturnRows(sqlmock.NewRows([]string{"xmin"}).AddRow(12))
+				mock.ExpectQuery(`SELECT tx_id FROM sync_horizon`).
+					WillReturnRows(sqlmock.NewRows([]string{"tx_id"}).AddRow(10))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+			errKind: domain.ErrSyncTokenExpired,
+		},
+		{
+			name:         "Invalid Token",
+			token:        "not a token",
+			mockBehavior: func() {},
+			wantErr:      true,
+			errKind:      domain.ErrValidation,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			got, err := r.GetChanges(1, testCase.token)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, testCase.errKind)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSyncPostgres_Prune(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewSyncPostgres(db)
+
+	before := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(`WITH pruned AS \(DELETE FROM changes WHERE created_at < \$1 RETURNING tx_id\) UPDATE sync_horizon`).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, r.Prune(before))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
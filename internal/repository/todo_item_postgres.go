@@ -1,14 +1,25 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/rank"
+	"github.com/pavel-trbv/go-todo-app/internal/recurrence"
 	"github.com/sirupsen/logrus"
-	"reflect"
 	"strings"
+	"time"
 )
 
+// itemColumns are selected explicitly, todo_items also holds the search
+// vector which has no place in domain.TodoItem.
+const itemColumns = "ti.id, ti.title, ti.description, ti.done, ti.due_at, ti.priority, " +
+	"ti.created_at, ti.updated_at, ti.completed_at, ti.parent_id, ti.position, ti.auto_complete, ti.recurrence, " +
+	"ti.timezone, ti.version"
+
 type TodoItemPostgres struct {
 	db *sqlx.DB
 }
@@ -17,118 +28,1033 @@ func NewTodoItemPostgres(db *sqlx.DB) *TodoItemPostgres {
 	return &TodoItemPostgres{db: db}
 }
 
-func (r *TodoItemPostgres) Create(listId int, item domain.TodoItem) (int, error) {
-	tx, err := r.db.Begin()
+func (r *TodoItemPostgres) Create(userId, listId int, item domain.TodoItem) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := requireListRole(tx, userId, listId, domain.WriteRoles...); err != nil {
+		return 0, err
+	}
+
+	itemId, err := createItem(tx, listId, item)
+	if err != nil {
+		return 0, err
+	}
+
+	return itemId, tx.Commit()
+}
+
+// createItem appends a top level item to the list.
+func createItem(tx *sqlx.Tx, listId int, item domain.TodoItem) (int, error) {
+	if err := lockList(tx, listId); err != nil {
+		return 0, err
+	}
+
+	position, err := nextItemPosition(tx, listId, nil)
 	if err != nil {
 		return 0, err
 	}
 
 	var itemId int
-	createItemQuery := fmt.Sprintf("INSERT INTO %s (title, description) VALUES ($1, $2) RETURNING id",
+	createItemQuery := fmt.Sprintf(
+		`INSERT INTO %s (title, description, due_at, priority, auto_complete, recurrence, timezone, position)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
 		todoItemsTable)
 
-	row := tx.QueryRow(createItemQuery, item.Title, item.Description)
+	row := tx.QueryRow(createItemQuery, item.Title, item.Description, item.DueAt, item.Priority, item.AutoComplete,
+		item.Recurrence, item.Timezone, position)
 	if err := row.Scan(&itemId); err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			return 0, rollbackErr
-		}
 		return 0, err
 	}
 
 	createListsItemsQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) VALUES ($1, $2)", listsItemsTable)
 	if _, err := tx.Exec(createListsItemsQuery, listId, itemId); err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			return 0, rollbackErr
-		}
 		return 0, err
 	}
 
-	return itemId, tx.Commit()
+	return itemId, nil
+}
+
+// GetAll returns the top level items of the list. Positions only rank
+// siblings, subtasks are returned by GetChildren.
+func (r *TodoItemPostgres) GetAll(userId, listId int, filter domain.ItemFilter) (domain.ItemPage, error) {
+	var b queryBuilder
+	b.where("li.list_id = ?", listId)
+	b.where("ti.parent_id IS NULL")
+
+	return r.getAll(&b, userId, filter)
+}
+
+// GetAllByLabel returns the items of every list the user is a member of that
+// carry the label.
+func (r *TodoItemPostgres) GetAllByLabel(userId, labelId int, filter domain.ItemFilter) (domain.ItemPage, error) {
+	filter.LabelId = labelId
+
+	return r.getAll(&queryBuilder{}, userId, filter)
 }
 
-func (r *TodoItemPostgres) GetAll(userId, listId int) ([]domain.TodoItem, error) {
+func (r *TodoItemPostgres) getAll(b *queryBuilder, userId int, filter domain.ItemFilter) (domain.ItemPage, error) {
+	b.where("ul.user_id = ?", userId)
+
+	if filter.Done != nil {
+		b.where("ti.done = ?", *filter.Done)
+	}
+	if len(filter.Priorities) > 0 {
+		b.where("ti.priority = ANY(?)", pq.Array(filter.Priorities))
+	}
+	if filter.DueBefore != nil {
+		b.where("ti.due_at < ?", *filter.DueBefore)
+	}
+	if filter.DueAfter != nil {
+		b.where("ti.due_at > ?", *filter.DueAfter)
+	}
+	if filter.LabelId != 0 {
+		b.where(fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s il INNER JOIN %s l ON l.id = il.label_id "+
+				"WHERE il.item_id = ti.id AND l.id = ? AND l.user_id = ?)",
+			itemsLabelsTable, labelsTable), filter.LabelId, userId)
+	}
+	if filter.Query != "" {
+		b.contains(filter.Query, "ti.title", "ti.description")
+	}
+
+	orderQuery, err := b.paginate(filter.Page, itemSortKeys[filter.Sort], "ti.id")
+	if err != nil {
+		return domain.ItemPage{}, err
+	}
+
 	var items []domain.TodoItem
 	query := fmt.Sprintf(
-		`SELECT ti.* FROM %s ti INNER JOIN %s li ON li.item_id = ti.id
-				INNER JOIN %s ul ON ul.list_id = li.list_id WHERE li.list_id = $1 AND ul.user_id = $2`,
+		`SELECT %s FROM %s ti INNER JOIN %s li ON li.item_id = ti.id
+				INNER JOIN %s ul ON ul.list_id = li.list_id %s %s`,
+		itemColumns,
 		todoItemsTable,
 		listsItemsTable,
 		usersListsTable,
+		b.whereClause(),
+		orderQuery,
 	)
 
-	if err := r.db.Select(&items, query, listId, userId); err != nil {
-		return nil, err
+	if err := r.db.Select(&items, query, b.args...); err != nil {
+		return domain.ItemPage{}, err
 	}
 
-	return items, nil
+	if err := loadItemLabels(r.db, userId, items); err != nil {
+		return domain.ItemPage{}, err
+	}
+
+	page := domain.ItemPage{Data: items}
+	if len(items) > filter.Limit {
+		page.Data = items[:filter.Limit]
+		last := page.Data[filter.Limit-1]
+		page.NextCursor = encodeCursor(cursor{
+			Sort:  filter.Sort,
+			Order: filter.Order,
+			Value: itemSortValue(last, filter.Sort),
+			Id:    last.Id,
+		})
+	}
+
+	return page, nil
 }
 
 func (r *TodoItemPostgres) GetById(userId, itemId int) (domain.TodoItem, error) {
 	var item domain.TodoItem
 	query := fmt.Sprintf(
-		`SELECT ti.* FROM %s ti INNER JOIN %s li ON li.item_id = ti.id
+		`SELECT %s FROM %s ti INNER JOIN %s li ON li.item_id = ti.id
 				INNER JOIN %s ul ON ul.list_id = li.list_id WHERE ti.id = $1 AND ul.user_id = $2`,
+		itemColumns,
 		todoItemsTable,
 		listsItemsTable,
 		usersListsTable,
 	)
 
 	if err := r.db.Get(&item, query, itemId, userId); err != nil {
+		return item, wrapError(err, "item")
+	}
+
+	items := []domain.TodoItem{item}
+	if err := loadItemLabels(r.db, userId, items); err != nil {
 		return item, err
 	}
 
-	return item, nil
+	return items[0], nil
+}
+
+// Delete removes the item together with its whole subtree, the children go
+// with it through the parent_id foreign key.
+func (r *TodoItemPostgres) Delete(userId, itemId int, version *int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var changes itemChanges
+	if err := deleteItem(tx, userId, itemId, version, &changes); err != nil {
+		return err
+	}
+
+	if err := changes.emit(tx, userId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetListId returns the list of an item the user has access to.
+func (r *TodoItemPostgres) GetListId(userId, itemId int) (int, error) {
+	_, listId, err := itemListRole(r.db, userId, itemId)
+
+	return listId, err
+}
+
+func deleteItem(tx *sqlx.Tx, userId, itemId int, version *int, changes *itemChanges) error {
+	role, listId, err := itemListRole(tx, userId, itemId)
+	if err != nil {
+		return err
+	}
+	if err := checkRole(role, domain.WriteRoles); err != nil {
+		return err
+	}
+
+	if err := checkVersion(tx, todoItemsTable, itemId, version, "item"); err != nil {
+		return err
+	}
+
+	descendants, err := selectDescendantIds(tx, itemId)
+	if err != nil {
+		return err
+	}
+
+	var parentId *int
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 RETURNING parent_id", todoItemsTable)
+	if err := tx.Get(&parentId, query, itemId); err != nil {
+		return wrapError(err, "item")
+	}
+	changes.add(domain.EventItemDeleted, listId, descendants...)
+
+	if parentId != nil {
+		updated, err := syncCompletion(tx, *parentId)
+		if err != nil {
+			return err
+		}
+		changes.add(domain.EventItemUpdated, listId, updated...)
+	}
+
+	return nil
+}
+
+func (r *TodoItemPostgres) Update(userId, itemId int, patch domain.Patch, version *int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var changes itemChanges
+	if err := updateItem(tx, userId, itemId, patch, version, &changes); err != nil {
+		return err
+	}
+
+	if err := changes.emit(tx, userId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func updateItem(tx *sqlx.Tx, userId, itemId int, patch domain.Patch, version *int, changes *itemChanges) error {
+	setValues, args, err := setClause(patch, itemFieldColumns)
+	if err != nil {
+		return err
+	}
+
+	role, listId, err := itemListRole(tx, userId, itemId)
+	if err != nil {
+		return err
+	}
+	if err := checkRole(role, domain.WriteRoles); err != nil {
+		return err
+	}
+
+	if err := checkVersion(tx, todoItemsTable, itemId, version, "item"); err != nil {
+		return err
+	}
+
+	// only the transition to done moves a series on
+	completing := false
+	if done, ok := patch["done"].(bool); ok && done {
+		var done bool
+		query := fmt.Sprintf("SELECT done FROM %s WHERE id = $1 FOR UPDATE", todoItemsTable)
+		if err := tx.Get(&done, query, itemId); err != nil {
+			return wrapError(err, "item")
+		}
+		completing = !done
+	}
+
+	setValues = append(setValues, "updated_at = now()")
+
+	setQuery := strings.Join(setValues, ", ")
+
+	query := fmt.Sprintf(`UPDATE %s ti SET %s WHERE ti.id = $%d RETURNING ti.parent_id`,
+		todoItemsTable, setQuery, len(args)+1)
+	args = append(args, itemId)
+
+	logrus.Debugf("updateQuery: %s", query)
+	logrus.Debugf("args: %s", args)
+
+	var parentId *int
+	if err := tx.Get(&parentId, query, args...); err != nil {
+		return wrapError(err, "item")
+	}
+
+	// switching auto completion on may complete the item itself, which in
+	// turn may complete its ancestors. The item itself is announced by the
+	// service.
+	if _, ok := patch["auto_complete"]; ok {
+		updated, err := syncCompletion(tx, itemId)
+		if err != nil {
+			return err
+		}
+		if len(updated) > 0 && updated[0] == itemId {
+			updated = updated[1:]
+		}
+		changes.add(domain.EventItemUpdated, listId, updated...)
+	}
+
+	if completing {
+		created, err := scheduleNextOccurrence(tx, userId, listId, itemId)
+		if err != nil {
+			return err
+		}
+		changes.add(domain.EventItemCreated, listId, created...)
+	}
+
+	if _, ok := patch["done"]; ok && parentId != nil {
+		updated, err := syncCompletion(tx, *parentId)
+		if err != nil {
+			return err
+		}
+		changes.add(domain.EventItemUpdated, listId, updated...)
+	}
+
+	return nil
+}
+
+func (r *TodoItemPostgres) CreateChild(userId, parentId int, item domain.TodoItem) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	role, listId, err := itemListRole(tx, userId, parentId)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkRole(role, domain.WriteRoles); err != nil {
+		return 0, err
+	}
+
+	if err := lockList(tx, listId); err != nil {
+		return 0, err
+	}
+
+	position, err := nextItemPosition(tx, listId, &parentId)
+	if err != nil {
+		return 0, err
+	}
+
+	var itemId int
+	createItemQuery := fmt.Sprintf(
+		`INSERT INTO %s (title, description, due_at, priority, auto_complete, recurrence, timezone, parent_id,
+					position)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		todoItemsTable,
+	)
+	err = tx.Get(&itemId, createItemQuery, item.Title, item.Description, item.DueAt, item.Priority,
+		item.AutoComplete, item.Recurrence, item.Timezone, parentId, position)
+	if err != nil {
+		return 0, wrapError(err, "item")
+	}
+
+	createListsItemsQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) VALUES ($1, $2)", listsItemsTable)
+	if _, err := tx.Exec(createListsItemsQuery, listId, itemId); err != nil {
+		return 0, err
+	}
+
+	// an open child reopens an auto completing parent
+	updated, err := syncCompletion(tx, parentId)
+	if err != nil {
+		return 0, err
+	}
+
+	var changes itemChanges
+	changes.add(domain.EventItemUpdated, listId, updated...)
+	if err := changes.emit(tx, userId); err != nil {
+		return 0, err
+	}
+
+	return itemId, tx.Commit()
+}
+
+// Move changes the position of the item among the items of its list that
+// have the same parent. When it goes to another list, its whole subtree
+// follows and it becomes a top level item of that list.
+func (r *TodoItemPostgres) Move(userId, itemId int, input domain.MoveInput) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	role, listId, err := itemListRole(tx, userId, itemId)
+	if err != nil {
+		return err
+	}
+	if err := checkRole(role, domain.WriteRoles); err != nil {
+		return err
+	}
+
+	targetListId := listId
+	if input.ListId != nil {
+		targetListId = *input.ListId
+	}
+
+	if err := lockLists(tx, listId, targetListId); err != nil {
+		return err
+	}
+
+	if targetListId != listId {
+		if err := requireListRole(tx, userId, targetListId, domain.WriteRoles...); err != nil {
+			return err
+		}
+	}
+
+	var parentId *int
+	query := fmt.Sprintf("SELECT parent_id FROM %s WHERE id = $1", todoItemsTable)
+	if err := tx.Get(&parentId, query, itemId); err != nil {
+		return wrapError(err, "item")
+	}
+
+	oldParentId := parentId
+	if targetListId != listId {
+		parentId = nil
+
+		query := fmt.Sprintf(
+			`WITH RECURSIVE subtree AS (
+						SELECT id FROM %[1]s WHERE id = $1
+						UNION ALL
+						SELECT c.id FROM %[1]s c INNER JOIN subtree s ON c.parent_id = s.id
+					)
+					UPDATE %[2]s SET list_id = $2 WHERE item_id IN (SELECT id FROM subtree)`,
+			todoItemsTable,
+			listsItemsTable,
+		)
+		if _, err := tx.Exec(query, itemId, targetListId); err != nil {
+			return err
+		}
+	}
+
+	var position string
+	if input.AfterId == nil && input.BeforeId == nil {
+		position, err = nextItemPosition(tx, targetListId, parentId)
+	} else {
+		position, err = movePosition(tx, itemSiblingsQuery, []interface{}{targetListId, parentId, itemId}, input)
+	}
+	if err != nil {
+		return err
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET position = $1, parent_id = $2, updated_at = now() WHERE id = $3",
+		todoItemsTable)
+	if _, err := tx.Exec(query, position, parentId, itemId); err != nil {
+		return err
+	}
+
+	// the old parent may now have only done children left
+	var changes itemChanges
+	if oldParentId != nil && parentId == nil {
+		updated, err := syncCompletion(tx, *oldParentId)
+		if err != nil {
+			return err
+		}
+		changes.add(domain.EventItemUpdated, listId, updated...)
+	}
+
+	if err := changes.emit(tx, userId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Copy creates a deep copy of the item with its subtree, the labels of the
+// user and the offset reminders. Without a target list the copy is placed right after the item.
+func (r *TodoItemPostgres) Copy(userId, itemId int, input domain.CopyItemInput) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	role, listId, err := itemListRole(tx, userId, itemId)
+	if err != nil {
+		return 0, err
+	}
+
+	targetListId := listId
+	if input.ListId != nil {
+		targetListId = *input.ListId
+	}
+
+	if err := lockLists(tx, listId, targetListId); err != nil {
+		return 0, err
+	}
+
+	// reading the item is enough to copy it to another list the user can write to
+	if targetListId == listId {
+		err = checkRole(role, domain.WriteRoles)
+	} else {
+		err = requireListRole(tx, userId, targetListId, domain.WriteRoles...)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	items, err := selectSubtree(tx, itemId)
+	if err != nil {
+		return 0, err
+	}
+
+	root := items[0]
+	var position string
+	if targetListId == listId {
+		position, err = movePosition(tx, itemSiblingsQuery,
+			[]interface{}{listId, root.ParentId, 0}, domain.MoveInput{AfterId: &root.Id})
+	} else {
+		root.ParentId = nil
+		position, err = nextItemPosition(tx, targetListId, nil)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	items[0].ParentId = root.ParentId
+	items[0].Position = position
+
+	ids, err := copyItems(tx, userId, targetListId, items)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, item := range items {
+		if err := copyReminders(tx, targetListId, item.Id, ids[item.Id]); err != nil {
+			return 0, err
+		}
+	}
+
+	// the copy of the item is announced by the service, the ones of its
+	// subtasks are not
+	var changes itemChanges
+	for _, item := range items[1:] {
+		changes.add(domain.EventItemCreated, targetListId, ids[item.Id])
+	}
+
+	if root.ParentId != nil {
+		updated, err := syncCompletion(tx, *root.ParentId)
+		if err != nil {
+			return 0, err
+		}
+		changes.add(domain.EventItemUpdated, targetListId, updated...)
+	}
+
+	if err := changes.emit(tx, userId); err != nil {
+		return 0, err
+	}
+
+	return ids[root.Id], tx.Commit()
+}
+
+func (r *TodoItemPostgres) GetChildren(userId, parentId int) ([]domain.TodoItem, error) {
+	if _, _, err := itemListRole(r.db, userId, parentId); err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.TodoItem, 0)
+	query := fmt.Sprintf("SELECT %s FROM %s ti WHERE ti.parent_id = $1 ORDER BY ti.position, ti.id",
+		itemColumns, todoItemsTable)
+	if err := r.db.Select(&items, query, parentId); err != nil {
+		return nil, err
+	}
+
+	if err := loadItemLabels(r.db, userId, items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// ReorderChildren sets the positions of the children to their order in ids,
+// which must name every child of the item exactly once.
+func (r *TodoItemPostgres) ReorderChildren(userId, parentId int, ids []int) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := requireItemRole(tx, userId, parentId, domain.WriteRoles...); err != nil {
+		return err
+	}
+
+	var childIds []int64
+	query := fmt.Sprintf("SELECT id FROM %s WHERE parent_id = $1 FOR UPDATE", todoItemsTable)
+	if err := tx.Select(&childIds, query, parentId); err != nil {
+		return err
+	}
+
+	if !sameIds(childIds, ids) {
+		return domain.NewError(domain.ErrValidation, "ids must contain every child of the item exactly once")
+	}
+
+	childIds = make([]int64, len(ids))
+	for i, id := range ids {
+		childIds[i] = int64(id)
+	}
+
+	query = fmt.Sprintf(
+		`UPDATE %s ti SET position = o.position, updated_at = now()
+				FROM unnest($1::int[], $2::text[]) AS o(id, position) WHERE ti.id = o.id`,
+		todoItemsTable,
+	)
+	if _, err := tx.Exec(query, pq.Array(childIds), pq.Array(rank.Sequence(len(ids)))); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *TodoItemPostgres) Delete(userId, itemId int) error {
+// BulkCreate appends the items to the list in a single transaction.
+func (r *TodoItemPostgres) BulkCreate(userId, listId int, items []domain.TodoItem,
+	atomic bool) (domain.BulkResponse, error) {
+	return r.bulk(userId, listId, len(items), atomic, func(tx *sqlx.Tx, i int, changes *itemChanges) (int, error) {
+		return createItem(tx, listId, items[i])
+	})
+}
+
+// BulkUpdate applies the patch to the items of the list in a single
+// transaction.
+func (r *TodoItemPostgres) BulkUpdate(userId, listId int, ids []int, patch domain.Patch,
+	atomic bool) (domain.BulkResponse, error) {
+	return r.bulk(userId, listId, len(ids), atomic, func(tx *sqlx.Tx, i int, changes *itemChanges) (int, error) {
+		if err := requireItemInList(tx, listId, ids[i]); err != nil {
+			return ids[i], err
+		}
+
+		return ids[i], updateItem(tx, userId, ids[i], patch, nil, changes)
+	})
+}
+
+// BulkDelete deletes the items of the list in a single transaction.
+func (r *TodoItemPostgres) BulkDelete(userId, listId int, ids []int, atomic bool) (domain.BulkResponse, error) {
+	return r.bulk(userId, listId, len(ids), atomic, func(tx *sqlx.Tx, i int, changes *itemChanges) (int, error) {
+		if err := requireItemInList(tx, listId, ids[i]); err != nil {
+			return ids[i], err
+		}
+
+		return ids[i], deleteItem(tx, userId, ids[i], nil, changes)
+	})
+}
+
+// ClearCompleted deletes the done items of the list along with their
+// subtasks and returns their ids.
+func (r *TodoItemPostgres) ClearCompleted(userId, listId int) ([]int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := requireListRole(tx, userId, listId, domain.WriteRoles...); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0)
 	query := fmt.Sprintf(
-		`DELETE FROM %s ti USING %s li, %s ul 
-				WHERE ti.id = li.item_id AND li.list_id = ul.list_id 
-				AND ul.user_id = $1 AND ti.id = $2`,
+		`SELECT ti.id FROM %s ti INNER JOIN %s li ON li.item_id = ti.id
+				WHERE li.list_id = $1 AND ti.done ORDER BY ti.id FOR UPDATE OF ti`,
 		todoItemsTable,
 		listsItemsTable,
-		usersListsTable,
 	)
+	if err := tx.Select(&ids, query, listId); err != nil {
+		return nil, err
+	}
+
+	var changes itemChanges
+	for _, id := range ids {
+		// subtasks are gone already when their parent was deleted before
+		err := deleteItem(tx, userId, id, nil, &changes)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	// the done items are announced by the service
+	changes.drop(domain.EventItemDeleted, ids...)
+	if err := changes.emit(tx, userId); err != nil {
+		return nil, err
+	}
 
-	_, err := r.db.Exec(query, userId, itemId)
-	return err
+	return ids, tx.Commit()
 }
 
-func (r *TodoItemPostgres) Update(userId, itemId int, input domain.UpdateItemInput) error {
-	setValues := make([]string, 0)
-	args := make([]interface{}, 0)
-	argId := 1
+// bulk runs apply for every entry of a bulk request on the list. In best
+// effort mode each entry runs under a savepoint, so that a failing entry is
+// undone alone. An atomic request stops at the first failure and is rolled
+// back. Only domain errors fail an entry, any other error fails the request.
+func (r *TodoItemPostgres) bulk(userId, listId, entries int, atomic bool,
+	apply func(tx *sqlx.Tx, i int, changes *itemChanges) (int, error)) (domain.BulkResponse, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return domain.BulkResponse{}, err
+	}
+	defer tx.Rollback()
+
+	if err := requireListRole(tx, userId, listId, domain.WriteRoles...); err != nil {
+		return domain.BulkResponse{}, err
+	}
+
+	response := domain.BulkResponse{Results: make([]domain.BulkResult, entries)}
+	for i := range response.Results {
+		response.Results[i] = domain.BulkResult{Index: i, Status: domain.BulkSkipped}
+	}
+
+	var changes itemChanges
+	for i := 0; i < entries; i++ {
+		mark := changes.mark()
+		if !atomic {
+			if _, err := tx.Exec("SAVEPOINT bulk_entry"); err != nil {
+				return domain.BulkResponse{}, err
+			}
+		}
+
+		id, err := apply(tx, i, &changes)
+
+		var domainErr *domain.Error
+		if err != nil && !errors.As(err, &domainErr) {
+			return domain.BulkResponse{}, err
+		}
+
+		if err != nil {
+			response.Results[i] = domain.BulkResult{Index: i, Id: id, Status: domain.BulkFailed, Error: domainErr.Message}
+			if atomic {
+				return rollbackBulk(response, i), nil
+			}
+
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT bulk_entry"); err != nil {
+				return domain.BulkResponse{}, err
+			}
+			changes.reset(mark)
+			continue
+		}
 
-	v := reflect.ValueOf(input)
-	for i := 0; i < v.NumField(); i++ {
-		key := v.Type().Field(i).Name
-		if !v.Field(i).IsNil() {
-			value := v.Field(i).Elem().Interface()
-			setValues = append(setValues, fmt.Sprintf("%s = $%d", key, argId))
-			args = append(args, value)
-			argId++
+		response.Results[i] = domain.BulkResult{Index: i, Id: id, Status: domain.BulkApplied}
+		if !atomic {
+			if _, err := tx.Exec("RELEASE SAVEPOINT bulk_entry"); err != nil {
+				return domain.BulkResponse{}, err
+			}
 		}
 	}
 
-	setQuery := strings.Join(setValues, ", ")
+	if err := changes.emit(tx, userId); err != nil {
+		return domain.BulkResponse{}, err
+	}
+
+	response.Committed = true
 
+	return response, tx.Commit()
+}
+
+// rollbackBulk reports the entries applied before the one that failed as
+// skipped, since they are rolled back with it.
+func rollbackBulk(response domain.BulkResponse, failed int) domain.BulkResponse {
+	for i := 0; i < failed; i++ {
+		response.Results[i] = domain.BulkResult{Index: i, Status: domain.BulkSkipped}
+	}
+
+	return response
+}
+
+func requireItemInList(tx *sqlx.Tx, listId, itemId int) error {
+	var exists bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE list_id = $1 AND item_id = $2)", listsItemsTable)
+	if err := tx.Get(&exists, query, listId, itemId); err != nil {
+		return err
+	}
+
+	if !exists {
+		return domain.NewError(domain.ErrNotFound, "item not found")
+	}
+
+	return nil
+}
+
+// syncCompletion updates the done state of an auto completing item from its
+// children and walks up the tree for as long as something changes. It returns
+// the ids of the items it changed, from the bottom up.
+func syncCompletion(tx *sqlx.Tx, itemId int) ([]int, error) {
 	query := fmt.Sprintf(
-		`UPDATE %s ti SET %s FROM %s li, %s ul 
-				WHERE ti.id = li.item_id AND li.list_id = ul.list_id 
-				AND ul.user_id = $%d AND ti.id = $%d`,
+		`WITH state AS (
+					SELECT NOT EXISTS (SELECT 1 FROM %[1]s c WHERE c.parent_id = $1 AND NOT c.done) AS done
+				)
+				UPDATE %[1]s ti SET done = state.done,
+					completed_at = CASE WHEN state.done THEN COALESCE(ti.completed_at, now()) ELSE NULL END,
+					updated_at = now()
+				FROM state
+				WHERE ti.id = $1 AND ti.auto_complete AND ti.done <> state.done
+					AND EXISTS (SELECT 1 FROM %[1]s c WHERE c.parent_id = $1)
+				RETURNING ti.parent_id`,
 		todoItemsTable,
-		setQuery,
-		listsItemsTable,
-		usersListsTable,
-		argId,
-		argId+1,
 	)
-	args = append(args, userId, itemId)
 
-	logrus.Debugf("updateQuery: %s", query)
-	logrus.Debugf("args: %s", args)
+	updated := make([]int, 0)
+	for {
+		var parentId *int
+		err := tx.Get(&parentId, query, itemId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return updated, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		updated = append(updated, itemId)
+
+		if parentId == nil {
+			return updated, nil
+		}
+		itemId = *parentId
+	}
+}
+
+// selectDescendantIds returns the ids of the items below the item.
+func selectDescendantIds(tx *sqlx.Tx, itemId int) ([]int, error) {
+	ids := make([]int, 0)
+	query := fmt.Sprintf(
+		`WITH RECURSIVE subtree AS (
+					SELECT id FROM %[1]s WHERE parent_id = $1
+					UNION ALL
+					SELECT c.id FROM %[1]s c INNER JOIN subtree s ON c.parent_id = s.id
+				)
+				SELECT id FROM subtree`,
+		todoItemsTable,
+	)
+	err := tx.Select(&ids, query, itemId)
+
+	return ids, err
+}
+
+// selectSubtree returns the item followed by its descendants, parents before
+// their children.
+func selectSubtree(tx *sqlx.Tx, itemId int) ([]domain.TodoItem, error) {
+	items := make([]domain.TodoItem, 0)
+	query := fmt.Sprintf(
+		`WITH RECURSIVE subtree AS (
+					SELECT ti.*, 0 AS depth FROM %[1]s ti WHERE ti.id = $1
+					UNION ALL
+					SELECT c.*, s.depth + 1 FROM %[1]s c INNER JOIN subtree s ON c.parent_id = s.id
+				)
+				SELECT %[2]s FROM subtree ti ORDER BY ti.depth, ti.position, ti.id`,
+		todoItemsTable,
+		itemColumns,
+	)
+	if err := tx.Select(&items, query, itemId); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, domain.NewError(domain.ErrNotFound, "item not found")
+	}
+
+	return items, nil
+}
+
+// scheduleNextOccurrence creates the next occurrence of a recurring item that
+// was just completed, right after it and with its subtree reopened. The due
+// dates of the subtree move along with the one of the item. The completed
+// item leaves the series, so that reopening and completing it again does not
+// repeat it twice. Reminders relative to the due date are carried over. It
+// returns the ids of the items it created.
+func scheduleNextOccurrence(tx *sqlx.Tx, userId, listId, itemId int) ([]int, error) {
+	items, err := selectSubtree(tx, itemId)
+	if err != nil {
+		return nil, err
+	}
+
+	root := items[0]
+	if root.Recurrence == "" {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET recurrence = '' WHERE id = $1", todoItemsTable)
+	if _, err := tx.Exec(query, itemId); err != nil {
+		return nil, err
+	}
+
+	rule, err := recurrence.Parse(root.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(root.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	completedAt := time.Now()
+	if root.CompletedAt != nil {
+		completedAt = *root.CompletedAt
+	}
+
+	next, nextRule, err := rule.Next(root.DueAt, completedAt, loc)
+	if errors.Is(err, recurrence.ErrNoOccurrence) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	next = next.UTC()
+
+	if err := lockList(tx, listId); err != nil {
+		return nil, err
+	}
+
+	position, err := movePosition(tx, itemSiblingsQuery,
+		[]interface{}{listId, root.ParentId, 0}, domain.MoveInput{AfterId: &root.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	var shift time.Duration
+	if root.DueAt != nil {
+		shift = next.Sub(*root.DueAt)
+	}
+
+	for i := range items {
+		items[i].Done = false
+		items[i].CompletedAt = nil
+		if i > 0 && items[i].DueAt != nil && shift != 0 {
+			dueAt := items[i].DueAt.Add(shift)
+			items[i].DueAt = &dueAt
+		}
+	}
+
+	items[0].DueAt = &next
+	items[0].Recurrence = nextRule.String()
+	items[0].Position = position
+
+	ids, err := copyItems(tx, userId, listId, items)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := copyReminders(tx, listId, root.Id, ids[root.Id]); err != nil {
+		return nil, err
+	}
+
+	created := make([]int, len(items))
+	for i, item := range items {
+		created[i] = ids[item.Id]
+	}
+
+	return created, nil
+}
+
+// itemSiblingsQuery selects the items of list $1 with parent $2, except $3.
+var itemSiblingsQuery = fmt.Sprintf(
+	`SELECT ti.id, ti.position FROM %s ti INNER JOIN %s li ON li.item_id = ti.id
+			WHERE li.list_id = $1 AND ti.parent_id IS NOT DISTINCT FROM $2 AND ti.id <> $3`,
+	todoItemsTable,
+	listsItemsTable,
+)
+
+// copyItems inserts copies of the items into the list, parents have to come
+// before their children. Items whose parent is not copied along keep their
+// ParentId. The labels of the user are copied as well. It returns the ids of
+// the copies by the ids of the originals.
+func copyItems(tx *sqlx.Tx, userId, listId int, items []domain.TodoItem) (map[int]int, error) {
+	ids := make(map[int]int, len(items))
+	oldIds := make([]int64, 0, len(items))
+	newIds := make([]int64, 0, len(items))
+
+	createItemQuery := fmt.Sprintf(
+		`INSERT INTO %s (title, description, done, due_at, priority, completed_at, parent_id, position, auto_complete,
+					recurrence, timezone)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+		todoItemsTable,
+	)
+	createListsItemsQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) VALUES ($1, $2)", listsItemsTable)
+
+	for _, item := range items {
+		parentId := item.ParentId
+		if parentId != nil {
+			if id, ok := ids[*parentId]; ok {
+				parentId = &id
+			}
+		}
+
+		var id int
+		err := tx.Get(&id, createItemQuery, item.Title, item.Description, item.Done, item.DueAt, item.Priority,
+			item.CompletedAt, parentId, item.Position, item.AutoComplete, item.Recurrence, item.Timezone)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.Exec(createListsItemsQuery, listId, id); err != nil {
+			return nil, err
+		}
+
+		ids[item.Id] = id
+		oldIds = append(oldIds, int64(item.Id))
+		newIds = append(newIds, int64(id))
+	}
+
+	copyLabelsQuery := fmt.Sprintf(
+		`INSERT INTO %s (item_id, label_id)
+				SELECT m.new_id, il.label_id FROM unnest($1::int[], $2::int[]) AS m(old_id, new_id)
+					INNER JOIN %s il ON il.item_id = m.old_id
+					INNER JOIN %s l ON l.id = il.label_id
+				WHERE l.user_id = $3`,
+		itemsLabelsTable,
+		itemsLabelsTable,
+		labelsTable,
+	)
+	if _, err := tx.Exec(copyLabelsQuery, pq.Array(oldIds), pq.Array(newIds), userId); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
 
-	_, err := r.db.Exec(query, args...)
+func sameIds(current []int64, ids []int) bool {
+	if len(current) != len(ids) {
+		return false
+	}
+
+	seen := make(map[int64]bool, len(current))
+	for _, id := range current {
+		seen[id] = true
+	}
+
+	for _, id := range ids {
+		if !seen[int64(id)] {
+			return false
+		}
+		delete(seen, int64(id))
+	}
 
-	return err
+	return true
 }
@@ -8,9 +8,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"log"
 	"testing"
+	"time"
 )
 
 func TestTodoItemPostgres_Create(t *testing.T) {
+	description := "test description"
+
 	mockDB, mock, err := sqlmock.New()
 	if err != nil {
 		log.Fatal(err)
@@ -21,6 +24,7 @@ func TestTodoItemPostgres_Create(t *testing.T) {
 	r := NewTodoItemPostgres(db)
 
 	type args struct {
+		userId int
 		listId int
 		item   domain.TodoItem
 	}
@@ -36,19 +40,33 @@ func TestTodoItemPostgres_Create(t *testing.T) {
 		{
 			name: "OK",
 			args: args{
+				userId: 1,
 				listId: 1,
 				item: domain.TodoItem{
 					Title:       "test title",
-					Description: "test description",
+					Description: &description,
 				},
 			},
 			id: 2,
 			mockBehavior: func(args args, id int) {
 				mock.ExpectBegin()
 
+				mock.ExpectQuery("SELECT role FROM users_lists").
+					WithArgs(args.userId, args.listId).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleEditor))
+
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(args.listId).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectQuery(`SELECT MAX\(ti.position\)`).
+					WithArgs(args.listId, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow("i"))
+
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
 				mock.ExpectQuery("INSERT INTO todo_item").
-					WithArgs(args.item.Title, args.item.Description).
+					WithArgs(args.item.Title, args.item.Description, args.item.DueAt, args.item.Priority, args.item.AutoComplete,
+						args.item.Recurrence, args.item.Timezone, "j").
 					WillReturnRows(rows)
 
 				mock.ExpectExec("INSERT INTO lists_items").
@@ -61,19 +79,33 @@ func TestTodoItemPostgres_Create(t *testing.T) {
 		{
 			name: "Empty Fields",
 			args: args{
+				userId: 1,
 				listId: 1,
 				item: domain.TodoItem{
 					Title:       "",
-					Description: "test description",
+					Description: &description,
 				},
 			},
 			mockBehavior: func(args args, id int) {
 				mock.ExpectBegin()
 
+				mock.ExpectQuery("SELECT role FROM users_lists").
+					WithArgs(args.userId, args.listId).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleEditor))
+
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(args.listId).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectQuery(`SELECT MAX\(ti.position\)`).
+					WithArgs(args.listId, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow("i"))
+
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(id).
 					RowError(1, errors.New("some error"))
 				mock.ExpectQuery("INSERT INTO todo_item").
-					WithArgs(args.item.Title, args.item.Description).
+					WithArgs(args.item.Title, args.item.Description, args.item.DueAt, args.item.Priority, args.item.AutoComplete,
+						args.item.Recurrence, args.item.Timezone, "j").
 					WillReturnRows(rows)
 
 				mock.ExpectRollback()
@@ -83,19 +115,33 @@ func TestTodoItemPostgres_Create(t *testing.T) {
 		{
 			name: "2nd Insert Error",
 			args: args{
+				userId: 1,
 				listId: 1,
 				item: domain.TodoItem{
 					Title:       "test title",
-					Description: "test description",
+					Description: &description,
 				},
 			},
 			id: 2,
 			mockBehavior: func(args args, id int) {
 				mock.ExpectBegin()
 
+				mock.ExpectQuery("SELECT role FROM users_lists").
+					WithArgs(args.userId, args.listId).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleEditor))
+
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(args.listId).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectQuery(`SELECT MAX\(ti.position\)`).
+					WithArgs(args.listId, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow("i"))
+
 				rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
 				mock.ExpectQuery("INSERT INTO todo_item").
-					WithArgs(args.item.Title, args.item.Description).
+					WithArgs(args.item.Title, args.item.Description, args.item.DueAt, args.item.Priority, args.item.AutoComplete,
+						args.item.Recurrence, args.item.Timezone, "j").
 					WillReturnRows(rows)
 
 				mock.ExpectExec("INSERT INTO lists_items").
@@ -106,13 +152,34 @@ func TestTodoItemPostgres_Create(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Viewer",
+			args: args{
+				userId: 1,
+				listId: 1,
+				item: domain.TodoItem{
+					Title:       "test title",
+					Description: &description,
+				},
+			},
+			mockBehavior: func(args args, id int) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("SELECT role FROM users_lists").
+					WithArgs(args.userId, args.listId).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleViewer))
+
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehavior(testCase.args, testCase.id)
 
-			got, err := r.Create(testCase.args.listId, testCase.args.item)
+			got, err := r.Create(testCase.args.userId, testCase.args.listId, testCase.args.item)
 			if testCase.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -122,3 +189,814 @@ func TestTodoItemPostgres_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestTodoItemPostgres_Update(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewTodoItemPostgres(db)
+
+	type args struct {
+		userId int
+		itemId int
+		patch  domain.Patch
+	}
+	type mockBehavior func(args args)
+
+	itemColumns := []string{"id", "title", "description", "done", "due_at", "priority", "created_at", "updated_at",
+		"completed_at", "parent_id", "position", "auto_complete", "recurrence", "timezone"}
+	now := time.Date(2021, 3, 22, 10, 0, 0, 0, time.UTC)
+	due := time.Date(2021, 3, 22, 8, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			args: args{
+				userId: 1,
+				itemId: 2,
+				patch:  domain.Patch{"title": "new title", "priority": domain.PriorityHigh},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(args.userId, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleEditor, 1))
+
+				mock.ExpectQuery(`UPDATE todo_items ti SET title = \$1, priority = \$2, updated_at = now\(\) WHERE ti.id = \$3`).
+					WithArgs("new title", domain.PriorityHigh, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(nil))
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Clear Description",
+			args: args{
+				userId: 1,
+				itemId: 2,
+				patch:  domain.Patch{"description": nil, "due_at": nil},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(args.userId, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleEditor, 1))
+
+				mock.ExpectQuery(`UPDATE todo_items ti SET description = \$1, due_at = \$2, updated_at = now\(\) WHERE ti.id = \$3`).
+					WithArgs(nil, nil, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(nil))
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Unknown Field",
+			args: args{
+				userId: 1,
+				itemId: 2,
+				patch:  domain.Patch{"created_at": now},
+			},
+			mockBehavior: func(args args) {},
+			wantErr:      true,
+		},
+		{
+			name: "Done",
+			args: args{
+				userId: 1,
+				itemId: 2,
+				patch:  domain.Patch{"done": true},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(args.userId, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleOwner, 1))
+
+				mock.ExpectQuery("SELECT done FROM todo_items WHERE id = \\$1 FOR UPDATE").
+					WithArgs(args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"done"}).AddRow(false))
+
+				mock.ExpectQuery(`UPDATE todo_items ti SET done = \$1, completed_at = CASE WHEN \$1 THEN COALESCE\(ti.completed_at, now\(\)\) ELSE NULL END, updated_at = now\(\) WHERE ti.id = \$2`).
+					WithArgs(true, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(5))
+
+				mock.ExpectQuery("WITH RECURSIVE subtree").
+					WithArgs(args.itemId).
+					WillReturnRows(sqlmock.NewRows(itemColumns).
+						AddRow(args.itemId, "chore", "", true, nil, domain.PriorityNone, now, now, now, 5, "i", false, "",
+							domain.DefaultTimezone))
+
+				// the parent gets completed, the grandparent does not change
+				mock.ExpectQuery("WITH state AS").
+					WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(9))
+				mock.ExpectQuery("WITH state AS").
+					WithArgs(9).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}))
+
+				expectEvent(mock, domain.EventItemUpdated, 5)
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Recurring",
+			args: args{
+				userId: 1,
+				itemId: 2,
+				patch:  domain.Patch{"done": true},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(args.userId, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleEditor, 1))
+
+				mock.ExpectQuery("SELECT done FROM todo_items").
+					WithArgs(args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"done"}).AddRow(false))
+
+				mock.ExpectQuery("UPDATE todo_items ti SET done").
+					WithArgs(true, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(nil))
+
+				// a weekly chore with a subtask due the day before
+				mock.ExpectQuery("WITH RECURSIVE subtree").
+					WithArgs(args.itemId).
+					WillReturnRows(sqlmock.NewRows(itemColumns).
+						AddRow(args.itemId, "chore", "", true, due, domain.PriorityNone, now, now, now, nil, "i", false,
+							"FREQ=WEEKLY;COUNT=3", "Europe/Berlin").
+						AddRow(3, "subtask", "", true, due.AddDate(0, 0, -1), domain.PriorityNone, now, now, now,
+							args.itemId, "i", false, "", domain.DefaultTimezone))
+
+				mock.ExpectExec("UPDATE todo_items SET recurrence = ''").
+					WithArgs(args.itemId).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectQuery(`SELECT s.position FROM`).
+					WithArgs(1, nil, 0, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow("i"))
+				mock.ExpectQuery(`SELECT MIN\(s.position\)`).
+					WithArgs(1, nil, 0, "i").
+					WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow(nil))
+
+				// 9:00 in Berlin across the switch to summer time
+				next := time.Date(2021, 3, 29, 7, 0, 0, 0, time.UTC)
+				mock.ExpectQuery("INSERT INTO todo_items").
+					WithArgs("chore", "", false, &next, domain.PriorityNone, nil, nil, "r", false,
+						"FREQ=WEEKLY;BYHOUR=9;BYMINUTE=0;COUNT=2", "Europe/Berlin").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+				mock.ExpectExec("INSERT INTO lists_items").
+					WithArgs(1, 10).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				subtaskDue := next.AddDate(0, 0, -1)
+				mock.ExpectQuery("INSERT INTO todo_items").
+					WithArgs("subtask", "", false, &subtaskDue, domain.PriorityNone, nil, 10, "i", false, "",
+						domain.DefaultTimezone).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11))
+				mock.ExpectExec("INSERT INTO lists_items").
+					WithArgs(1, 11).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectExec("INSERT INTO items_labels").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), args.userId).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.ExpectExec("INSERT INTO reminders").
+					WithArgs(args.itemId, 10, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				// the next occurrence and its subtask
+				expectEvent(mock, domain.EventItemCreated, 10)
+				expectEvent(mock, domain.EventItemCreated, 11)
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Already Done",
+			args: args{
+				userId: 1,
+				itemId: 2,
+				patch:  domain.Patch{"done": true},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(args.userId, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleEditor, 1))
+
+				mock.ExpectQuery("SELECT done FROM todo_items").
+					WithArgs(args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"done"}).AddRow(true))
+
+				mock.ExpectQuery("UPDATE todo_items ti SET done").
+					WithArgs(true, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(nil))
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Viewer",
+			args: args{
+				userId: 1,
+				itemId: 2,
+				patch:  domain.Patch{"done": true},
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(args.userId, args.itemId).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleViewer, 1))
+
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args)
+
+			err := r.Update(testCase.args.userId, testCase.args.itemId, testCase.args.patch, nil)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTodoItemPostgres_GetAll(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewTodoItemPostgres(db)
+
+	done := false
+	columns := []string{"id", "title", "description", "done", "priority"}
+
+	testTable := []struct {
+		name           string
+		filter         domain.ItemFilter
+		mockBehavior   func(filter domain.ItemFilter)
+		expectedIds    []int
+		expectedLabels map[int][]domain.Label
+		nextCursor     *cursor
+		wantErr        bool
+	}{
+		{
+			name: "Next Page",
+			filter: domain.ItemFilter{
+				Page:       domain.Page{Sort: domain.SortByPriority, Order: domain.SortDesc, Limit: 2},
+				Done:       &done,
+				Priorities: []string{domain.PriorityHigh, domain.PriorityUrgent},
+				Query:      "50%_off",
+			},
+			mockBehavior: func(filter domain.ItemFilter) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(5, "a", "", false, domain.PriorityUrgent).
+					AddRow(3, "b", "", false, domain.PriorityHigh).
+					AddRow(2, "c", "", false, domain.PriorityHigh)
+
+				mock.ExpectQuery(`WHERE li.list_id = \$1 AND ti.parent_id IS NULL AND ul.user_id = \$2 AND ti.done = \$3 `+
+					`AND ti.priority = ANY\(\$4\) AND \(ti.title ILIKE \$5 OR ti.description ILIKE \$6\) `+
+					`ORDER BY CASE ti.priority .* END DESC, ti.id DESC LIMIT 3`).
+					WithArgs(1, 1, false, sqlmock.AnyArg(), `%50\%\_off%`, `%50\%\_off%`).
+					WillReturnRows(rows)
+
+				mock.ExpectQuery("SELECT il.item_id, l.id, l.name, l.color FROM items_labels").
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnRows(sqlmock.NewRows([]string{"item_id", "id", "name", "color"}).
+						AddRow(5, 7, "@work", "#ff0000"))
+			},
+			expectedIds: []int{5, 3},
+			expectedLabels: map[int][]domain.Label{
+				5: {{Id: 7, Name: "@work", Color: "#ff0000"}},
+				3: {},
+			},
+			nextCursor: &cursor{Sort: domain.SortByPriority, Order: domain.SortDesc, Value: "3", Id: 3},
+		},
+		{
+			name: "From Cursor",
+			filter: domain.ItemFilter{
+				Page: domain.Page{
+					Sort:  domain.SortById,
+					Order: domain.SortAsc,
+					Limit: 2,
+					Cursor: encodeCursor(cursor{
+						Sort:  domain.SortById,
+						Order: domain.SortAsc,
+						Value: "3",
+						Id:    3,
+					}),
+				},
+			},
+			mockBehavior: func(filter domain.ItemFilter) {
+				rows := sqlmock.NewRows(columns).
+					AddRow(4, "a", "", false, domain.PriorityNone)
+
+				mock.ExpectQuery(`WHERE li.list_id = \$1 AND ti.parent_id IS NULL AND ul.user_id = \$2 `+
+					`AND \(ti.id, ti.id\) > \(\$3::int, \$4\) ORDER BY ti.id ASC, ti.id ASC LIMIT 3`).
+					WithArgs(1, 1, "3", 3).
+					WillReturnRows(rows)
+
+				mock.ExpectQuery("SELECT il.item_id, l.id, l.name, l.color FROM items_labels").
+					WithArgs(sqlmock.AnyArg(), 1).
+					WillReturnRows(sqlmock.NewRows([]string{"item_id", "id", "name", "color"}))
+			},
+			expectedIds: []int{4},
+		},
+		{
+			name: "Cursor Of Another Sort",
+			filter: domain.ItemFilter{
+				Page: domain.Page{
+					Sort:   domain.SortByTitle,
+					Order:  domain.SortAsc,
+					Limit:  2,
+					Cursor: encodeCursor(cursor{Sort: domain.SortById, Order: domain.SortAsc, Value: "3", Id: 3}),
+				},
+			},
+			mockBehavior: func(filter domain.ItemFilter) {},
+			wantErr:      true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.filter)
+
+			got, err := r.GetAll(1, 1, testCase.filter)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, domain.ErrValidation)
+				return
+			}
+
+			assert.NoError(t, err)
+			ids := make([]int, len(got.Data))
+			for i, item := range got.Data {
+				ids[i] = item.Id
+			}
+			assert.Equal(t, testCase.expectedIds, ids)
+
+			for _, item := range got.Data {
+				if labels, ok := testCase.expectedLabels[item.Id]; ok {
+					assert.Equal(t, labels, item.Labels)
+				}
+			}
+
+			if testCase.nextCursor == nil {
+				assert.Empty(t, got.NextCursor)
+			} else {
+				assert.Equal(t, encodeCursor(*testCase.nextCursor), got.NextCursor)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTodoItemPostgres_ReorderChildren(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewTodoItemPostgres(db)
+
+	testTable := []struct {
+		name         string
+		ids          []int
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name: "OK",
+			ids:  []int{4, 3},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleEditor, 1))
+				mock.ExpectQuery("SELECT id FROM todo_items WHERE parent_id = \\$1 FOR UPDATE").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(4))
+				mock.ExpectExec("UPDATE todo_items ti SET position").
+					WithArgs("{4,3}", `{"c","o"}`).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Missing Child",
+			ids:  []int{4, 4},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleEditor, 1))
+				mock.ExpectQuery("SELECT id FROM todo_items WHERE parent_id = \\$1 FOR UPDATE").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(4))
+				mock.ExpectRollback()
+			},
+			wantErr: domain.ErrValidation,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			err := r.ReorderChildren(1, 2, testCase.ids)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTodoItemPostgres_Move(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewTodoItemPostgres(db)
+
+	afterId, beforeId, listId := 3, 4, 5
+
+	testTable := []struct {
+		name         string
+		input        domain.MoveInput
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name:  "After",
+			input: domain.MoveInput{AfterId: &afterId},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleEditor, 1))
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT parent_id FROM todo_items").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(nil))
+				mock.ExpectQuery(`SELECT s.position FROM \(.*\) s WHERE s.id = \$4`).
+					WithArgs(1, nil, 2, afterId).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow("i"))
+				mock.ExpectQuery(`SELECT MIN\(s.position\) FROM \(.*\) s WHERE s.position > \$4`).
+					WithArgs(1, nil, 2, "i").
+					WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow("r"))
+				mock.ExpectExec("UPDATE todo_items SET position").
+					WithArgs("m", nil, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:  "To Another List",
+			input: domain.MoveInput{ListId: &listId},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleEditor, 1))
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(listId).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT role FROM users_lists WHERE user_id = \$1 AND list_id = \$2 FOR SHARE`).
+					WithArgs(1, listId).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleEditor))
+				mock.ExpectQuery("SELECT parent_id FROM todo_items").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(7))
+				mock.ExpectExec("WITH RECURSIVE subtree AS .* UPDATE lists_items SET list_id = \\$2").
+					WithArgs(2, listId).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectQuery(`SELECT MAX\(ti.position\)`).
+					WithArgs(listId, nil).
+					WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+				mock.ExpectExec("UPDATE todo_items SET position").
+					WithArgs("i", nil, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("WITH state AS").
+					WithArgs(7).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:  "To A Read-Only List",
+			input: domain.MoveInput{ListId: &listId},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleEditor, 1))
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(listId).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT role FROM users_lists WHERE user_id = \$1 AND list_id = \$2 FOR SHARE`).
+					WithArgs(1, listId).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleViewer))
+				mock.ExpectRollback()
+			},
+			wantErr: domain.ErrForbidden,
+		},
+		{
+			name:  "Not A Sibling",
+			input: domain.MoveInput{AfterId: &afterId, BeforeId: &beforeId},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleEditor, 1))
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("SELECT parent_id FROM todo_items").
+					WithArgs(2).
+					WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(7))
+				mock.ExpectQuery(`SELECT s.position FROM \(.*\) s WHERE s.id = \$4`).
+					WithArgs(1, 7, 2, afterId).
+					WillReturnRows(sqlmock.NewRows([]string{"position"}))
+				mock.ExpectRollback()
+			},
+			wantErr: domain.ErrValidation,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			err := r.Move(1, 2, testCase.input)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTodoItemPostgres_Copy(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewTodoItemPostgres(db)
+
+	listId := 5
+
+	testTable := []struct {
+		name         string
+		input        domain.CopyItemInput
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name: "Read-Only Source",
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleViewer, 1))
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectRollback()
+			},
+			wantErr: domain.ErrForbidden,
+		},
+		{
+			name:  "To A Read-Only List",
+			input: domain.CopyItemInput{ListId: &listId},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleViewer, 1))
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(listId).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT role FROM users_lists WHERE user_id = \$1 AND list_id = \$2 FOR SHARE`).
+					WithArgs(1, listId).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleViewer))
+				mock.ExpectRollback()
+			},
+			wantErr: domain.ErrForbidden,
+		},
+		{
+			name:  "To A List Not Shared",
+			input: domain.CopyItemInput{ListId: &listId},
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+					WithArgs(1, 2).
+					WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleViewer, 1))
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("SELECT id FROM todo_lists").
+					WithArgs(listId).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(`SELECT role FROM users_lists WHERE user_id = \$1 AND list_id = \$2 FOR SHARE`).
+					WithArgs(1, listId).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}))
+				mock.ExpectRollback()
+			},
+			wantErr: domain.ErrNotFound,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			_, err := r.Copy(1, 2, testCase.input)
+			assert.ErrorIs(t, err, testCase.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTodoItemPostgres_BulkDelete(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewTodoItemPostgres(db)
+
+	expectDelete := func(itemId int, descendants ...int) {
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM lists_items WHERE list_id = \$1 AND item_id = \$2\)`).
+			WithArgs(1, itemId).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery("SELECT ul.role, ul.list_id FROM users_lists").
+			WithArgs(1, itemId).
+			WillReturnRows(sqlmock.NewRows([]string{"role", "list_id"}).AddRow(domain.RoleOwner, 1))
+		rows := sqlmock.NewRows([]string{"id"})
+		for _, id := range descendants {
+			rows.AddRow(id)
+		}
+		mock.ExpectQuery("WITH RECURSIVE subtree AS .* SELECT id FROM subtree").
+			WithArgs(itemId).
+			WillReturnRows(rows)
+		mock.ExpectQuery(`DELETE FROM todo_items WHERE id = \$1 RETURNING parent_id`).
+			WithArgs(itemId).
+			WillReturnRows(sqlmock.NewRows([]string{"parent_id"}).AddRow(nil))
+	}
+	expectMissing := func(itemId int) {
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM lists_items`).
+			WithArgs(1, itemId).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	}
+
+	type mockBehavior func()
+
+	testTable := []struct {
+		name         string
+		atomic       bool
+		mockBehavior mockBehavior
+		want         domain.BulkResponse
+	}{
+		{
+			name:   "Best Effort",
+			atomic: false,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT role FROM users_lists").
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleOwner))
+
+				mock.ExpectExec("SAVEPOINT bulk_entry").WillReturnResult(sqlmock.NewResult(0, 0))
+				expectDelete(5, 7)
+				mock.ExpectExec("RELEASE SAVEPOINT bulk_entry").WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.ExpectExec("SAVEPOINT bulk_entry").WillReturnResult(sqlmock.NewResult(0, 0))
+				expectMissing(6)
+				mock.ExpectExec("ROLLBACK TO SAVEPOINT bulk_entry").WillReturnResult(sqlmock.NewResult(0, 0))
+
+				// the subtask deleted along with its parent
+				expectEvent(mock, domain.EventItemDeleted, 7)
+
+				mock.ExpectCommit()
+			},
+			want: domain.BulkResponse{
+				Committed: true,
+				Results: []domain.BulkResult{
+					{Index: 0, Id: 5, Status: domain.BulkApplied},
+					{Index: 1, Id: 6, Status: domain.BulkFailed, Error: "item not found"},
+				},
+			},
+		},
+		{
+			name:   "Atomic",
+			atomic: true,
+			mockBehavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT role FROM users_lists").
+					WithArgs(1, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleOwner))
+
+				expectDelete(5, 7)
+				expectMissing(6)
+
+				mock.ExpectRollback()
+			},
+			want: domain.BulkResponse{
+				Committed: false,
+				Results: []domain.BulkResult{
+					{Index: 0, Status: domain.BulkSkipped},
+					{Index: 1, Id: 6, Status: domain.BulkFailed, Error: "item not found"},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			got, err := r.BulkDelete(1, 1, []int{5, 6}, testCase.atomic)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// expectEvent expects the event of an item changed by the repository on its
+// own. Events of created and updated items carry the item as it is now.
+func expectEvent(mock sqlmock.Sqlmock, eventType string, itemId int) {
+	if eventType != domain.EventItemDeleted {
+		mock.ExpectQuery(`SELECT ti.id, .* FROM todo_items ti WHERE ti.id = \$1`).
+			WithArgs(itemId).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(itemId))
+	}
+
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1\)`).
+		WithArgs(eventsLockKey).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO events").
+		WithArgs(eventType, 1, 1, sqlmock.AnyArg(), EventsChannel, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO webhook_deliveries").
+		WithArgs(eventType, sqlmock.AnyArg(), 1, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
@@ -5,10 +5,11 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/pavel-trbv/go-todo-app/internal/domain"
 	"github.com/sirupsen/logrus"
-	"reflect"
 	"strings"
 )
 
+const listColumns = "tl.id, tl.title, tl.description, tl.version"
+
 type TodoListPostgres struct {
 	db *sqlx.DB
 }
@@ -18,97 +19,272 @@ func NewTodoListPostgres(db *sqlx.DB) *TodoListPostgres {
 }
 
 func (r *TodoListPostgres) Create(userId int, list domain.TodoList) (int, error) {
-	tx, err := r.db.Begin()
+	tx, err := r.db.Beginx()
 	if err != nil {
 		return 0, err
 	}
+	defer tx.Rollback()
 
 	var id int
 	createListQuery := fmt.Sprintf("INSERT INTO %s (title, description) VALUES ($1, $2) RETURNING id", todoListsTable)
 	row := tx.QueryRow(createListQuery, list.Title, list.Description)
 	if err := row.Scan(&id); err != nil {
-		tx.Rollback()
-		return 0, nil
+		return 0, err
+	}
+
+	if err := lockUser(tx, userId); err != nil {
+		return 0, err
+	}
+
+	position, err := nextListPosition(tx, userId)
+	if err != nil {
+		return 0, err
 	}
 
-	createUsersListQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id) VALUES ($1, $2) RETURNING id", usersListsTable)
-	if _, err := tx.Exec(createUsersListQuery, userId, id); err != nil {
-		tx.Rollback()
-		return 0, nil
+	createUsersListQuery := fmt.Sprintf(
+		"INSERT INTO %s (user_id, list_id, role, position) VALUES ($1, $2, $3, $4) RETURNING id",
+		usersListsTable)
+	if _, err := tx.Exec(createUsersListQuery, userId, id, domain.RoleOwner, position); err != nil {
+		return 0, err
 	}
 
 	return id, tx.Commit()
 }
 
-func (r *TodoListPostgres) GetAll(userId int) ([]domain.TodoList, error) {
+func (r *TodoListPostgres) GetAll(userId int, filter domain.ListFilter) (domain.ListPage, error) {
+	var b queryBuilder
+	b.where("ul.user_id = ?", userId)
+
+	if filter.Query != "" {
+		b.contains(filter.Query, "tl.title", "tl.description")
+	}
+
+	orderQuery, err := b.paginate(filter.Page, listSortKeys[filter.Sort], "tl.id")
+	if err != nil {
+		return domain.ListPage{}, err
+	}
+
 	var lists []domain.TodoList
+	query := fmt.Sprintf("SELECT %s, ul.role, ul.position FROM %s tl INNER JOIN %s ul ON tl.id = ul.list_id %s %s",
+		listColumns, todoListsTable, usersListsTable, b.whereClause(), orderQuery)
+	if err := r.db.Select(&lists, query, b.args...); err != nil {
+		return domain.ListPage{}, err
+	}
 
-	query := fmt.Sprintf("SELECT tl.* FROM %s tl INNER JOIN %s ul ON tl.id = ul.list_id WHERE ul.user_id = $1",
-		todoListsTable, usersListsTable)
-	err := r.db.Select(&lists, query, userId)
+	page := domain.ListPage{Data: lists}
+	if len(lists) > filter.Limit {
+		page.Data = lists[:filter.Limit]
+		last := page.Data[filter.Limit-1]
+		page.NextCursor = encodeCursor(cursor{
+			Sort:  filter.Sort,
+			Order: filter.Order,
+			Value: listSortValue(last, filter.Sort),
+			Id:    last.Id,
+		})
+	}
 
-	return lists, err
+	return page, nil
 }
 
 func (r *TodoListPostgres) GetById(userId, listId int) (domain.TodoList, error) {
 	var list domain.TodoList
 
 	query := fmt.Sprintf(
-		`SELECT tl.* FROM %s tl 
+		`SELECT %s, ul.role, ul.position FROM %s tl 
 				INNER JOIN %s ul ON tl.id = ul.list_id 
 				WHERE ul.user_id = $1 AND ul.list_id = $2
 				LIMIT 1`,
+		listColumns,
 		todoListsTable,
 		usersListsTable,
 	)
 	err := r.db.Get(&list, query, userId, listId)
 
-	return list, err
+	return list, wrapError(err, "list")
+}
+
+// Move changes the position of the list among the lists of the user, the
+// order is personal to every member of the list.
+func (r *TodoListPostgres) Move(userId, listId int, input domain.MoveInput) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := listRole(tx, userId, listId); err != nil {
+		return err
+	}
+
+	if err := lockUser(tx, userId); err != nil {
+		return err
+	}
+
+	siblingsQuery := fmt.Sprintf("SELECT list_id AS id, position FROM %s WHERE user_id = $1 AND list_id <> $2",
+		usersListsTable)
+	position, err := movePosition(tx, siblingsQuery, []interface{}{userId, listId}, input)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET position = $1 WHERE user_id = $2 AND list_id = $3", usersListsTable)
+	if _, err := tx.Exec(query, position, userId, listId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *TodoListPostgres) Delete(userId, listId int) error {
+// Duplicate creates a list owned by the user with deep copies of all items
+// of the list, the labels the user put on them and the user's offset
+// reminders.
+func (r *TodoListPostgres) Duplicate(userId, listId int, title string) (int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := listRole(tx, userId, listId); err != nil {
+		return 0, err
+	}
+
+	var id int
+	createListQuery := fmt.Sprintf(
+		"INSERT INTO %s (title, description) SELECT $1, description FROM %s WHERE id = $2 RETURNING id",
+		todoListsTable, todoListsTable)
+	if err := tx.Get(&id, createListQuery, title, listId); err != nil {
+		return 0, wrapError(err, "list")
+	}
+
+	if err := lockUser(tx, userId); err != nil {
+		return 0, err
+	}
+
+	position, err := nextListPosition(tx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	createUsersListQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id, role, position) VALUES ($1, $2, $3, $4)",
+		usersListsTable)
+	if _, err := tx.Exec(createUsersListQuery, userId, id, domain.RoleOwner, position); err != nil {
+		return 0, err
+	}
+
+	items := make([]domain.TodoItem, 0)
 	query := fmt.Sprintf(
-		`DELETE FROM %s tl USING %s ul WHERE tl.id = ul.list_id AND ul.user_id = $1 AND ul.list_id = $2`,
-		todoListsTable,
-		usersListsTable,
+		`WITH RECURSIVE tree AS (
+					SELECT ti.*, 0 AS depth FROM %[1]s ti INNER JOIN %[2]s li ON li.item_id = ti.id
+						WHERE li.list_id = $1 AND ti.parent_id IS NULL
+					UNION ALL
+					SELECT c.*, t.depth + 1 FROM %[1]s c INNER JOIN tree t ON c.parent_id = t.id
+				)
+				SELECT %[3]s FROM tree ti ORDER BY ti.depth, ti.position, ti.id`,
+		todoItemsTable,
+		listsItemsTable,
+		itemColumns,
 	)
-	_, err := r.db.Exec(query, userId, listId)
+	if err := tx.Select(&items, query, listId); err != nil {
+		return 0, err
+	}
 
-	return err
-}
+	ids, err := copyItems(tx, userId, id, items)
+	if err != nil {
+		return 0, err
+	}
 
-func (r *TodoListPostgres) Update(userId, listId int, input domain.UpdateListInput) error {
-	setValues := make([]string, 0)
-	args := make([]interface{}, 0)
-	argId := 1
-
-	v := reflect.ValueOf(input)
-	for i := 0; i < v.NumField(); i++ {
-		key := v.Type().Field(i).Name
-		if !v.Field(i).IsNil() {
-			value := v.Field(i).Elem().Interface()
-			setValues = append(setValues, fmt.Sprintf("%s = $%d", key, argId))
-			args = append(args, value)
-			argId++
+	for _, item := range items {
+		if err := copyReminders(tx, id, item.Id, ids[item.Id]); err != nil {
+			return 0, err
 		}
 	}
 
-	setQuery := strings.Join(setValues, ", ")
+	return id, tx.Commit()
+}
+
+func (r *TodoListPostgres) GetRole(userId, listId int) (string, error) {
+	return listRole(r.db, userId, listId)
+}
+
+// Delete deletes the list and returns the ids of its members, which the
+// deletion cascades to.
+func (r *TodoListPostgres) Delete(userId, listId int, version *int) ([]int, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := requireListRole(tx, userId, listId, domain.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(tx, todoListsTable, listId, version, "list"); err != nil {
+		return nil, err
+	}
+
+	members := make([]int, 0)
+	membersQuery := fmt.Sprintf("SELECT user_id FROM %s WHERE list_id = $1 ORDER BY user_id", usersListsTable)
+	if err := tx.Select(&members, membersQuery, listId); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", todoListsTable)
+	res, err := tx.Exec(query, listId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireAffected(res, "list"); err != nil {
+		return nil, err
+	}
+
+	return members, tx.Commit()
+}
+
+func (r *TodoListPostgres) Update(userId, listId int, patch domain.Patch, version *int) error {
+	setValues, args, err := setClause(patch, listFieldColumns)
+	if err != nil {
+		return err
+	}
+	if len(setValues) == 0 {
+		return domain.NewError(domain.ErrValidation, "update structure has no value")
+	}
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := requireListRole(tx, userId, listId, domain.WriteRoles...); err != nil {
+		return err
+	}
+
+	if err := checkVersion(tx, todoListsTable, listId, version, "list"); err != nil {
+		return err
+	}
 
 	query := fmt.Sprintf(
-		`UPDATE %s tl SET %s FROM %s ul WHERE tl.id = ul.list_id AND ul.list_id = $%d AND ul.user_id = $%d`,
+		`UPDATE %s tl SET %s WHERE tl.id = $%d`,
 		todoListsTable,
-		setQuery,
-		usersListsTable,
-		argId,
-		argId+1,
+		strings.Join(setValues, ", "),
+		len(args)+1,
 	)
-	args = append(args, listId, userId)
+	args = append(args, listId)
 
 	logrus.Debugf("updateQuery: %s", query)
 	logrus.Debugf("args: %s", args)
 
-	_, err := r.db.Exec(query, args...)
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res, "list"); err != nil {
+		return err
+	}
 
-	return err
+	return tx.Commit()
 }
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+)
+
+func TestTodoListPostgres_Delete(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewTodoListPostgres(db)
+
+	type args struct {
+		userId int
+		listId int
+	}
+	type mockBehavior func(args args)
+
+	testTable := []struct {
+		name         string
+		mockBehavior mockBehavior
+		args         args
+		members      []int
+		wantErr      bool
+		errKind      error
+	}{
+		{
+			name: "OK",
+			args: args{
+				userId: 1,
+				listId: 2,
+			},
+			members: []int{1, 3},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(`SELECT role FROM users_lists WHERE user_id = \$1 AND list_id = \$2 FOR SHARE`).
+					WithArgs(args.userId, args.listId).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleOwner))
+
+				mock.ExpectQuery("SELECT user_id FROM users_lists").
+					WithArgs(args.listId).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1).AddRow(3))
+
+				mock.ExpectExec("DELETE FROM todo_lists").
+					WithArgs(args.listId).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Not Owner",
+			args: args{
+				userId: 1,
+				listId: 2,
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(`SELECT role FROM users_lists WHERE user_id = \$1 AND list_id = \$2 FOR SHARE`).
+					WithArgs(args.userId, args.listId).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleEditor))
+
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+			errKind: domain.ErrForbidden,
+		},
+		{
+			name: "Failed Delete",
+			args: args{
+				userId: 1,
+				listId: 2,
+			},
+			mockBehavior: func(args args) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(`SELECT role FROM users_lists WHERE user_id = \$1 AND list_id = \$2 FOR SHARE`).
+					WithArgs(args.userId, args.listId).
+					WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(domain.RoleOwner))
+
+				mock.ExpectQuery("SELECT user_id FROM users_lists").
+					WithArgs(args.listId).
+					WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+
+				mock.ExpectExec("DELETE FROM todo_lists").
+					WithArgs(args.listId).
+					WillReturnError(errors.New("some error"))
+
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior(testCase.args)
+
+			got, err := r.Delete(testCase.args.userId, testCase.args.listId, nil)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				if testCase.errKind != nil {
+					assert.True(t, errors.Is(err, testCase.errKind))
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.members, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"strings"
+	"time"
+)
+
+const (
+	webhookColumns  = "w.id, w.url, w.secret, w.events, w.active, w.created_at"
+	deliveryColumns = "d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.next_attempt_at, " +
+		"d.last_status_code, d.last_error, d.created_at, d.delivered_at"
+)
+
+// webhookRow scans the event types, which domain.Webhook keeps as a plain
+// slice.
+type webhookRow struct {
+	domain.Webhook
+	Events pq.StringArray `db:"events"`
+}
+
+func (r webhookRow) webhook() domain.Webhook {
+	webhook := r.Webhook
+	webhook.Events = r.Events
+
+	return webhook
+}
+
+// eventMembers returns a query selecting the members an event is for and its
+// argument, numbered n: the explicit recipients of the event or else the
+// members of its list.
+func eventMembers(event domain.Event, n int) (string, interface{}) {
+	if event.Recipients != nil {
+		return fmt.Sprintf("SELECT unnest($%d::int[])", n), pq.Array(event.Recipients)
+	}
+
+	return fmt.Sprintf("SELECT ul.user_id FROM %s ul WHERE ul.list_id = $%d", usersListsTable, n), event.ListId
+}
+
+type WebhookPostgres struct {
+	db *sqlx.DB
+}
+
+func NewWebhookPostgres(db *sqlx.DB) *WebhookPostgres {
+	return &WebhookPostgres{db: db}
+}
+
+func (r *WebhookPostgres) Create(userId int, webhook domain.Webhook) (int, error) {
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (user_id, url, secret, events) VALUES ($1, $2, $3, $4) RETURNING id",
+		webhooksTable)
+	err := r.db.QueryRow(query, userId, webhook.URL, webhook.Secret, pq.Array(webhook.Events)).Scan(&id)
+	if err != nil {
+		return 0, wrapError(err, "webhook")
+	}
+
+	return id, nil
+}
+
+func (r *WebhookPostgres) GetAll(userId int) ([]domain.Webhook, error) {
+	var rows []webhookRow
+	query := fmt.Sprintf("SELECT %s FROM %s w WHERE w.user_id = $1 ORDER BY w.id", webhookColumns, webhooksTable)
+	if err := r.db.Select(&rows, query, userId); err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]domain.Webhook, len(rows))
+	for i, row := range rows {
+		webhooks[i] = row.webhook()
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookPostgres) GetById(userId, webhookId int) (domain.Webhook, error) {
+	var row webhookRow
+	query := fmt.Sprintf("SELECT %s FROM %s w WHERE w.id = $1 AND w.user_id = $2", webhookColumns, webhooksTable)
+	if err := r.db.Get(&row, query, webhookId, userId); err != nil {
+		return domain.Webhook{}, wrapError(err, "webhook")
+	}
+
+	return row.webhook(), nil
+}
+
+func (r *WebhookPostgres) Update(userId, webhookId int, patch domain.Patch) error {
+	setValues, args, err := setClause(patch, webhookFieldColumns)
+	if err != nil {
+		return err
+	}
+	if len(setValues) == 0 {
+		return domain.NewError(domain.ErrValidation, "update structure has no value")
+	}
+
+	for i, arg := range args {
+		if events, ok := arg.([]string); ok {
+			args[i] = pq.Array(events)
+		}
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d AND user_id = $%d",
+		webhooksTable, strings.Join(setValues, ", "), len(args)+1, len(args)+2)
+	args = append(args, webhookId, userId)
+
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return wrapError(err, "webhook")
+	}
+
+	return requireAffected(res, "webhook")
+}
+
+func (r *WebhookPostgres) Delete(userId, webhookId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND user_id = $2", webhooksTable)
+	res, err := r.db.Exec(query, webhookId, userId)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res, "webhook")
+}
+
+// Enqueue stores a pending delivery of the event for every active webhook
+// subscribed to it.
+func (r *WebhookPostgres) Enqueue(event domain.Event) error {
+	return enqueueEvent(r.db, event)
+}
+
+func enqueueEvent(e sqlx.Execer, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	members, membersArg := eventMembers(event, 4)
+	query := fmt.Sprintf(
+		`INSERT INTO %s (webhook_id, event, payload)
+				SELECT w.id, $1, $2 FROM %s w
+				WHERE w.active AND $1 = ANY(w.events) AND (w.user_id = $3 OR w.user_id IN (%s))`,
+		webhookDeliveriesTable,
+		webhooksTable,
+		members,
+	)
+	_, err = e.Exec(query, event.Type, string(payload), event.ActorId, membersArg)
+
+	return err
+}
+
+func (r *WebhookPostgres) GetDeliveries(userId, webhookId, limit int) ([]domain.WebhookDelivery, error) {
+	if _, err := r.GetById(userId, webhookId); err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+	query := fmt.Sprintf("SELECT %s FROM %s d WHERE d.webhook_id = $1 ORDER BY d.id DESC LIMIT $2",
+		deliveryColumns, webhookDeliveriesTable)
+	err := r.db.Select(&deliveries, query, webhookId, limit)
+
+	return deliveries, err
+}
+
+// Redeliver queues a delivery again right away with a fresh set of attempts,
+// whatever its outcome was.
+func (r *WebhookPostgres) Redeliver(userId, webhookId, deliveryId int) error {
+	query := fmt.Sprintf(
+		`UPDATE %s d SET status = $1, attempts = 0, next_attempt_at = now()
+				FROM %s w
+				WHERE w.id = d.webhook_id AND d.id = $2 AND d.webhook_id = $3 AND w.user_id = $4`,
+		webhookDeliveriesTable,
+		webhooksTable,
+	)
+	res, err := r.db.Exec(query, domain.DeliveryPending, deliveryId, webhookId, userId)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res, "delivery")
+}
+
+// ProcessPending claims up to limit pending deliveries that are due and
+// hands them to deliver one by one, recording the outcome. Claiming leases
+// the deliveries by moving their next attempt past lease, so other instances
+// skip them while no transaction is held during the requests. A delivery
+// whose outcome is not recorded before its lease ends is attempted again. It
+// returns how many were delivered.
+func (r *WebhookPostgres) ProcessPending(limit int, lease time.Duration,
+	deliver func(domain.WebhookDelivery) domain.DeliveryResult) (int, error) {
+	deliveries := make([]domain.WebhookDelivery, 0)
+	query := fmt.Sprintf(
+		`WITH claimed AS (
+					SELECT d.id FROM %s d INNER JOIN %s w ON w.id = d.webhook_id
+					WHERE d.status = $1 AND d.next_attempt_at <= now() AND w.active
+					ORDER BY d.next_attempt_at, d.id
+					LIMIT $2
+					FOR UPDATE OF d SKIP LOCKED
+				)
+				UPDATE %s d SET next_attempt_at = now() + $3 * interval '1 millisecond'
+				FROM claimed c, %s w
+				WHERE d.id = c.id AND w.id = d.webhook_id
+				RETURNING %s, w.url, w.secret`,
+		webhookDeliveriesTable,
+		webhooksTable,
+		webhookDeliveriesTable,
+		webhooksTable,
+		deliveryColumns,
+	)
+	err := r.db.Select(&deliveries, query, domain.DeliveryPending, limit, lease.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+
+	deliveredQuery := fmt.Sprintf(
+		`UPDATE %s SET status = $2, attempts = attempts + 1, next_attempt_at = NULL, last_status_code = $3,
+					last_error = NULL, delivered_at = now()
+				WHERE id = $1`,
+		webhookDeliveriesTable,
+	)
+	// deliveries given up on have no next attempt
+	failedQuery := fmt.Sprintf(
+		`UPDATE %s SET status = $2, attempts = attempts + 1, next_attempt_at = now() + $3 * interval '1 millisecond',
+					last_status_code = $4, last_error = $5
+				WHERE id = $1`,
+		webhookDeliveriesTable,
+	)
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		result := deliver(delivery)
+
+		var statusCode *int
+		if result.StatusCode != 0 {
+			statusCode = &result.StatusCode
+		}
+
+		if result.Err == nil {
+			if _, err := r.db.Exec(deliveredQuery, delivery.Id, domain.DeliveryDelivered, statusCode); err != nil {
+				return 0, err
+			}
+			delivered++
+			continue
+		}
+
+		status := domain.DeliveryFailed
+		var retryMs *int64
+		if result.RetryIn > 0 {
+			ms := result.RetryIn.Milliseconds()
+			status, retryMs = domain.DeliveryPending, &ms
+		}
+
+		_, err := r.db.Exec(failedQuery, delivery.Id, status, retryMs, statusCode, result.Err.Error())
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return delivered, nil
+}
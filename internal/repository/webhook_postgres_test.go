@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestWebhookPostgres_Enqueue(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewWebhookPostgres(db)
+
+	createdAt := time.Date(2021, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name            string
+		event           domain.Event
+		expectedPayload string
+		expectedMembers string
+		membersArg      interface{}
+	}{
+		{
+			name: "List Members",
+			event: domain.Event{
+				Type:      domain.EventItemCreated,
+				ActorId:   1,
+				ListId:    2,
+				Data:      map[string]int{"id": 3},
+				CreatedAt: createdAt,
+			},
+			expectedPayload: `{"event":"item.created","actor_id":1,"list_id":2,"data":{"id":3},` +
+				`"created_at":"2021-10-01T09:00:00Z"}`,
+			expectedMembers: `SELECT ul.user_id FROM users_lists ul WHERE ul.list_id = \$4`,
+			membersArg:      2,
+		},
+		{
+			name: "Recipients",
+			event: domain.Event{
+				Type:       domain.EventListDeleted,
+				ActorId:    1,
+				ListId:     2,
+				Data:       map[string]int{"id": 2},
+				CreatedAt:  createdAt,
+				Recipients: []int{1, 3},
+			},
+			expectedPayload: `{"event":"list.deleted","actor_id":1,"list_id":2,"data":{"id":2},` +
+				`"created_at":"2021-10-01T09:00:00Z"}`,
+			expectedMembers: `SELECT unnest\(\$4::int\[\]\)`,
+			membersArg:      "{1,3}",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			mock.ExpectExec(`INSERT INTO webhook_deliveries \(webhook_id, event, payload\) SELECT w.id, \$1, \$2 `+
+				`FROM webhooks w WHERE w.active AND \$1 = ANY\(w.events\) `+
+				`AND \(w.user_id = \$3 OR w.user_id IN \(`+testCase.expectedMembers+`\)\)`).
+				WithArgs(testCase.event.Type, testCase.expectedPayload, 1, testCase.membersArg).
+				WillReturnResult(sqlmock.NewResult(0, 2))
+
+			assert.NoError(t, r.Enqueue(testCase.event))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWebhookPostgres_Update(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewWebhookPostgres(db)
+
+	testTable := []struct {
+		name         string
+		patch        domain.Patch
+		mockBehavior func()
+		wantErr      error
+	}{
+		{
+			name:  "OK",
+			patch: domain.Patch{"active": false, "events": []string{domain.EventItemCreated}},
+			mockBehavior: func() {
+				mock.ExpectExec(`UPDATE webhooks SET events = \$1, active = \$2 WHERE id = \$3 AND user_id = \$4`).
+					WithArgs("{\"item.created\"}", false, 2, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:         "Unknown Field",
+			patch:        domain.Patch{"user_id": 3},
+			mockBehavior: func() {},
+			wantErr:      domain.ErrValidation,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			err := r.Update(1, 2, testCase.patch)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestWebhookPostgres_ProcessPending(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer mockDB.Close()
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	r := NewWebhookPostgres(db)
+
+	columns := []string{"id", "webhook_id", "event", "payload", "status", "attempts", "next_attempt_at",
+		"last_status_code", "last_error", "created_at", "delivered_at", "url", "secret"}
+	now := time.Date(2021, 10, 1, 9, 0, 0, 0, time.UTC)
+	row := func(rows *sqlmock.Rows, id, attempts int) *sqlmock.Rows {
+		return rows.AddRow(id, 1, domain.EventItemCreated, []byte(`{}`), domain.DeliveryPending, attempts, now,
+			nil, nil, now, nil, "https://example.com/hooks", "secret")
+	}
+
+	testTable := []struct {
+		name         string
+		mockBehavior func()
+		deliver      func(domain.WebhookDelivery) domain.DeliveryResult
+		delivered    int
+		wantErr      bool
+	}{
+		{
+			name: "OK",
+			mockBehavior: func() {
+				mock.ExpectQuery(`WHERE d.status = \$1 AND d.next_attempt_at <= now\(\) AND w.active `+
+					`ORDER BY d.next_attempt_at, d.id LIMIT \$2 FOR UPDATE OF d SKIP LOCKED \) `+
+					`UPDATE webhook_deliveries d SET next_attempt_at = now\(\) \+ \$3 \* interval '1 millisecond'`).
+					WithArgs(domain.DeliveryPending, 10, 300000).
+					WillReturnRows(row(row(row(sqlmock.NewRows(columns), 1, 0), 2, 1), 3, 4))
+
+				mock.ExpectExec("UPDATE webhook_deliveries SET status = \\$2, attempts = attempts \\+ 1, "+
+					"next_attempt_at = NULL").
+					WithArgs(1, domain.DeliveryDelivered, 204).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE webhook_deliveries SET status = \\$2, attempts = attempts \\+ 1, "+
+					"next_attempt_at = now\\(\\) \\+ \\$3").
+					WithArgs(2, domain.DeliveryPending, 120000, 500, "webhook responded with status 500").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE webhook_deliveries SET status = \\$2, attempts = attempts \\+ 1, "+
+					"next_attempt_at = now\\(\\) \\+ \\$3").
+					WithArgs(3, domain.DeliveryFailed, nil, nil, "connection refused").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			deliver: func(delivery domain.WebhookDelivery) domain.DeliveryResult {
+				switch delivery.Id {
+				case 1:
+					return domain.DeliveryResult{StatusCode: 204}
+				case 2:
+					return domain.DeliveryResult{
+						StatusCode: 500,
+						Err:        errors.New("webhook responded with status 500"),
+						RetryIn:    2 * time.Minute,
+					}
+				default:
+					return domain.DeliveryResult{Err: errors.New("connection refused")}
+				}
+			},
+			delivered: 1,
+		},
+		{
+			name: "Nothing Pending",
+			mockBehavior: func() {
+				mock.ExpectQuery("FOR UPDATE OF d SKIP LOCKED").
+					WithArgs(domain.DeliveryPending, 10, 300000).
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			deliver: func(delivery domain.WebhookDelivery) domain.DeliveryResult {
+				t.Error("nothing should be delivered")
+				return domain.DeliveryResult{}
+			},
+		},
+		{
+			name: "Recording Fails",
+			mockBehavior: func() {
+				mock.ExpectQuery("FOR UPDATE OF d SKIP LOCKED").
+					WithArgs(domain.DeliveryPending, 10, 300000).
+					WillReturnRows(row(row(sqlmock.NewRows(columns), 1, 0), 2, 0))
+
+				mock.ExpectExec("UPDATE webhook_deliveries SET status").
+					WithArgs(1, domain.DeliveryDelivered, 200).
+					WillReturnError(errors.New("connection lost"))
+			},
+			deliver: func(delivery domain.WebhookDelivery) domain.DeliveryResult {
+				if delivery.Id != 1 {
+					t.Error("delivering should stop once recording fails")
+				}
+				return domain.DeliveryResult{StatusCode: 200}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehavior()
+
+			delivered, err := r.ProcessPending(10, 5*time.Minute, testCase.deliver)
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.delivered, delivered)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
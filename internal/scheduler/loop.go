@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// loop runs a job every interval until it is shut down. Jobs watch ctx to
+// abort work in progress once the shutdown deadline is reached.
+type loop struct {
+	interval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func newLoop(interval time.Duration) *loop {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &loop{
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (l *loop) run(job func()) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.interval)
+	defer ticker.Stop()
+
+	for {
+		job()
+
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// stopping tells jobs working through a backlog to return early.
+func (l *loop) stopping() bool {
+	select {
+	case <-l.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// shutdown stops the loop and waits for the job in progress. When the
+// context ends first, the job is aborted.
+func (l *loop) shutdown(ctx context.Context) error {
+	close(l.stop)
+	defer l.cancel()
+
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		<-l.done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,92 @@
+// Package scheduler runs the background jobs of the application next to the
+// HTTP server.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/notify"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type ReminderConfig struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	SendTimeout time.Duration
+}
+
+// ReminderScheduler sends due reminders. Several instances may run against
+// the same database, each reminder is claimed by one of them.
+type ReminderScheduler struct {
+	repo     repository.Reminder
+	channels notify.Channels
+	cfg      ReminderConfig
+	loop     *loop
+}
+
+func NewReminderScheduler(repo repository.Reminder, channels notify.Channels,
+	cfg ReminderConfig) *ReminderScheduler {
+	return &ReminderScheduler{
+		repo:     repo,
+		channels: channels,
+		cfg:      cfg,
+		loop:     newLoop(cfg.Interval),
+	}
+}
+
+// Run processes due reminders every interval until Shutdown is called.
+func (s *ReminderScheduler) Run() {
+	s.loop.run(s.processDue)
+}
+
+// Shutdown stops the scheduler and waits for the batch in progress. When the
+// context ends first, pending notifications are aborted.
+func (s *ReminderScheduler) Shutdown(ctx context.Context) error {
+	return s.loop.shutdown(ctx)
+}
+
+// processDue works through full batches until the backlog is cleared.
+func (s *ReminderScheduler) processDue() {
+	for {
+		sent, err := s.repo.ProcessDue(s.cfg.BatchSize, s.cfg.MaxAttempts, s.send)
+		if err != nil {
+			logrus.Errorf("error occured while processing reminders: %s", err.Error())
+			return
+		}
+
+		if sent < s.cfg.BatchSize || s.loop.stopping() {
+			return
+		}
+	}
+}
+
+func (s *ReminderScheduler) send(reminder domain.DueReminder) error {
+	ctx, cancel := context.WithTimeout(s.loop.ctx, s.cfg.SendTimeout)
+	defer cancel()
+
+	err := s.channels.Notify(ctx, reminder.Channel, reminderMessage(reminder))
+	if err != nil {
+		logrus.Warnf("failed to send reminder %d: %s", reminder.Id, err.Error())
+	}
+
+	return err
+}
+
+func reminderMessage(reminder domain.DueReminder) notify.Message {
+	body := fmt.Sprintf("Reminder for %q.", reminder.ItemTitle)
+	if reminder.DueAt != nil {
+		body = fmt.Sprintf("%q is due at %s.", reminder.ItemTitle, reminder.DueAt.UTC().Format(time.RFC1123))
+	}
+
+	return notify.Message{
+		To:      reminder.Target,
+		Subject: "Reminder: " + reminder.ItemTitle,
+		Body:    body,
+		ItemId:  reminder.ItemId,
+		DueAt:   reminder.DueAt,
+	}
+}
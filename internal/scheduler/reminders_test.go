@@ -0,0 +1,148 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/notify"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reminderRepo hands out the queued reminders in batches and records what
+// happened to them.
+type reminderRepo struct {
+	mu     sync.Mutex
+	queue  []domain.DueReminder
+	sent   []int
+	failed []int
+}
+
+func (r *reminderRepo) Create(userId, itemId int, reminder domain.Reminder) (int, error) {
+	return 0, nil
+}
+
+func (r *reminderRepo) GetAll(userId, itemId int) ([]domain.Reminder, error) {
+	return nil, nil
+}
+
+func (r *reminderRepo) Delete(userId, reminderId int) error {
+	return nil
+}
+
+func (r *reminderRepo) ProcessDue(limit, maxAttempts int, send func(domain.DueReminder) error) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	batch := r.queue
+	if len(batch) > limit {
+		batch = batch[:limit]
+	}
+	r.queue = r.queue[len(batch):]
+
+	sent := 0
+	for _, reminder := range batch {
+		if err := send(reminder); err != nil {
+			r.failed = append(r.failed, reminder.Id)
+			continue
+		}
+		r.sent = append(r.sent, reminder.Id)
+		sent++
+	}
+
+	return sent, nil
+}
+
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages []notify.Message
+	err      error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.messages = append(n.messages, msg)
+	return n.err
+}
+
+func TestReminderScheduler(t *testing.T) {
+	dueAt := time.Date(2021, 10, 1, 9, 0, 0, 0, time.UTC)
+	reminder := func(id int, channel string) domain.DueReminder {
+		return domain.DueReminder{
+			Reminder:  domain.Reminder{Id: id, ItemId: 10 + id, Channel: channel, Target: "alice@example.com"},
+			ItemTitle: "Pay rent",
+			DueAt:     &dueAt,
+		}
+	}
+
+	repo := &reminderRepo{queue: []domain.DueReminder{
+		reminder(1, domain.ChannelEmail),
+		reminder(2, domain.ChannelWebhook),
+		reminder(3, domain.ChannelEmail),
+		reminder(4, "sms"),
+		reminder(5, domain.ChannelEmail),
+	}}
+	email := &recordingNotifier{}
+	webhook := &recordingNotifier{err: errors.New("connection refused")}
+
+	s := NewReminderScheduler(repo, notify.Channels{
+		domain.ChannelEmail:   email,
+		domain.ChannelWebhook: webhook,
+	}, ReminderConfig{
+		Interval:    time.Hour,
+		BatchSize:   2,
+		MaxAttempts: 5,
+		SendTimeout: time.Second,
+	})
+
+	go s.Run()
+
+	// the first round stops after the batch in which the webhook failed
+	assert.Eventually(t, func() bool {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		return len(repo.sent)+len(repo.failed) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, s.Shutdown(ctx))
+
+	assert.Equal(t, []int{1}, repo.sent)
+	assert.Equal(t, []int{2}, repo.failed)
+	assert.Len(t, repo.queue, 3)
+
+	if assert.Len(t, email.messages, 1) {
+		msg := email.messages[0]
+		assert.Equal(t, "alice@example.com", msg.To)
+		assert.Equal(t, "Reminder: Pay rent", msg.Subject)
+		assert.Equal(t, `"Pay rent" is due at Fri, 01 Oct 2021 09:00:00 UTC.`, msg.Body)
+		assert.Equal(t, 11, msg.ItemId)
+	}
+}
+
+func TestReminderScheduler_ProcessesFullBatches(t *testing.T) {
+	repo := &reminderRepo{}
+	for i := 1; i <= 5; i++ {
+		repo.queue = append(repo.queue, domain.DueReminder{
+			Reminder:  domain.Reminder{Id: i, Channel: domain.ChannelLog},
+			ItemTitle: "Pay rent",
+		})
+	}
+
+	s := NewReminderScheduler(repo, notify.Channels{domain.ChannelLog: &recordingNotifier{}}, ReminderConfig{
+		Interval:    time.Hour,
+		BatchSize:   2,
+		MaxAttempts: 5,
+		SendTimeout: time.Second,
+	})
+
+	s.processDue()
+
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, repo.sent)
+	assert.Empty(t, repo.failed)
+}
@@ -0,0 +1,47 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type SyncConfig struct {
+	Interval time.Duration
+	// changes older than Retention are pruned, so are the sync tokens
+	// taken before them
+	Retention time.Duration
+}
+
+// SyncPruner prunes the change log sync tokens are served from. Pruning is
+// a single statement, several instances may run at once.
+type SyncPruner struct {
+	repo repository.Sync
+	cfg  SyncConfig
+	loop *loop
+}
+
+func NewSyncPruner(repo repository.Sync, cfg SyncConfig) *SyncPruner {
+	return &SyncPruner{
+		repo: repo,
+		cfg:  cfg,
+		loop: newLoop(cfg.Interval),
+	}
+}
+
+// Run prunes the change log every interval until Shutdown is called.
+func (p *SyncPruner) Run() {
+	p.loop.run(p.prune)
+}
+
+// Shutdown stops the pruner and waits for the run in progress.
+func (p *SyncPruner) Shutdown(ctx context.Context) error {
+	return p.loop.shutdown(ctx)
+}
+
+func (p *SyncPruner) prune() {
+	if err := p.repo.Prune(time.Now().Add(-p.cfg.Retention)); err != nil {
+		logrus.Errorf("error occured while pruning sync changes: %s", err.Error())
+	}
+}
@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/netguard"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Todo-Event"
+	DeliveryHeader  = "X-Todo-Delivery"
+	SignatureHeader = "X-Todo-Signature"
+)
+
+type WebhookConfig struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	Timeout     time.Duration
+	// failed attempts are retried after MinBackoff, doubling up to MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Guard limits the addresses deliveries are sent to
+	Guard netguard.Guard
+}
+
+// WebhookDispatcher delivers pending webhook deliveries, retrying failed
+// ones with exponential backoff. Like reminders, deliveries are claimed, so
+// several instances can run at once.
+type WebhookDispatcher struct {
+	repo   repository.Webhook
+	client *http.Client
+	cfg    WebhookConfig
+	loop   *loop
+}
+
+func NewWebhookDispatcher(repo repository.Webhook, cfg WebhookConfig) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:   repo,
+		client: cfg.Guard.Client(cfg.Timeout),
+		cfg:    cfg,
+		loop:   newLoop(cfg.Interval),
+	}
+}
+
+// Run delivers pending deliveries every interval until Shutdown is called.
+func (d *WebhookDispatcher) Run() {
+	d.loop.run(d.processPending)
+}
+
+// Shutdown stops the dispatcher and waits for the batch in progress. When
+// the context ends first, requests in flight are aborted and retried later.
+func (d *WebhookDispatcher) Shutdown(ctx context.Context) error {
+	return d.loop.shutdown(ctx)
+}
+
+func (d *WebhookDispatcher) processPending() {
+	// the deliveries of a batch are sent one after another
+	lease := d.cfg.Timeout * time.Duration(d.cfg.BatchSize+1)
+
+	for {
+		delivered, err := d.repo.ProcessPending(d.cfg.BatchSize, lease, d.deliver)
+		if err != nil {
+			logrus.Errorf("error occured while delivering webhooks: %s", err.Error())
+			return
+		}
+
+		if delivered < d.cfg.BatchSize || d.loop.stopping() {
+			return
+		}
+	}
+}
+
+func (d *WebhookDispatcher) deliver(delivery domain.WebhookDelivery) domain.DeliveryResult {
+	statusCode, err := d.post(delivery)
+	if err == nil {
+		return domain.DeliveryResult{StatusCode: statusCode}
+	}
+
+	logrus.Warnf("failed to deliver webhook delivery %d: %s", delivery.Id, err.Error())
+
+	result := domain.DeliveryResult{StatusCode: statusCode, Err: err}
+	if attempt := delivery.Attempts + 1; attempt < d.cfg.MaxAttempts {
+		result.RetryIn = d.backoff(attempt)
+	}
+
+	return result
+}
+
+func (d *WebhookDispatcher) post(delivery domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(d.loop.ctx, http.MethodPost, delivery.URL,
+		bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.Itoa(delivery.Id))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff is the delay before the attempt following the given one.
+func (d *WebhookDispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.MinBackoff
+	for i := 1; i < attempt && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > d.cfg.MaxBackoff {
+		delay = d.cfg.MaxBackoff
+	}
+
+	return delay
+}
+
+// Sign computes the signature header of a payload: the hex encoded
+// HMAC-SHA256 of the raw request body keyed with the webhook secret.
+// Receivers should compare it in constant time.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
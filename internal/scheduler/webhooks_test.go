@@ -0,0 +1,213 @@
+package scheduler
+
+import (
+	"crypto/hmac"
+	"errors"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/netguard"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// webhookRepo hands out its pending deliveries once and keeps the results.
+type webhookRepo struct {
+	pending []domain.WebhookDelivery
+	results map[int]domain.DeliveryResult
+}
+
+func (r *webhookRepo) Create(userId int, webhook domain.Webhook) (int, error) {
+	return 0, nil
+}
+
+func (r *webhookRepo) GetAll(userId int) ([]domain.Webhook, error) {
+	return nil, nil
+}
+
+func (r *webhookRepo) GetById(userId, webhookId int) (domain.Webhook, error) {
+	return domain.Webhook{}, nil
+}
+
+func (r *webhookRepo) Update(userId, webhookId int, patch domain.Patch) error {
+	return nil
+}
+
+func (r *webhookRepo) Delete(userId, webhookId int) error {
+	return nil
+}
+
+func (r *webhookRepo) Enqueue(event domain.Event) error {
+	return nil
+}
+
+func (r *webhookRepo) GetDeliveries(userId, webhookId, limit int) ([]domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (r *webhookRepo) Redeliver(userId, webhookId, deliveryId int) error {
+	return nil
+}
+
+func (r *webhookRepo) ProcessPending(limit int, lease time.Duration,
+	deliver func(domain.WebhookDelivery) domain.DeliveryResult) (int, error) {
+	delivered := 0
+	for _, delivery := range r.pending {
+		result := deliver(delivery)
+		r.results[delivery.Id] = result
+		if result.Err == nil {
+			delivered++
+		}
+	}
+	r.pending = nil
+
+	return delivered, nil
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	payload := []byte(`{"event":"item.completed","actor_id":1,"list_id":2,"data":{"id":3}}`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		assert.Equal(t, payload, body)
+		assert.Equal(t, "item.completed", r.Header.Get(EventHeader))
+
+		secret := "good secret"
+		if r.URL.Path == "/rotated" {
+			secret = "new secret"
+		}
+		if !hmac.Equal([]byte(Sign(secret, body)), []byte(r.Header.Get(SignatureHeader))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := func(id int, path, secret string, attempts int) domain.WebhookDelivery {
+		return domain.WebhookDelivery{
+			Id:       id,
+			Event:    domain.EventItemCompleted,
+			Payload:  payload,
+			Attempts: attempts,
+			URL:      server.URL + path,
+			Secret:   secret,
+		}
+	}
+
+	repo := &webhookRepo{
+		pending: []domain.WebhookDelivery{
+			delivery(1, "/", "good secret", 0),
+			delivery(2, "/rotated", "good secret", 0),
+			delivery(3, "/rotated", "good secret", 2),
+			delivery(4, "/", "good secret", 3),
+		},
+		results: make(map[int]domain.DeliveryResult),
+	}
+
+	d := NewWebhookDispatcher(repo, WebhookConfig{
+		Interval:    time.Hour,
+		BatchSize:   10,
+		MaxAttempts: 4,
+		Timeout:     time.Second,
+		MinBackoff:  time.Minute,
+		MaxBackoff:  time.Hour,
+		Guard:       netguard.Guard{AllowPrivate: true},
+	})
+	d.processPending()
+
+	assert.NoError(t, repo.results[1].Err)
+	assert.Equal(t, http.StatusNoContent, repo.results[1].StatusCode)
+
+	assert.Error(t, repo.results[2].Err)
+	assert.Equal(t, http.StatusUnauthorized, repo.results[2].StatusCode)
+	assert.Equal(t, time.Minute, repo.results[2].RetryIn)
+
+	assert.Error(t, repo.results[3].Err)
+	assert.Equal(t, 4*time.Minute, repo.results[3].RetryIn)
+
+	// the last attempt may still succeed
+	assert.NoError(t, repo.results[4].Err)
+}
+
+func TestWebhookDispatcher_GivesUp(t *testing.T) {
+	repo := &webhookRepo{
+		pending: []domain.WebhookDelivery{
+			{Id: 1, Attempts: 3, URL: "http://127.0.0.1:0", Payload: []byte("{}")},
+		},
+		results: make(map[int]domain.DeliveryResult),
+	}
+
+	d := NewWebhookDispatcher(repo, WebhookConfig{
+		Interval:    time.Hour,
+		BatchSize:   10,
+		MaxAttempts: 4,
+		Timeout:     time.Second,
+		MinBackoff:  time.Minute,
+		MaxBackoff:  time.Hour,
+	})
+	d.processPending()
+
+	assert.Error(t, repo.results[1].Err)
+	assert.Zero(t, repo.results[1].StatusCode)
+	assert.Zero(t, repo.results[1].RetryIn)
+}
+
+func TestWebhookDispatcher_PrivateAddress(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	repo := &webhookRepo{
+		pending: []domain.WebhookDelivery{
+			{Id: 1, URL: server.URL, Payload: []byte("{}")},
+		},
+		results: make(map[int]domain.DeliveryResult),
+	}
+
+	d := NewWebhookDispatcher(repo, WebhookConfig{
+		Interval:    time.Hour,
+		BatchSize:   10,
+		MaxAttempts: 4,
+		Timeout:     time.Second,
+		MinBackoff:  time.Minute,
+		MaxBackoff:  time.Hour,
+	})
+	d.processPending()
+
+	assert.True(t, errors.Is(repo.results[1].Err, netguard.ErrForbiddenAddress))
+	assert.Zero(t, requests)
+}
+
+func TestWebhookDispatcher_backoff(t *testing.T) {
+	d := NewWebhookDispatcher(nil, WebhookConfig{
+		Interval:   time.Hour,
+		MinBackoff: 30 * time.Second,
+		MaxBackoff: 10 * time.Minute,
+	})
+
+	expected := []time.Duration{
+		30 * time.Second,
+		time.Minute,
+		2 * time.Minute,
+		4 * time.Minute,
+		8 * time.Minute,
+		10 * time.Minute,
+		10 * time.Minute,
+	}
+	for i, delay := range expected {
+		assert.Equal(t, delay, d.backoff(i+1), "attempt %d", i+1)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"a":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=aa9e2e3575f5d7098b6caccd790888c36d5fdb63342a73bada2d6a51747a8494",
+		Sign("secret", []byte(`{"a":1}`)))
+}
@@ -16,7 +16,7 @@ func (s *Server) Run(port string, handler http.Handler) error {
 		Handler: handler,
 		MaxHeaderBytes: 1 << 20, // 1 MB
 		ReadTimeout:    10 * time.Second,
-		WriteTimeout:   10 * time.Second,
+		// no WriteTimeout, it would cut the event streams of /api/stream
 	}
 
 	return s.httpServer.ListenAndServe()
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+)
+
+var ErrInvalidAccessToken = domain.NewError(domain.ErrUnauthorized, "invalid access token")
+
+type AccessTokenService struct {
+	repo repository.AccessToken
+}
+
+func NewAccessTokenService(repo repository.AccessToken) *AccessTokenService {
+	return &AccessTokenService{repo: repo}
+}
+
+// Create issues a token to the caller. A caller authenticated with a personal
+// access token can only grant the scopes that token holds.
+func (s *AccessTokenService) Create(identity domain.Identity,
+	input domain.CreateAccessTokenInput) (domain.CreatedAccessToken, error) {
+	if err := input.Validate(); err != nil {
+		return domain.CreatedAccessToken{}, err
+	}
+
+	for _, scope := range input.Scopes {
+		if !identity.HasScope(scope) {
+			return domain.CreatedAccessToken{}, domain.NewError(domain.ErrForbidden, "token can not grant scope "+scope)
+		}
+	}
+
+	secret, err := generateRandomToken()
+	if err != nil {
+		return domain.CreatedAccessToken{}, err
+	}
+
+	plain := domain.AccessTokenPrefix + secret
+	token, err := s.repo.Create(identity.UserId, hashToken(plain), input)
+	if err != nil {
+		return domain.CreatedAccessToken{}, err
+	}
+
+	return domain.CreatedAccessToken{AccessToken: token, Token: plain}, nil
+}
+
+func (s *AccessTokenService) GetAll(userId int) ([]domain.AccessToken, error) {
+	return s.repo.GetAll(userId)
+}
+
+func (s *AccessTokenService) Revoke(userId, tokenId int) error {
+	return s.repo.Revoke(userId, tokenId)
+}
+
+func (s *AccessTokenService) ParseToken(token string) (domain.Identity, error) {
+	identity, err := s.repo.Use(hashToken(token))
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.Identity{}, ErrInvalidAccessToken
+	}
+
+	return identity, err
+}
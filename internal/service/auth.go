@@ -1,81 +1,240 @@
 package service
 
 import (
-	"crypto/sha1"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"fmt"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/hash"
+	"github.com/pavel-trbv/go-todo-app/internal/keys"
 	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/sirupsen/logrus"
 	"time"
 )
 
-const (
-	salt       = "fsdf7ashagbsv789sa11"
-	tokenTTL   = 12 * time.Hour
-	signingKey = "sfnfi0ew&#$123mfg#fnmfgf1544"
+var (
+	ErrInvalidCredentials  = domain.NewError(domain.ErrUnauthorized, "invalid username or password")
+	ErrInvalidRefreshToken = domain.NewError(domain.ErrUnauthorized, "invalid refresh token")
+	ErrRefreshTokenReused  = domain.NewError(domain.ErrUnauthorized, "refresh token reuse detected")
+	ErrSessionRevoked      = domain.NewError(domain.ErrUnauthorized, "session is revoked")
+	ErrInvalidToken        = domain.NewError(domain.ErrUnauthorized, "invalid token")
 )
 
 type tokenClaims struct {
 	jwt.StandardClaims
-	UserId int `json:"user_id"`
+	UserId    int `json:"user_id"`
+	SessionId int `json:"sid"`
 }
 
 type AuthService struct {
-	repo repository.Authorization
+	repo            repository.Authorization
+	sessionRepo     repository.Session
+	hasher          hash.PasswordHasher
+	keySet          *keys.KeySet
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
 }
 
-func NewAuthService(repo repository.Authorization) *AuthService {
-	return &AuthService{repo: repo}
+func NewAuthService(repo repository.Authorization, sessionRepo repository.Session, hasher hash.PasswordHasher,
+	keySet *keys.KeySet, accessTokenTTL, refreshTokenTTL time.Duration) *AuthService {
+	return &AuthService{
+		repo:            repo,
+		sessionRepo:     sessionRepo,
+		hasher:          hasher,
+		keySet:          keySet,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+	}
 }
 
 func (s *AuthService) CreateUser(user domain.User) (int, error) {
-	user.Password = generatePasswordHash(user.Password)
+	passwordHash, err := s.hasher.Hash(user.Password)
+	if err != nil {
+		return 0, err
+	}
+
+	user.PasswordHash = passwordHash
 	return s.repo.CreateUser(user)
 }
 
-func (s *AuthService) GenerateToken(username, password string) (string, error) {
-	user, err := s.repo.GetUser(username, generatePasswordHash(password))
+func (s *AuthService) GenerateTokens(username, password string) (domain.Tokens, error) {
+	user, err := s.repo.GetUser(username)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Tokens{}, ErrInvalidCredentials
+		}
+		return domain.Tokens{}, err
+	}
 
+	// a hash no algorithm recognizes, like a legacy one when PASSWORD_SALT is
+	// not set, can not be verified, the user can not log in
+	ok, err := s.hasher.Verify(password, user.PasswordHash)
+	if errors.Is(err, hash.ErrUnknownAlgorithm) {
+		logrus.Warnf("can not verify password of user %d: %s", user.Id, err.Error())
+		return domain.Tokens{}, ErrInvalidCredentials
+	}
 	if err != nil {
-		return "", err
+		return domain.Tokens{}, err
+	}
+	if !ok {
+		return domain.Tokens{}, ErrInvalidCredentials
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(12 * time.Hour).Unix(),
-			IssuedAt:  time.Now().Unix(),
-		},
-		user.Id,
+	if s.hasher.NeedsRehash(user.PasswordHash) {
+		s.rehashPassword(user.Id, password)
+	}
+
+	refreshToken, err := generateRandomToken()
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	sessionId, err := s.sessionRepo.Create(user.Id, domain.RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
 	})
+	if err != nil {
+		return domain.Tokens{}, err
+	}
 
-	return token.SignedString([]byte(signingKey))
+	return s.newTokens(user.Id, sessionId, refreshToken)
 }
 
-func (s *AuthService) ParseToken(accessToken string) (int, error) {
-	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
+func (s *AuthService) RefreshTokens(refreshToken string) (domain.Tokens, error) {
+	token, err := s.sessionRepo.GetRefreshToken(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Tokens{}, ErrInvalidRefreshToken
 		}
+		return domain.Tokens{}, err
+	}
+
+	if token.RevokedAt != nil {
+		return domain.Tokens{}, ErrSessionRevoked
+	}
+
+	if token.UsedAt != nil {
+		return domain.Tokens{}, s.revokeReusedSession(token.SessionId)
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return domain.Tokens{}, ErrInvalidRefreshToken
+	}
 
-		return []byte(signingKey), nil
+	nextRefreshToken, err := generateRandomToken()
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	rotated, err := s.sessionRepo.Rotate(token.Id, domain.RefreshToken{
+		SessionId: token.SessionId,
+		TokenHash: hashToken(nextRefreshToken),
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
 	})
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+	if !rotated {
+		// the token was used by a concurrent request between the lookup and the rotation
+		return domain.Tokens{}, s.revokeReusedSession(token.SessionId)
+	}
 
+	return s.newTokens(token.UserId, token.SessionId, nextRefreshToken)
+}
+
+func (s *AuthService) ParseToken(accessToken string) (domain.Identity, error) {
+	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, s.keySet.Keyfunc)
 	if err != nil {
-		return 0, err
+		return domain.Identity{}, ErrInvalidToken
 	}
 
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
-		return 0, errors.New("token claims are not of type *tokenClaims")
+		return domain.Identity{}, errors.New("token claims are not of type *tokenClaims")
+	}
+
+	active, err := s.sessionRepo.IsActive(claims.SessionId)
+	if err != nil {
+		return domain.Identity{}, err
+	}
+	if !active {
+		return domain.Identity{}, ErrSessionRevoked
+	}
+
+	return domain.Identity{UserId: claims.UserId, SessionId: claims.SessionId}, nil
+}
+
+func (s *AuthService) Logout(sessionId int) error {
+	return s.sessionRepo.Revoke(sessionId)
+}
+
+func (s *AuthService) LogoutAll(userId int) error {
+	return s.sessionRepo.RevokeAll(userId)
+}
+
+func (s *AuthService) JWKS() keys.JWKS {
+	return s.keySet.JWKS()
+}
+
+func (s *AuthService) newTokens(userId, sessionId int, refreshToken string) (domain.Tokens, error) {
+	accessToken, err := s.keySet.Sign(&tokenClaims{
+		jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(s.accessTokenTTL).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		userId,
+		sessionId,
+	})
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	return domain.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// revokeReusedSession kills the whole session when an already rotated refresh
+// token is presented again, since one of its copies has probably been stolen.
+func (s *AuthService) revokeReusedSession(sessionId int) error {
+	if err := s.sessionRepo.Revoke(sessionId); err != nil {
+		return err
+	}
+
+	return ErrRefreshTokenReused
+}
+
+// rehashPassword upgrades a stored hash to the preferred algorithm. Failures
+// are only logged, the user has already been authenticated at this point.
+func (s *AuthService) rehashPassword(userId int, password string) {
+	passwordHash, err := s.hasher.Hash(password)
+	if err != nil {
+		logrus.Errorf("failed to rehash password of user %d: %s", userId, err.Error())
+		return
 	}
 
-	return claims.UserId, nil
+	if err := s.repo.UpdatePasswordHash(userId, passwordHash); err != nil {
+		logrus.Errorf("failed to update password hash of user %d: %s", userId, err.Error())
+	}
 }
 
-func generatePasswordHash(password string) string {
-	hash := sha1.New()
-	hash.Write([]byte(password))
+// generateRandomToken returns an url safe secret of 256 bits used for refresh
+// and personal access tokens. Only its sha256 hash is persisted.
+func generateRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
 
-	return fmt.Sprintf("%x", hash.Sum([]byte(salt)))
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
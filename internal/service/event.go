@@ -0,0 +1,34 @@
+package service
+
+import (
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// EventService stores events for the change stream.
+type EventService struct {
+	repo repository.Event
+}
+
+func NewEventService(repo repository.Event) *EventService {
+	return &EventService{repo: repo}
+}
+
+func (s *EventService) Publish(event domain.Event) {
+	if err := s.repo.Store(event); err != nil {
+		logrus.Errorf("failed to store %s event: %s", event.Type, err.Error())
+	}
+}
+
+// publishers hands every event to each of them, stamped with a single time.
+type publishers []EventPublisher
+
+func (p publishers) Publish(event domain.Event) {
+	event.CreatedAt = time.Now().UTC()
+
+	for _, publisher := range p {
+		publisher.Publish(event)
+	}
+}
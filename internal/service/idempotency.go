@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"time"
+)
+
+// IdempotencyService keeps responses to requests made with an idempotency key
+// for ttl, retries within that window get the stored response.
+type IdempotencyService struct {
+	repo repository.Idempotency
+	ttl  time.Duration
+}
+
+func NewIdempotencyService(repo repository.Idempotency, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{repo: repo, ttl: ttl}
+}
+
+func (s *IdempotencyService) Lock(ctx context.Context, userId int, key string) (func(), error) {
+	return s.repo.Lock(ctx, userId, key)
+}
+
+// Get returns the stored response for the key, nil when there is none.
+func (s *IdempotencyService) Get(userId int, key string) (*domain.IdempotentResponse, error) {
+	response, err := s.repo.Get(userId, key, s.ttl)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (s *IdempotencyService) Save(userId int, key string, response domain.IdempotentResponse) error {
+	return s.repo.Save(userId, key, response, s.ttl)
+}
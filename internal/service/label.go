@@ -0,0 +1,54 @@
+package service
+
+import (
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+)
+
+type LabelService struct {
+	repo repository.Label
+}
+
+func NewLabelService(repo repository.Label) *LabelService {
+	return &LabelService{repo: repo}
+}
+
+func (s *LabelService) Create(userId int, label domain.Label) (int, error) {
+	if err := label.Validate(); err != nil {
+		return 0, err
+	}
+
+	if label.Color == "" {
+		label.Color = domain.DefaultLabelColor
+	}
+
+	return s.repo.Create(userId, label)
+}
+
+func (s *LabelService) GetAll(userId int) ([]domain.Label, error) {
+	return s.repo.GetAll(userId)
+}
+
+func (s *LabelService) GetById(userId, labelId int) (domain.Label, error) {
+	return s.repo.GetById(userId, labelId)
+}
+
+func (s *LabelService) Update(userId, labelId int, input domain.UpdateLabelInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	return s.repo.Update(userId, labelId, input.Patch())
+}
+
+func (s *LabelService) Delete(userId, labelId int) error {
+	return s.repo.Delete(userId, labelId)
+}
+
+func (s *LabelService) Attach(userId, itemId, labelId int) error {
+	return s.repo.Attach(userId, itemId, labelId)
+}
+
+func (s *LabelService) Detach(userId, itemId, labelId int) error {
+	return s.repo.Detach(userId, itemId, labelId)
+}
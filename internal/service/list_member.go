@@ -0,0 +1,54 @@
+package service
+
+import (
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+)
+
+type ListMemberService struct {
+	repo repository.ListMember
+}
+
+func NewListMemberService(repo repository.ListMember) *ListMemberService {
+	return &ListMemberService{repo: repo}
+}
+
+func (s *ListMemberService) GetAll(userId, listId int) ([]domain.ListMember, error) {
+	return s.repo.GetAll(userId, listId)
+}
+
+func (s *ListMemberService) Invite(userId, listId int, input domain.InviteMemberInput) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+
+	return s.repo.Invite(userId, listId, input)
+}
+
+func (s *ListMemberService) GetInvitations(userId int) ([]domain.Invitation, error) {
+	return s.repo.GetInvitations(userId)
+}
+
+func (s *ListMemberService) AcceptInvitation(userId, invitationId int) error {
+	return s.repo.AcceptInvitation(userId, invitationId)
+}
+
+func (s *ListMemberService) DeclineInvitation(userId, invitationId int) error {
+	return s.repo.DeclineInvitation(userId, invitationId)
+}
+
+func (s *ListMemberService) UpdateRole(userId, listId, memberId int, input domain.UpdateMemberInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateRole(userId, listId, memberId, input.Role)
+}
+
+func (s *ListMemberService) Remove(userId, listId, memberId int) error {
+	return s.repo.Remove(userId, listId, memberId)
+}
+
+func (s *ListMemberService) Leave(userId, listId int) error {
+	return s.repo.Leave(userId, listId)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"reflect"
+)
+
+// patchAttempts bounds how often a patch without a version is reapplied to a
+// list or an item changed meanwhile.
+const patchAttempts = 3
+
+// applyPatch applies a patch to the JSON of a list or an item and returns the
+// top level fields it changed, the removed ones as null.
+func applyPatch(entity interface{}, input domain.PatchInput) (map[string]json.RawMessage, error) {
+	original, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch input.ContentType {
+	case domain.MergePatchType:
+		patched, err = jsonpatch.MergePatch(original, input.Body)
+	case domain.JSONPatchType:
+		var patch jsonpatch.Patch
+		if patch, err = jsonpatch.DecodePatch(input.Body); err == nil {
+			patched, err = patch.Apply(original)
+		}
+	default:
+		return nil, domain.NewError(domain.ErrValidation, "unsupported patch type "+input.ContentType)
+	}
+	if err != nil {
+		return nil, domain.NewError(domain.ErrValidation, "invalid patch: "+err.Error())
+	}
+
+	var before, after map[string]interface{}
+	if err := json.Unmarshal(original, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return nil, domain.NewError(domain.ErrValidation, "invalid patch: the result is not an object")
+	}
+
+	changes := make(map[string]json.RawMessage)
+	for field, value := range after {
+		if old, ok := before[field]; ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+
+		if changes[field], err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+	for field := range before {
+		if _, ok := after[field]; !ok {
+			changes[field] = json.RawMessage("null")
+		}
+	}
+
+	return changes, nil
+}
+
+// retryPatch runs a patch until it does not conflict with a concurrent
+// change. A patch of a given version is run once, its conflict is reported.
+func retryPatch(input domain.PatchInput, patch func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := patch()
+		if input.Version != nil || attempt == patchAttempts || !errors.Is(err, domain.ErrVersionMismatch) {
+			return err
+		}
+	}
+}
+
+// patchVersion is the version a patch is applied to: the one given, or else
+// the one it was computed from.
+func patchVersion(input domain.PatchInput, loaded int) *int {
+	if input.Version != nil {
+		return input.Version
+	}
+
+	return &loaded
+}
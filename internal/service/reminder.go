@@ -0,0 +1,46 @@
+package service
+
+import (
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/netguard"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+)
+
+type ReminderService struct {
+	repo         repository.Reminder
+	guard        netguard.Guard
+	emailDomains []string
+}
+
+// NewReminderService creates the service. Email reminders can only be sent
+// to addresses at emailDomains.
+func NewReminderService(repo repository.Reminder, guard netguard.Guard, emailDomains []string) *ReminderService {
+	return &ReminderService{repo: repo, guard: guard, emailDomains: emailDomains}
+}
+
+func (s *ReminderService) Create(userId, itemId int, reminder domain.Reminder) (int, error) {
+	if err := reminder.Validate(); err != nil {
+		return 0, err
+	}
+
+	switch reminder.Channel {
+	case domain.ChannelWebhook:
+		if err := checkURL(s.guard, "target", reminder.Target); err != nil {
+			return 0, err
+		}
+	case domain.ChannelEmail:
+		if !domain.EmailAllowed(reminder.Target, s.emailDomains) {
+			return 0, domain.NewError(domain.ErrValidation, "email reminders can not be sent to this address")
+		}
+	}
+
+	return s.repo.Create(userId, itemId, reminder)
+}
+
+func (s *ReminderService) GetAll(userId, itemId int) ([]domain.Reminder, error) {
+	return s.repo.GetAll(userId, itemId)
+}
+
+func (s *ReminderService) Delete(userId, reminderId int) error {
+	return s.repo.Delete(userId, reminderId)
+}
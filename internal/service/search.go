@@ -0,0 +1,22 @@
+package service
+
+import (
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+)
+
+type SearchService struct {
+	repo repository.Search
+}
+
+func NewSearchService(repo repository.Search) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+func (s *SearchService) Search(userId int, input domain.SearchInput) ([]domain.SearchResult, error) {
+	if err := input.Normalize(); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Search(userId, input)
+}
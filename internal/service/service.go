@@ -1,44 +1,174 @@
 package service
 
 import (
+	"context"
 	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/hash"
+	"github.com/pavel-trbv/go-todo-app/internal/keys"
+	"github.com/pavel-trbv/go-todo-app/internal/netguard"
 	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/pavel-trbv/go-todo-app/internal/stream"
+	"time"
 )
 
 //go:generate mockgen -source=service.go -destination=mocks/mock.go
 
 type Authorization interface {
 	CreateUser(user domain.User) (int, error)
-	GenerateToken(username, password string) (string, error)
-	ParseToken(token string) (int, error)
+	GenerateTokens(username, password string) (domain.Tokens, error)
+	RefreshTokens(refreshToken string) (domain.Tokens, error)
+	ParseToken(token string) (domain.Identity, error)
+	Logout(sessionId int) error
+	LogoutAll(userId int) error
+	JWKS() keys.JWKS
+}
+
+type AccessToken interface {
+	Create(identity domain.Identity, input domain.CreateAccessTokenInput) (domain.CreatedAccessToken, error)
+	GetAll(userId int) ([]domain.AccessToken, error)
+	Revoke(userId, tokenId int) error
+	ParseToken(token string) (domain.Identity, error)
 }
 
 type TodoList interface {
 	Create(userId int, list domain.TodoList) (int, error)
-	GetAll(userId int) ([]domain.TodoList, error)
+	GetAll(userId int, filter domain.ListFilter) (domain.ListPage, error)
 	GetById(userId, listId int) (domain.TodoList, error)
-	Delete(userId, listId int) error
+	Delete(userId, listId int, version *int) error
 	Update(userId, listId int, input domain.UpdateListInput) error
+	Patch(userId, listId int, input domain.PatchInput) error
+	Move(userId, listId int, input domain.MoveInput) error
+	Duplicate(userId, listId int, input domain.DuplicateListInput) (int, error)
+}
+
+type ListMember interface {
+	GetAll(userId, listId int) ([]domain.ListMember, error)
+	Invite(userId, listId int, input domain.InviteMemberInput) (int, error)
+	GetInvitations(userId int) ([]domain.Invitation, error)
+	AcceptInvitation(userId, invitationId int) error
+	DeclineInvitation(userId, invitationId int) error
+	UpdateRole(userId, listId, memberId int, input domain.UpdateMemberInput) error
+	Remove(userId, listId, memberId int) error
+	Leave(userId, listId int) error
 }
 
 type TodoItem interface {
 	Create(userId, listId int, item domain.TodoItem) (int, error)
-	GetAll(userId, listId int) ([]domain.TodoItem, error)
+	GetAll(userId, listId int, filter domain.ItemFilter) (domain.ItemPage, error)
+	GetAllByLabel(userId, labelId int, filter domain.ItemFilter) (domain.ItemPage, error)
 	GetById(userId, itemId int) (domain.TodoItem, error)
-	Delete(userId, itemId int) error
+	Delete(userId, itemId int, version *int) error
 	Update(userId, itemId int, input domain.UpdateItemInput) error
+	Patch(userId, itemId int, input domain.PatchInput) error
+	CreateChild(userId, parentId int, item domain.TodoItem) (int, error)
+	GetChildren(userId, parentId int) ([]domain.TodoItem, error)
+	ReorderChildren(userId, parentId int, ids []int) error
+	Move(userId, itemId int, input domain.MoveInput) error
+	Copy(userId, itemId int, input domain.CopyItemInput) (int, error)
+	BulkCreate(userId, listId int, input domain.BulkCreateItemsInput) (domain.BulkResponse, error)
+	BulkUpdate(userId, listId int, input domain.BulkUpdateItemsInput) (domain.BulkResponse, error)
+	BulkDelete(userId, listId int, input domain.BulkDeleteItemsInput) (domain.BulkResponse, error)
+	ClearCompleted(userId, listId int) (domain.BulkResponse, error)
+}
+
+type Label interface {
+	Create(userId int, label domain.Label) (int, error)
+	GetAll(userId int) ([]domain.Label, error)
+	GetById(userId, labelId int) (domain.Label, error)
+	Update(userId, labelId int, input domain.UpdateLabelInput) error
+	Delete(userId, labelId int) error
+	Attach(userId, itemId, labelId int) error
+	Detach(userId, itemId, labelId int) error
+}
+
+type Reminder interface {
+	Create(userId, itemId int, reminder domain.Reminder) (int, error)
+	GetAll(userId, itemId int) ([]domain.Reminder, error)
+	Delete(userId, reminderId int) error
+}
+
+type Webhook interface {
+	Create(userId int, input domain.CreateWebhookInput) (domain.CreatedWebhook, error)
+	GetAll(userId int) ([]domain.Webhook, error)
+	GetById(userId, webhookId int) (domain.Webhook, error)
+	Update(userId, webhookId int, input domain.UpdateWebhookInput) error
+	Delete(userId, webhookId int) error
+	GetDeliveries(userId, webhookId int) ([]domain.WebhookDelivery, error)
+	Redeliver(userId, webhookId, deliveryId int) error
+}
+
+// EventPublisher is told about changes of lists and items once they are
+// committed.
+type EventPublisher interface {
+	Publish(event domain.Event)
+}
+
+type Stream interface {
+	Subscribe(userId int, lastEventId int64) (*stream.Subscription, error)
+}
+
+type Search interface {
+	Search(userId int, input domain.SearchInput) ([]domain.SearchResult, error)
+}
+
+type Sync interface {
+	GetChanges(userId int, token string) (domain.SyncChanges, error)
+	Apply(userId int, input domain.SyncInput) ([]domain.SyncResult, error)
+}
+
+type Idempotency interface {
+	Lock(ctx context.Context, userId int, key string) (func(), error)
+	Get(userId int, key string) (*domain.IdempotentResponse, error)
+	Save(userId int, key string, response domain.IdempotentResponse) error
 }
 
 type Service struct {
 	Authorization
+	AccessToken
 	TodoList
+	ListMember
 	TodoItem
+	Label
+	Reminder
+	Webhook
+	Stream
+	Sync
+	Idempotency
+	Search
 }
 
-func NewService(repos *repository.Repository) *Service {
+type Deps struct {
+	Hasher          hash.PasswordHasher
+	Keys            *keys.KeySet
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	Stream          Stream
+	IdempotencyTTL  time.Duration
+	// Guard limits the addresses of the URLs users give
+	Guard netguard.Guard
+	// EmailDomains are the domains email reminders can be sent to
+	EmailDomains []string
+}
+
+func NewService(repos *repository.Repository, deps Deps) *Service {
+	webhooks := NewWebhookService(repos.Webhook, deps.Guard)
+	events := publishers{webhooks, NewEventService(repos.Event)}
+	lists := NewTodoListService(repos.TodoList, events)
+	items := NewTodoItemService(repos.TodoItem, repos.TodoList, repos.Label, events)
+
 	return &Service{
-		Authorization: NewAuthService(repos.Authorization),
-		TodoList:      NewTodoListService(repos.TodoList),
-		TodoItem:      NewTodoItemService(repos.TodoItem, repos.TodoList),
+		Authorization: NewAuthService(repos.Authorization, repos.Session, deps.Hasher,
+			deps.Keys, deps.AccessTokenTTL, deps.RefreshTokenTTL),
+		AccessToken: NewAccessTokenService(repos.AccessToken),
+		TodoList:    lists,
+		ListMember:  NewListMemberService(repos.ListMember),
+		TodoItem:    items,
+		Label:       NewLabelService(repos.Label),
+		Reminder:    NewReminderService(repos.Reminder, deps.Guard, deps.EmailDomains),
+		Webhook:     webhooks,
+		Stream:      deps.Stream,
+		Sync:        NewSyncService(repos.Sync, lists, items),
+		Idempotency: NewIdempotencyService(repos.Idempotency, deps.IdempotencyTTL),
+		Search:      NewSearchService(repos.Search),
 	}
 }
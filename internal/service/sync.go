@@ -0,0 +1,170 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// SyncService applies mutations through the list and item services, so they
+// are validated and announced like any other change.
+type SyncService struct {
+	repo  repository.Sync
+	lists TodoList
+	items TodoItem
+}
+
+func NewSyncService(repo repository.Sync, lists TodoList, items TodoItem) *SyncService {
+	return &SyncService{repo: repo, lists: lists, items: items}
+}
+
+func (s *SyncService) GetChanges(userId int, token string) (domain.SyncChanges, error) {
+	return s.repo.GetChanges(userId, token)
+}
+
+// Apply applies the mutations in order, each one on its own: a mutation that
+// fails does not stop the ones after it, its result tells why it failed.
+func (s *SyncService) Apply(userId int, input domain.SyncInput) ([]domain.SyncResult, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	// ids of the lists created so far, by ref
+	created := make(map[string]int)
+
+	results := make([]domain.SyncResult, len(input.Mutations))
+	for i, m := range input.Mutations {
+		id, err := s.apply(userId, m, created)
+		if err == nil && m.Entity == domain.EntityList && m.Op == domain.SyncCreate {
+			created[m.Ref] = id
+		}
+
+		results[i] = s.result(userId, m, id, err)
+	}
+
+	return results, nil
+}
+
+func (s *SyncService) apply(userId int, m domain.SyncMutation, created map[string]int) (int, error) {
+	switch m.Entity + " " + m.Op {
+	case domain.EntityList + " " + domain.SyncCreate:
+		var list domain.TodoList
+		if err := decodeSyncData(m, &list); err != nil {
+			return 0, err
+		}
+		if list.Title == "" {
+			return 0, domain.NewError(domain.ErrValidation, "title must not be empty")
+		}
+
+		return s.lists.Create(userId, list)
+	case domain.EntityList + " " + domain.SyncUpdate:
+		var input domain.UpdateListInput
+		if err := decodeSyncData(m, &input); err != nil {
+			return 0, err
+		}
+		input.Version = m.Version
+
+		return m.Id, s.lists.Update(userId, m.Id, input)
+	case domain.EntityList + " " + domain.SyncDelete:
+		return m.Id, ignoreNotFound(s.lists.Delete(userId, m.Id, m.Version))
+	case domain.EntityItem + " " + domain.SyncCreate:
+		var item domain.TodoItem
+		if err := decodeSyncData(m, &item); err != nil {
+			return 0, err
+		}
+		if item.Title == "" {
+			return 0, domain.NewError(domain.ErrValidation, "title must not be empty")
+		}
+
+		if item.ParentId != nil {
+			return s.items.CreateChild(userId, *item.ParentId, item)
+		}
+
+		listId := m.ListId
+		if m.ListRef != "" {
+			var ok bool
+			if listId, ok = created[m.ListRef]; !ok {
+				return 0, domain.NewError(domain.ErrValidation, fmt.Sprintf("list_ref %s is not a list created before", m.ListRef))
+			}
+		}
+
+		return s.items.Create(userId, listId, item)
+	case domain.EntityItem + " " + domain.SyncUpdate:
+		var input domain.UpdateItemInput
+		if err := decodeSyncData(m, &input); err != nil {
+			return 0, err
+		}
+		input.Version = m.Version
+
+		return m.Id, s.items.Update(userId, m.Id, input)
+	default:
+		return m.Id, ignoreNotFound(s.items.Delete(userId, m.Id, m.Version))
+	}
+}
+
+func decodeSyncData(m domain.SyncMutation, v interface{}) error {
+	if len(m.Data) == 0 {
+		return domain.NewError(domain.ErrValidation, "data is required")
+	}
+
+	if err := json.Unmarshal(m.Data, v); err != nil {
+		return domain.NewError(domain.ErrValidation, "invalid data")
+	}
+
+	return nil
+}
+
+// ignoreNotFound lets deleting what is gone already succeed.
+func ignoreNotFound(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+func (s *SyncService) result(userId int, m domain.SyncMutation, id int, err error) domain.SyncResult {
+	result := domain.SyncResult{Ref: m.Ref, Status: domain.SyncApplied, Id: id}
+	if err == nil {
+		return result
+	}
+
+	var domainErr *domain.Error
+	switch {
+	case errors.Is(err, domain.ErrConflict):
+		result.Status = domain.SyncConflict
+		result.Error = err.Error()
+		result.Current = s.current(userId, m)
+	case errors.As(err, &domainErr):
+		result.Status = domain.SyncRejected
+		result.Error = domainErr.Message
+	default:
+		logrus.Errorf("failed to apply sync mutation %s of user %d: %s", m.Ref, userId, err.Error())
+		result.Status = domain.SyncFailed
+		result.Error = "internal error"
+	}
+
+	return result
+}
+
+// current loads the entity a mutation conflicted with, nil when it is gone.
+func (s *SyncService) current(userId int, m domain.SyncMutation) interface{} {
+	if m.Entity == domain.EntityList {
+		list, err := s.lists.GetById(userId, m.Id)
+		if err != nil {
+			return nil
+		}
+
+		return list
+	}
+
+	item, err := s.items.GetById(userId, m.Id)
+	if err != nil {
+		return nil
+	}
+
+	return item
+}
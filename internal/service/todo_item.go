@@ -3,37 +3,87 @@ package service
 import (
 	"github.com/pavel-trbv/go-todo-app/internal/domain"
 	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/sirupsen/logrus"
 )
 
 type TodoItemService struct {
-	repo     repository.TodoItem
-	listRepo repository.TodoList
+	repo      repository.TodoItem
+	listRepo  repository.TodoList
+	labelRepo repository.Label
+	events    EventPublisher
 }
 
-func NewTodoItemService(repo repository.TodoItem, listRepo repository.TodoList) *TodoItemService {
-	return &TodoItemService{repo: repo, listRepo: listRepo}
+func NewTodoItemService(repo repository.TodoItem, listRepo repository.TodoList,
+	labelRepo repository.Label, events EventPublisher) *TodoItemService {
+	return &TodoItemService{repo: repo, listRepo: listRepo, labelRepo: labelRepo, events: events}
 }
 
 func (s *TodoItemService) Create(userId, listId int, item domain.TodoItem) (int, error) {
-	_, err := s.listRepo.GetById(userId, listId)
+	if err := item.Validate(); err != nil {
+		return 0, err
+	}
+
+	if item.Priority == "" {
+		item.Priority = domain.PriorityNone
+	}
+
+	if item.Timezone == "" {
+		item.Timezone = domain.DefaultTimezone
+	}
+
+	id, err := s.repo.Create(userId, listId, item)
 	if err != nil {
-		// list does not exist or not belongs to user
 		return 0, err
 	}
 
-	return s.repo.Create(listId, item)
+	s.publish(domain.EventItemCreated, userId, listId, id)
+
+	return id, nil
+}
+
+func (s *TodoItemService) GetAll(userId, listId int, filter domain.ItemFilter) (domain.ItemPage, error) {
+	if err := filter.Normalize(); err != nil {
+		return domain.ItemPage{}, err
+	}
+
+	return s.repo.GetAll(userId, listId, filter)
 }
 
-func (s *TodoItemService) GetAll(userId, listId int) ([]domain.TodoItem, error) {
-	return s.repo.GetAll(userId, listId)
+func (s *TodoItemService) GetAllByLabel(userId, labelId int, filter domain.ItemFilter) (domain.ItemPage, error) {
+	if err := filter.Normalize(); err != nil {
+		return domain.ItemPage{}, err
+	}
+
+	// an unknown label must not look like a label without items
+	if _, err := s.labelRepo.GetById(userId, labelId); err != nil {
+		return domain.ItemPage{}, err
+	}
+
+	return s.repo.GetAllByLabel(userId, labelId, filter)
 }
 
 func (s *TodoItemService) GetById(userId, itemId int) (domain.TodoItem, error) {
 	return s.repo.GetById(userId, itemId)
 }
 
-func (s *TodoItemService) Delete(userId, itemId int) error {
-	return s.repo.Delete(userId, itemId)
+func (s *TodoItemService) Delete(userId, itemId int, version *int) error {
+	listId, err := s.repo.GetListId(userId, itemId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(userId, itemId, version); err != nil {
+		return err
+	}
+
+	s.events.Publish(domain.Event{
+		Type:    domain.EventItemDeleted,
+		ActorId: userId,
+		ListId:  listId,
+		Data:    map[string]int{"id": itemId},
+	})
+
+	return nil
 }
 
 func (s *TodoItemService) Update(userId, itemId int, input domain.UpdateItemInput) error {
@@ -41,5 +91,286 @@ func (s *TodoItemService) Update(userId, itemId int, input domain.UpdateItemInpu
 		return err
 	}
 
-	return s.repo.Update(userId, itemId, input)
+	listId, err := s.repo.GetListId(userId, itemId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(userId, itemId, input.Patch(), input.Version); err != nil {
+		return err
+	}
+
+	eventType := domain.EventItemUpdated
+	if input.Done != nil && *input.Done {
+		eventType = domain.EventItemCompleted
+	}
+	s.publish(eventType, userId, listId, itemId)
+
+	return nil
+}
+
+// Patch applies a JSON merge patch or a JSON patch to the item.
+func (s *TodoItemService) Patch(userId, itemId int, input domain.PatchInput) error {
+	listId, err := s.repo.GetListId(userId, itemId)
+	if err != nil {
+		return err
+	}
+
+	var patch domain.Patch
+	err = retryPatch(input, func() error {
+		item, err := s.repo.GetById(userId, itemId)
+		if err != nil {
+			return err
+		}
+
+		changes, err := applyPatch(item, input)
+		if err != nil {
+			return err
+		}
+
+		if patch, err = domain.NewItemPatch(changes); err != nil || len(patch) == 0 {
+			return err
+		}
+
+		return s.repo.Update(userId, itemId, patch, patchVersion(input, item.Version))
+	})
+	if err != nil || len(patch) == 0 {
+		return err
+	}
+
+	eventType := domain.EventItemUpdated
+	if done, ok := patch["done"].(bool); ok && done {
+		eventType = domain.EventItemCompleted
+	}
+	s.publish(eventType, userId, listId, itemId)
+
+	return nil
+}
+
+func (s *TodoItemService) CreateChild(userId, parentId int, item domain.TodoItem) (int, error) {
+	if err := item.Validate(); err != nil {
+		return 0, err
+	}
+
+	if item.Priority == "" {
+		item.Priority = domain.PriorityNone
+	}
+
+	if item.Timezone == "" {
+		item.Timezone = domain.DefaultTimezone
+	}
+
+	listId, err := s.repo.GetListId(userId, parentId)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.CreateChild(userId, parentId, item)
+	if err != nil {
+		return 0, err
+	}
+
+	s.publish(domain.EventItemCreated, userId, listId, id)
+
+	return id, nil
+}
+
+func (s *TodoItemService) GetChildren(userId, parentId int) ([]domain.TodoItem, error) {
+	return s.repo.GetChildren(userId, parentId)
+}
+
+func (s *TodoItemService) ReorderChildren(userId, parentId int, ids []int) error {
+	return s.repo.ReorderChildren(userId, parentId, ids)
+}
+
+func (s *TodoItemService) Move(userId, itemId int, input domain.MoveInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.repo.Move(userId, itemId, input); err != nil {
+		return err
+	}
+
+	listId, err := s.repo.GetListId(userId, itemId)
+	if err != nil {
+		logrus.Errorf("failed to load the list of item %d for an event: %s", itemId, err.Error())
+		return nil
+	}
+	s.publish(domain.EventItemUpdated, userId, listId, itemId)
+
+	return nil
+}
+
+func (s *TodoItemService) Copy(userId, itemId int, input domain.CopyItemInput) (int, error) {
+	id, err := s.repo.Copy(userId, itemId, input)
+	if err != nil {
+		return 0, err
+	}
+
+	listId, err := s.repo.GetListId(userId, id)
+	if err != nil {
+		logrus.Errorf("failed to load the list of item %d for an event: %s", id, err.Error())
+		return id, nil
+	}
+	s.publish(domain.EventItemCreated, userId, listId, id)
+
+	return id, nil
+}
+
+// BulkCreate validates every item before any is created, the ones failing
+// validation are reported along with the results of the others.
+func (s *TodoItemService) BulkCreate(userId, listId int,
+	input domain.BulkCreateItemsInput) (domain.BulkResponse, error) {
+	if err := input.Validate(); err != nil {
+		return domain.BulkResponse{}, err
+	}
+
+	atomic := input.Mode != domain.BulkBestEffort
+
+	response := domain.BulkResponse{Results: make([]domain.BulkResult, len(input.Items))}
+	items := make([]domain.TodoItem, 0, len(input.Items))
+	// indexes[i] is the entry of items[i]
+	indexes := make([]int, 0, len(input.Items))
+
+	for i, item := range input.Items {
+		response.Results[i] = domain.BulkResult{Index: i, Status: domain.BulkSkipped}
+
+		if item.Title == "" {
+			response.Results[i].Status = domain.BulkFailed
+			response.Results[i].Error = "title must not be empty"
+			continue
+		}
+
+		if err := item.Validate(); err != nil {
+			response.Results[i].Status = domain.BulkFailed
+			response.Results[i].Error = err.Error()
+			continue
+		}
+
+		if item.Priority == "" {
+			item.Priority = domain.PriorityNone
+		}
+
+		if item.Timezone == "" {
+			item.Timezone = domain.DefaultTimezone
+		}
+
+		items = append(items, item)
+		indexes = append(indexes, i)
+	}
+
+	if atomic && response.Failed() {
+		return response, nil
+	}
+
+	response.Committed = true
+	if len(items) == 0 {
+		return response, nil
+	}
+
+	created, err := s.repo.BulkCreate(userId, listId, items, atomic)
+	if err != nil {
+		return domain.BulkResponse{}, err
+	}
+
+	response.Committed = created.Committed
+	for i, result := range created.Results {
+		result.Index = indexes[i]
+		response.Results[indexes[i]] = result
+	}
+
+	s.publishBulk(domain.EventItemCreated, userId, listId, created)
+
+	return response, nil
+}
+
+func (s *TodoItemService) BulkUpdate(userId, listId int,
+	input domain.BulkUpdateItemsInput) (domain.BulkResponse, error) {
+	if err := input.Validate(); err != nil {
+		return domain.BulkResponse{}, err
+	}
+
+	response, err := s.repo.BulkUpdate(userId, listId, input.Ids, input.Update.Patch(), input.Mode != domain.BulkBestEffort)
+	if err != nil {
+		return domain.BulkResponse{}, err
+	}
+
+	eventType := domain.EventItemUpdated
+	if input.Update.Done != nil && *input.Update.Done {
+		eventType = domain.EventItemCompleted
+	}
+	s.publishBulk(eventType, userId, listId, response)
+
+	return response, nil
+}
+
+func (s *TodoItemService) BulkDelete(userId, listId int,
+	input domain.BulkDeleteItemsInput) (domain.BulkResponse, error) {
+	if err := input.Validate(); err != nil {
+		return domain.BulkResponse{}, err
+	}
+
+	response, err := s.repo.BulkDelete(userId, listId, input.Ids, input.Mode != domain.BulkBestEffort)
+	if err != nil {
+		return domain.BulkResponse{}, err
+	}
+
+	s.publishBulk(domain.EventItemDeleted, userId, listId, response)
+
+	return response, nil
+}
+
+func (s *TodoItemService) ClearCompleted(userId, listId int) (domain.BulkResponse, error) {
+	ids, err := s.repo.ClearCompleted(userId, listId)
+	if err != nil {
+		return domain.BulkResponse{}, err
+	}
+
+	response := domain.BulkResponse{Committed: true, Results: make([]domain.BulkResult, len(ids))}
+	for i, id := range ids {
+		response.Results[i] = domain.BulkResult{Index: i, Id: id, Status: domain.BulkApplied}
+	}
+
+	s.publishBulk(domain.EventItemDeleted, userId, listId, response)
+
+	return response, nil
+}
+
+// publishBulk announces the applied entries of a committed bulk request.
+func (s *TodoItemService) publishBulk(eventType string, userId, listId int, response domain.BulkResponse) {
+	if !response.Committed {
+		return
+	}
+
+	for _, result := range response.Results {
+		if result.Status != domain.BulkApplied {
+			continue
+		}
+
+		if eventType == domain.EventItemDeleted {
+			s.events.Publish(domain.Event{
+				Type:    domain.EventItemDeleted,
+				ActorId: userId,
+				ListId:  listId,
+				Data:    map[string]int{"id": result.Id},
+			})
+			continue
+		}
+
+		s.publish(eventType, userId, listId, result.Id)
+	}
+}
+
+// publish sends an event carrying the item as it is now. Labels are private
+// to the actor, so they are left out.
+func (s *TodoItemService) publish(eventType string, userId, listId, itemId int) {
+	item, err := s.repo.GetById(userId, itemId)
+	if err != nil {
+		logrus.Errorf("failed to load item %d for a %s event: %s", itemId, eventType, err.Error())
+		return
+	}
+	item.Labels = nil
+
+	s.events.Publish(domain.Event{Type: eventType, ActorId: userId, ListId: listId, Data: item})
 }
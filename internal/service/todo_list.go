@@ -3,30 +3,56 @@ package service
 import (
 	"github.com/pavel-trbv/go-todo-app/internal/domain"
 	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/sirupsen/logrus"
 )
 
 type TodoListService struct {
-	repo repository.TodoList
+	repo   repository.TodoList
+	events EventPublisher
 }
 
-func NewTodoListService(repo repository.TodoList) *TodoListService {
-	return &TodoListService{repo: repo}
+func NewTodoListService(repo repository.TodoList, events EventPublisher) *TodoListService {
+	return &TodoListService{repo: repo, events: events}
 }
 
 func (s *TodoListService) Create(userId int, list domain.TodoList) (int, error) {
-	return s.repo.Create(userId, list)
+	id, err := s.repo.Create(userId, list)
+	if err != nil {
+		return 0, err
+	}
+
+	s.publish(domain.EventListCreated, userId, id)
+
+	return id, nil
 }
 
-func (s *TodoListService) GetAll(userId int) ([]domain.TodoList, error) {
-	return s.repo.GetAll(userId)
+func (s *TodoListService) GetAll(userId int, filter domain.ListFilter) (domain.ListPage, error) {
+	if err := filter.Normalize(); err != nil {
+		return domain.ListPage{}, err
+	}
+
+	return s.repo.GetAll(userId, filter)
 }
 
 func (s *TodoListService) GetById(userId, listId int) (domain.TodoList, error) {
 	return s.repo.GetById(userId, listId)
 }
 
-func (s *TodoListService) Delete(userId, listId int) error {
-	return s.repo.Delete(userId, listId)
+func (s *TodoListService) Delete(userId, listId int, version *int) error {
+	members, err := s.repo.Delete(userId, listId, version)
+	if err != nil {
+		return err
+	}
+
+	s.events.Publish(domain.Event{
+		Type:       domain.EventListDeleted,
+		ActorId:    userId,
+		ListId:     listId,
+		Data:       map[string]int{"id": listId},
+		Recipients: members,
+	})
+
+	return nil
 }
 
 func (s *TodoListService) Update(userId, listId int, input domain.UpdateListInput) error {
@@ -34,5 +60,91 @@ func (s *TodoListService) Update(userId, listId int, input domain.UpdateListInpu
 		return err
 	}
 
-	return s.repo.Update(userId, listId, input)
+	if err := s.repo.Update(userId, listId, input.Patch(), input.Version); err != nil {
+		return err
+	}
+
+	s.publish(domain.EventListUpdated, userId, listId)
+
+	return nil
+}
+
+// Patch applies a JSON merge patch or a JSON patch to the list.
+func (s *TodoListService) Patch(userId, listId int, input domain.PatchInput) error {
+	var patch domain.Patch
+	err := retryPatch(input, func() error {
+		list, err := s.repo.GetById(userId, listId)
+		if err != nil {
+			return err
+		}
+
+		changes, err := applyPatch(list, input)
+		if err != nil {
+			return err
+		}
+
+		if patch, err = domain.NewListPatch(changes); err != nil || len(patch) == 0 {
+			return err
+		}
+
+		return s.repo.Update(userId, listId, patch, patchVersion(input, list.Version))
+	})
+	if err != nil || len(patch) == 0 {
+		return err
+	}
+
+	s.publish(domain.EventListUpdated, userId, listId)
+
+	return nil
+}
+
+func (s *TodoListService) Move(userId, listId int, input domain.MoveInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if input.ListId != nil {
+		return domain.NewError(domain.ErrValidation, "lists can not be moved to a list")
+	}
+
+	return s.repo.Move(userId, listId, input)
+}
+
+func (s *TodoListService) Duplicate(userId, listId int, input domain.DuplicateListInput) (int, error) {
+	if err := input.Validate(); err != nil {
+		return 0, err
+	}
+
+	var title string
+	if input.Title != nil {
+		title = *input.Title
+	} else {
+		list, err := s.repo.GetById(userId, listId)
+		if err != nil {
+			return 0, err
+		}
+		title = domain.CopyTitle(list.Title)
+	}
+
+	id, err := s.repo.Duplicate(userId, listId, title)
+	if err != nil {
+		return 0, err
+	}
+
+	s.publish(domain.EventListCreated, userId, id)
+
+	return id, nil
+}
+
+// publish sends an event carrying the list as it is now. The role and the
+// position are those of the actor, so they are left out.
+func (s *TodoListService) publish(eventType string, userId, listId int) {
+	list, err := s.repo.GetById(userId, listId)
+	if err != nil {
+		logrus.Errorf("failed to load list %d for a %s event: %s", listId, eventType, err.Error())
+		return
+	}
+	list.Role, list.Position = "", ""
+
+	s.events.Publish(domain.Event{Type: eventType, ActorId: userId, ListId: listId, Data: list})
 }
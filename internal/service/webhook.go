@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/netguard"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// deliveriesLimit is how many of the latest deliveries of a webhook are
+// listed.
+const deliveriesLimit = 100
+
+type WebhookService struct {
+	repo  repository.Webhook
+	guard netguard.Guard
+}
+
+func NewWebhookService(repo repository.Webhook, guard netguard.Guard) *WebhookService {
+	return &WebhookService{repo: repo, guard: guard}
+}
+
+func (s *WebhookService) Create(userId int, input domain.CreateWebhookInput) (domain.CreatedWebhook, error) {
+	if err := input.Validate(); err != nil {
+		return domain.CreatedWebhook{}, err
+	}
+
+	if err := checkURL(s.guard, "url", input.URL); err != nil {
+		return domain.CreatedWebhook{}, err
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateRandomToken(); err != nil {
+			return domain.CreatedWebhook{}, err
+		}
+	}
+
+	id, err := s.repo.Create(userId, domain.Webhook{URL: input.URL, Secret: secret, Events: input.Events})
+	if err != nil {
+		return domain.CreatedWebhook{}, err
+	}
+
+	return domain.CreatedWebhook{Id: id, Secret: secret}, nil
+}
+
+func (s *WebhookService) GetAll(userId int) ([]domain.Webhook, error) {
+	return s.repo.GetAll(userId)
+}
+
+func (s *WebhookService) GetById(userId, webhookId int) (domain.Webhook, error) {
+	return s.repo.GetById(userId, webhookId)
+}
+
+func (s *WebhookService) Update(userId, webhookId int, input domain.UpdateWebhookInput) error {
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if input.URL != nil {
+		if err := checkURL(s.guard, "url", *input.URL); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.Update(userId, webhookId, input.Patch())
+}
+
+func (s *WebhookService) Delete(userId, webhookId int) error {
+	return s.repo.Delete(userId, webhookId)
+}
+
+func (s *WebhookService) GetDeliveries(userId, webhookId int) ([]domain.WebhookDelivery, error) {
+	return s.repo.GetDeliveries(userId, webhookId, deliveriesLimit)
+}
+
+func (s *WebhookService) Redeliver(userId, webhookId, deliveryId int) error {
+	return s.repo.Redeliver(userId, webhookId, deliveryId)
+}
+
+// Publish queues the deliveries of an event. The change it describes is
+// already committed, so a failure is logged rather than reported.
+func (s *WebhookService) Publish(event domain.Event) {
+	if err := s.repo.Enqueue(event); err != nil {
+		logrus.Errorf("failed to enqueue %s event: %s", event.Type, err.Error())
+	}
+}
+
+// checkURL rejects URLs whose host resolves to an address of the server's
+// networks. Requests are checked again when they are sent.
+func checkURL(guard netguard.Guard, field, rawURL string) error {
+	if err := guard.CheckURL(context.Background(), rawURL); err != nil {
+		return domain.NewError(domain.ErrValidation, field+" must point to a public address")
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+// Package stream fans stored events out to the change streams of the users
+// connected to this instance.
+package stream
+
+import (
+	"context"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/repository"
+	"github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+var ErrClosed = errors.New("stream broker is closed")
+
+type Config struct {
+	BatchSize int
+	// events are also looked for without a notification, in case one was
+	// lost while the listener reconnected
+	PollInterval time.Duration
+	// how long clients can resume from an event
+	Retention time.Duration
+	// events queued for a subscriber before it is dropped as too slow
+	Buffer int
+	// events replayed at most when a client resumes
+	ReplayLimit int
+}
+
+// Broker reads the events stored by any instance as they are announced and
+// hands them to the subscribers among their recipients.
+type Broker struct {
+	repo          repository.Event
+	notifications <-chan *pq.Notification
+	cfg           Config
+
+	// lastId is only used by Run
+	lastId int64
+
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewBroker(repo repository.Event, notifications <-chan *pq.Notification, cfg Config) (*Broker, error) {
+	lastId, err := repo.LastId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Broker{
+		repo:          repo,
+		notifications: notifications,
+		cfg:           cfg,
+		lastId:        lastId,
+		subs:          make(map[*Subscription]struct{}),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}, nil
+}
+
+// Run dispatches events until Shutdown is called and prunes the ones older
+// than the retention.
+func (b *Broker) Run() {
+	defer close(b.done)
+
+	poll := time.NewTicker(b.cfg.PollInterval)
+	defer poll.Stop()
+
+	prune := time.NewTicker(b.cfg.Retention / 10)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			b.closeAll()
+			return
+		case <-b.notifications:
+			b.drainNotifications()
+			b.dispatch()
+		case <-poll.C:
+			b.dispatch()
+		case <-prune.C:
+			if err := b.repo.Prune(time.Now().Add(-b.cfg.Retention)); err != nil {
+				logrus.Errorf("error occured while pruning events: %s", err.Error())
+			}
+		}
+	}
+}
+
+// Shutdown stops the broker and ends the streams of all subscribers.
+func (b *Broker) Shutdown(ctx context.Context) error {
+	close(b.stop)
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe registers a user for new events. With a last event id, the
+// events missed since are replayed first.
+func (b *Broker) Subscribe(userId int, lastEventId int64) (*Subscription, error) {
+	sub := &Subscription{
+		userId: userId,
+		events: make(chan domain.StreamEvent, b.cfg.Buffer),
+		broker: b,
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	if lastEventId > 0 {
+		backlog, err := b.repo.GetSince(userId, lastEventId, b.cfg.ReplayLimit+1)
+		if err != nil {
+			sub.Close()
+			return nil, err
+		}
+
+		if len(backlog) > b.cfg.ReplayLimit {
+			if backlog, err = b.reset(); err != nil {
+				sub.Close()
+				return nil, err
+			}
+		}
+		sub.Backlog = backlog
+	}
+
+	return sub, nil
+}
+
+// reset is the backlog of a client that missed too many events. Its id is
+// the last event stored, the client reloads what it shows and resumes from
+// there.
+func (b *Broker) reset() ([]domain.StreamEvent, error) {
+	lastId, err := b.repo.LastId()
+	if err != nil {
+		return nil, err
+	}
+
+	return []domain.StreamEvent{{Id: lastId, Type: domain.EventStreamReset, Payload: []byte("{}")}}, nil
+}
+
+// drainNotifications skips the notifications of a burst, one read finds
+// all of their events.
+func (b *Broker) drainNotifications() {
+	for {
+		select {
+		case <-b.notifications:
+		default:
+			return
+		}
+	}
+}
+
+func (b *Broker) dispatch() {
+	for {
+		events, err := b.repo.GetAllSince(b.lastId, b.cfg.BatchSize)
+		if err != nil {
+			logrus.Errorf("error occured while reading events: %s", err.Error())
+			return
+		}
+
+		b.mu.Lock()
+		for _, event := range events {
+			for sub := range b.subs {
+				if sub.isRecipient(event) {
+					b.send(sub, event)
+				}
+			}
+		}
+		b.mu.Unlock()
+
+		if len(events) > 0 {
+			b.lastId = events[len(events)-1].Id
+		}
+
+		if len(events) < b.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// send drops subscribers that do not keep up rather than holding up the
+// others, they resume from their last event. Called with mu held.
+func (b *Broker) send(sub *Subscription, event domain.StreamEvent) {
+	select {
+	case sub.events <- event:
+	default:
+		logrus.Warnf("dropping the stream of user %d, it fell behind", sub.userId)
+		b.remove(sub)
+	}
+}
+
+// remove must be called with mu held.
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
+
+func (b *Broker) closeAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subs {
+		b.remove(sub)
+	}
+}
+
+// Subscription is the change stream of one client.
+type Subscription struct {
+	// Backlog holds the events missed since the event the client resumed
+	// from, or a reset event when there are too many of them.
+	Backlog []domain.StreamEvent
+
+	userId int
+	events chan domain.StreamEvent
+	broker *Broker
+}
+
+// Events delivers new events, possibly some of the backlog again. It is
+// closed when the subscriber falls behind or the broker stops.
+func (s *Subscription) Events() <-chan domain.StreamEvent {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.broker.remove(s)
+}
+
+func (s *Subscription) isRecipient(event domain.StreamEvent) bool {
+	for _, id := range event.Recipients {
+		if id == s.userId {
+			return true
+		}
+	}
+
+	return false
+}
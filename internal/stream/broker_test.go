@@ -0,0 +1,218 @@
+package stream
+
+import (
+	"context"
+	"github.com/lib/pq"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventRepo keeps the stored events in memory.
+type eventRepo struct {
+	mu     sync.Mutex
+	events []domain.StreamEvent
+}
+
+func (r *eventRepo) add(eventType string, recipients ...int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, domain.StreamEvent{
+		Id:         int64(len(r.events) + 1),
+		Type:       eventType,
+		Payload:    []byte("{}"),
+		Recipients: recipients,
+	})
+}
+
+func (r *eventRepo) Store(event domain.Event) error {
+	return nil
+}
+
+func (r *eventRepo) GetSince(userId int, afterId int64, limit int) ([]domain.StreamEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]domain.StreamEvent, 0)
+	for _, event := range r.events {
+		if event.Id > afterId && contains(event.Recipients, userId) && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (r *eventRepo) GetAllSince(afterId int64, limit int) ([]domain.StreamEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]domain.StreamEvent, 0)
+	for _, event := range r.events {
+		if event.Id > afterId && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (r *eventRepo) LastId() (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return int64(len(r.events)), nil
+}
+
+func (r *eventRepo) Prune(before time.Time) error {
+	return nil
+}
+
+func contains(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+var testConfig = Config{
+	BatchSize:    2,
+	PollInterval: time.Hour,
+	Retention:    time.Hour,
+	Buffer:       4,
+	ReplayLimit:  3,
+}
+
+func newTestBroker(t *testing.T, repo *eventRepo) (*Broker, chan *pq.Notification) {
+	notifications := make(chan *pq.Notification)
+
+	b, err := NewBroker(repo, notifications, testConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go b.Run()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		b.Shutdown(ctx)
+	})
+
+	return b, notifications
+}
+
+func receive(t *testing.T, sub *Subscription) (domain.StreamEvent, bool) {
+	select {
+	case event, ok := <-sub.Events():
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return domain.StreamEvent{}, false
+	}
+}
+
+func TestBroker_Dispatch(t *testing.T) {
+	repo := &eventRepo{}
+	repo.add(domain.EventListCreated, 1)
+
+	b, notifications := newTestBroker(t, repo)
+
+	alice, err := b.Subscribe(1, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, alice.Backlog)
+
+	bob, err := b.Subscribe(2, 0)
+	assert.NoError(t, err)
+
+	// events stored before the broker started are not sent again
+	repo.add(domain.EventItemCreated, 1, 2)
+	repo.add(domain.EventItemUpdated, 1)
+	repo.add(domain.EventItemDeleted, 1, 2)
+	notifications <- &pq.Notification{Extra: "4"}
+
+	for _, id := range []int64{2, 3, 4} {
+		event, ok := receive(t, alice)
+		assert.True(t, ok)
+		assert.Equal(t, id, event.Id)
+	}
+
+	for _, id := range []int64{2, 4} {
+		event, ok := receive(t, bob)
+		assert.True(t, ok)
+		assert.Equal(t, id, event.Id)
+	}
+}
+
+func TestBroker_Resume(t *testing.T) {
+	repo := &eventRepo{}
+	for i := 0; i < 3; i++ {
+		repo.add(domain.EventItemUpdated, 1, 2)
+	}
+	repo.add(domain.EventItemUpdated, 2)
+	repo.add(domain.EventItemUpdated, 1, 2)
+
+	b, _ := newTestBroker(t, repo)
+
+	sub, err := b.Subscribe(1, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, sub.Backlog, 2) {
+		assert.Equal(t, int64(3), sub.Backlog[0].Id)
+		assert.Equal(t, int64(5), sub.Backlog[1].Id)
+	}
+
+	// more events were missed than are replayed
+	sub, err = b.Subscribe(2, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.StreamEvent{{Id: 5, Type: domain.EventStreamReset, Payload: []byte("{}")}},
+		sub.Backlog)
+}
+
+func TestBroker_DropsSlowSubscribers(t *testing.T) {
+	repo := &eventRepo{}
+	b, notifications := newTestBroker(t, repo)
+
+	slow, err := b.Subscribe(1, 0)
+	assert.NoError(t, err)
+
+	for i := 0; i < testConfig.Buffer+1; i++ {
+		repo.add(domain.EventItemUpdated, 1)
+	}
+	notifications <- &pq.Notification{}
+
+	for i := 0; i < testConfig.Buffer; i++ {
+		_, ok := receive(t, slow)
+		assert.True(t, ok)
+	}
+	_, ok := receive(t, slow)
+	assert.False(t, ok)
+
+	// closing a dropped subscription is harmless
+	slow.Close()
+}
+
+func TestBroker_Shutdown(t *testing.T) {
+	b, err := NewBroker(&eventRepo{}, make(chan *pq.Notification), testConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go b.Run()
+
+	sub, err := b.Subscribe(1, 0)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, b.Shutdown(ctx))
+
+	_, ok := receive(t, sub)
+	assert.False(t, ok)
+
+	_, err = b.Subscribe(1, 0)
+	assert.ErrorIs(t, err, ErrClosed)
+}
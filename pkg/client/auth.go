@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"net/http"
+)
+
+func (c *Client) SignUp(ctx context.Context, user domain.User) (int, error) {
+	var response idResponse
+	err := c.do(ctx, request{method: http.MethodPost, path: "/auth/sign-up", body: user, public: true}, &response)
+
+	return response.Id, err
+}
+
+// SignIn starts a session, the requests made afterwards belong to it.
+func (c *Client) SignIn(ctx context.Context, username, password string) error {
+	input := map[string]string{"username": username, "password": password}
+
+	var tokens domain.Tokens
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/auth/sign-in", body: input, public: true},
+		&tokens); err != nil {
+		return err
+	}
+	c.setTokens(tokens)
+
+	return nil
+}
+
+// Refresh replaces the tokens of the session. It is done on its own when the
+// access token expires.
+func (c *Client) Refresh(ctx context.Context) error {
+	return c.refresh(ctx, c.Tokens().AccessToken)
+}
+
+// refresh exchanges the refresh token for new tokens, unless the stale access
+// token was replaced by another refresh meanwhile.
+func (c *Client) refresh(ctx context.Context, stale string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	tokens := c.Tokens()
+	if tokens.AccessToken != stale && !c.expiring() {
+		return nil
+	}
+
+	if tokens.RefreshToken == "" {
+		return &Error{StatusCode: http.StatusUnauthorized, Code: CodeUnauthorized, Message: "no refresh token"}
+	}
+
+	input := map[string]string{"refresh_token": tokens.RefreshToken}
+
+	var fresh domain.Tokens
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/auth/refresh", body: input, public: true},
+		&fresh); err != nil {
+		return err
+	}
+	c.setTokens(fresh)
+
+	return nil
+}
+
+// Logout ends the session.
+func (c *Client) Logout(ctx context.Context) error {
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/auth/logout"}, nil); err != nil {
+		return err
+	}
+	c.setTokens(domain.Tokens{})
+
+	return nil
+}
+
+// LogoutAll ends every session of the user.
+func (c *Client) LogoutAll(ctx context.Context) error {
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/auth/logout-all"}, nil); err != nil {
+		return err
+	}
+	c.setTokens(domain.Tokens{})
+
+	return nil
+}
@@ -0,0 +1,268 @@
+// Package client is a Go client of the todo API. It signs in, keeps the access
+// token fresh and retries the requests that are safe to repeat.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetries = 2
+	defaultBackoff = 200 * time.Millisecond
+	// access tokens expiring sooner than this are refreshed before a request
+	refreshMargin = 30 * time.Second
+)
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+
+	mu        sync.Mutex
+	tokens    domain.Tokens
+	expiresAt time.Time
+
+	// refreshing one token at a time keeps a refresh token from being used
+	// twice, which would end the session
+	refreshMu sync.Mutex
+}
+
+type Option func(c *Client)
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how often a request failing with a network error or a
+// 429, 502, 503 or 504 status is retried, waiting backoff, twice as long,
+// and so on in between.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries, c.backoff = retries, backoff
+	}
+}
+
+// WithTokens resumes a session signed in before.
+func WithTokens(tokens domain.Tokens) Option {
+	return func(c *Client) {
+		c.setTokens(tokens)
+	}
+}
+
+// WithAccessToken authenticates with a personal access token, which is never
+// refreshed.
+func WithAccessToken(token string) Option {
+	return func(c *Client) {
+		c.tokens = domain.Tokens{AccessToken: token}
+	}
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		retries:    defaultRetries,
+		backoff:    defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Tokens returns the current tokens of the session, to resume it later with
+// WithTokens.
+func (c *Client) Tokens() domain.Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tokens
+}
+
+func (c *Client) setTokens(tokens domain.Tokens) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens = tokens
+	c.expiresAt = time.Time{}
+	if tokens.ExpiresIn > 0 {
+		c.expiresAt = time.Now().Add(time.Duration(tokens.ExpiresIn) * time.Second)
+	}
+}
+
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey makes a POST request sent with the context carry the key.
+// The API applies such a request once, so it is retried like any other safe
+// request. Use a new key for every call.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        interface{}
+	contentType string
+	version     *int
+	// public requests are sent without the access token
+	public bool
+}
+
+// do sends the request and decodes the response into out, when it is given.
+func (c *Client) do(ctx context.Context, r request, out interface{}) error {
+	var body []byte
+	if r.body != nil {
+		var err error
+		if body, err = json.Marshal(r.body); err != nil {
+			return err
+		}
+	}
+
+	token := ""
+	if !r.public {
+		if c.expiring() {
+			if err := c.refresh(ctx, c.Tokens().AccessToken); err != nil {
+				return err
+			}
+		}
+		token = c.Tokens().AccessToken
+	}
+
+	status, respBody, err := c.send(ctx, r, body, token)
+	if err == nil && status == http.StatusUnauthorized && token != "" && c.Tokens().RefreshToken != "" {
+		if err := c.refresh(ctx, token); err != nil {
+			return err
+		}
+		status, respBody, err = c.send(ctx, r, body, c.Tokens().AccessToken)
+	}
+	if err != nil {
+		return err
+	}
+
+	if status >= http.StatusBadRequest {
+		return newError(status, respBody)
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(respBody, out)
+}
+
+// send makes the request, retrying it while that is safe and the failure is
+// temporary.
+func (c *Client) send(ctx context.Context, r request, body []byte, token string) (int, []byte, error) {
+	key, _ := ctx.Value(idempotencyKeyCtx{}).(string)
+	retryable := r.method == http.MethodGet || r.method == http.MethodPut || r.method == http.MethodDelete ||
+		(r.method == http.MethodPost && key != "")
+
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		status, respBody, err := c.roundTrip(ctx, r, body, token, key)
+		if !retryable || attempt >= c.retries || !temporary(ctx, status, err) {
+			return status, respBody, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+func (c *Client) roundTrip(ctx context.Context, r request, body []byte, token, key string) (int, []byte, error) {
+	u := c.baseURL + r.path
+	if len(r.query) > 0 {
+		u += "?" + r.query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, u, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if body != nil {
+		contentType := r.contentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if r.version != nil {
+		req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, *r.version))
+	}
+	if key != "" && r.method == http.MethodPost {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return resp.StatusCode, respBody, nil
+}
+
+func temporary(ctx context.Context, status int, err error) bool {
+	// a canceled request stays canceled
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return true
+	}
+
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// expiring reports whether the access token has to be refreshed before it is
+// used.
+func (c *Client) expiring() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tokens.RefreshToken != "" && !c.expiresAt.IsZero() && time.Now().Add(refreshMargin).After(c.expiresAt)
+}
+
+type idResponse struct {
+	Id int `json:"id"`
+}
+
+type dataResponse struct {
+	Data interface{} `json:"data"`
+}
@@ -0,0 +1,311 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/pavel-trbv/go-todo-app/internal/handler"
+	"github.com/pavel-trbv/go-todo-app/internal/service"
+	mock_service "github.com/pavel-trbv/go-todo-app/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type mocks struct {
+	auth    *mock_service.MockAuthorization
+	lists   *mock_service.MockTodoList
+	items   *mock_service.MockTodoItem
+	members *mock_service.MockListMember
+	labels  *mock_service.MockLabel
+}
+
+func newMocks(c *gomock.Controller) mocks {
+	return mocks{
+		auth:    mock_service.NewMockAuthorization(c),
+		lists:   mock_service.NewMockTodoList(c),
+		items:   mock_service.NewMockTodoItem(c),
+		members: mock_service.NewMockListMember(c),
+		labels:  mock_service.NewMockLabel(c),
+	}
+}
+
+// newServer serves the handler of the mocked services, wrapped by wrap when it
+// is given.
+func newServer(m mocks, wrap func(next http.Handler) http.Handler) *httptest.Server {
+	services := &service.Service{Authorization: m.auth, TodoList: m.lists, TodoItem: m.items, ListMember: m.members,
+		Label: m.labels}
+
+	var h http.Handler = handler.NewHandler(services).InitRoutes()
+	if wrap != nil {
+		h = wrap(h)
+	}
+
+	return httptest.NewServer(h)
+}
+
+// failing responds with the status to the first failures requests.
+func failing(failures int32, status int, requests *int32) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(requests, 1) <= failures {
+				w.WriteHeader(status)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestClient_SignIn(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	m := newMocks(c)
+	tokens := domain.Tokens{AccessToken: "access", RefreshToken: "refresh", ExpiresIn: 900}
+	m.auth.EXPECT().GenerateTokens("user", "qwerty").Return(tokens, nil)
+	m.auth.EXPECT().ParseToken("access").Return(domain.Identity{UserId: 1, SessionId: 1}, nil)
+	m.lists.EXPECT().GetById(1, 2).Return(domain.TodoList{Id: 2, Title: "title", Version: 3}, nil)
+
+	server := newServer(m, nil)
+	defer server.Close()
+
+	client := New(server.URL)
+	ctx := context.Background()
+
+	assert.NoError(t, client.SignIn(ctx, "user", "qwerty"))
+	assert.Equal(t, tokens, client.Tokens())
+
+	list, err := client.GetList(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.TodoList{Id: 2, Title: "title", Version: 3}, list)
+}
+
+func TestClient_refresh(t *testing.T) {
+	fresh := domain.Tokens{AccessToken: "new", RefreshToken: "refresh2", ExpiresIn: 900}
+
+	testTable := []struct {
+		name           string
+		tokens         domain.Tokens
+		mockBehavior   func(m mocks)
+		expectedTokens domain.Tokens
+		expectedError  error
+	}{
+		{
+			name:   "Rejected Token",
+			tokens: domain.Tokens{AccessToken: "old", RefreshToken: "refresh", ExpiresIn: 900},
+			mockBehavior: func(m mocks) {
+				m.auth.EXPECT().ParseToken("old").Return(domain.Identity{}, service.ErrInvalidToken)
+				m.auth.EXPECT().RefreshTokens("refresh").Return(fresh, nil)
+				m.auth.EXPECT().ParseToken("new").Return(domain.Identity{UserId: 1, SessionId: 1}, nil)
+				m.lists.EXPECT().GetById(1, 2).Return(domain.TodoList{Id: 2}, nil)
+			},
+			expectedTokens: fresh,
+		},
+		{
+			name:   "Expiring Token",
+			tokens: domain.Tokens{AccessToken: "old", RefreshToken: "refresh", ExpiresIn: 10},
+			mockBehavior: func(m mocks) {
+				m.auth.EXPECT().RefreshTokens("refresh").Return(fresh, nil)
+				m.auth.EXPECT().ParseToken("new").Return(domain.Identity{UserId: 1, SessionId: 1}, nil)
+				m.lists.EXPECT().GetById(1, 2).Return(domain.TodoList{Id: 2}, nil)
+			},
+			expectedTokens: fresh,
+		},
+		{
+			name:   "Refresh Rejected",
+			tokens: domain.Tokens{AccessToken: "old", RefreshToken: "refresh", ExpiresIn: 900},
+			mockBehavior: func(m mocks) {
+				m.auth.EXPECT().ParseToken("old").Return(domain.Identity{}, service.ErrInvalidToken)
+				m.auth.EXPECT().RefreshTokens("refresh").
+					Return(domain.Tokens{}, domain.NewError(domain.ErrUnauthorized, "invalid refresh token"))
+			},
+			expectedTokens: domain.Tokens{AccessToken: "old", RefreshToken: "refresh", ExpiresIn: 900},
+			expectedError:  domain.ErrUnauthorized,
+		},
+		{
+			name:   "Access Token",
+			tokens: domain.Tokens{AccessToken: "old"},
+			mockBehavior: func(m mocks) {
+				m.auth.EXPECT().ParseToken("old").Return(domain.Identity{}, service.ErrInvalidToken)
+			},
+			expectedTokens: domain.Tokens{AccessToken: "old"},
+			expectedError:  domain.ErrUnauthorized,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			m := newMocks(c)
+			testCase.mockBehavior(m)
+
+			server := newServer(m, nil)
+			defer server.Close()
+
+			client := New(server.URL, WithTokens(testCase.tokens))
+
+			_, err := client.GetList(context.Background(), 2)
+
+			if testCase.expectedError != nil {
+				assert.True(t, errors.Is(err, testCase.expectedError))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, testCase.expectedTokens, client.Tokens())
+		})
+	}
+}
+
+func TestClient_retries(t *testing.T) {
+	testTable := []struct {
+		name             string
+		failures         int32
+		status           int
+		request          func(ctx context.Context, client *Client) error
+		mockBehavior     func(m mocks)
+		expectedRequests int32
+		expectedStatus   int
+	}{
+		{
+			name:     "Retried GET",
+			failures: 2,
+			status:   http.StatusServiceUnavailable,
+			request: func(ctx context.Context, client *Client) error {
+				_, err := client.GetList(ctx, 2)
+				return err
+			},
+			mockBehavior: func(m mocks) {
+				m.lists.EXPECT().GetById(1, 2).Return(domain.TodoList{Id: 2}, nil)
+			},
+			expectedRequests: 3,
+		},
+		{
+			name:     "Retries Exhausted",
+			failures: 3,
+			status:   http.StatusBadGateway,
+			request: func(ctx context.Context, client *Client) error {
+				return client.DeleteList(ctx, 2, nil)
+			},
+			mockBehavior:     func(m mocks) {},
+			expectedRequests: 3,
+			expectedStatus:   http.StatusBadGateway,
+		},
+		{
+			name:     "POST Not Retried",
+			failures: 1,
+			status:   http.StatusServiceUnavailable,
+			request: func(ctx context.Context, client *Client) error {
+				_, err := client.CreateList(ctx, domain.TodoList{Title: "title"})
+				return err
+			},
+			mockBehavior:     func(m mocks) {},
+			expectedRequests: 1,
+			expectedStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			name:     "Client Error Not Retried",
+			failures: 1,
+			status:   http.StatusConflict,
+			request: func(ctx context.Context, client *Client) error {
+				_, err := client.GetList(ctx, 2)
+				return err
+			},
+			mockBehavior:     func(m mocks) {},
+			expectedRequests: 1,
+			expectedStatus:   http.StatusConflict,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			m := newMocks(c)
+			m.auth.EXPECT().ParseToken("token").Return(domain.Identity{UserId: 1, SessionId: 1}, nil).AnyTimes()
+			testCase.mockBehavior(m)
+
+			var requests int32
+			server := newServer(m, failing(testCase.failures, testCase.status, &requests))
+			defer server.Close()
+
+			client := New(server.URL, WithAccessToken("token"), WithRetries(2, time.Millisecond))
+
+			err := testCase.request(context.Background(), client)
+
+			assert.Equal(t, testCase.expectedRequests, requests)
+			if testCase.expectedStatus != 0 {
+				var apiErr *Error
+				assert.True(t, errors.As(err, &apiErr))
+				assert.Equal(t, testCase.expectedStatus, apiErr.StatusCode)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_errors(t *testing.T) {
+	testTable := []struct {
+		name          string
+		serviceError  error
+		expectedError *Error
+		expectedKind  error
+	}{
+		{
+			name:          "Not Found",
+			serviceError:  domain.NewError(domain.ErrNotFound, "list not found"),
+			expectedError: &Error{StatusCode: 404, Code: CodeNotFound, Message: "list not found"},
+			expectedKind:  domain.ErrNotFound,
+		},
+		{
+			name:          "Forbidden",
+			serviceError:  domain.NewError(domain.ErrForbidden, "list is read only"),
+			expectedError: &Error{StatusCode: 403, Code: CodeForbidden, Message: "list is read only"},
+			expectedKind:  domain.ErrForbidden,
+		},
+		{
+			name:          "Version Mismatch",
+			serviceError:  domain.ErrVersionMismatch,
+			expectedError: &Error{StatusCode: 412, Code: CodePreconditionFailed, Message: "conflict: version mismatch"},
+			expectedKind:  domain.ErrConflict,
+		},
+		{
+			name:          "Internal Error",
+			serviceError:  errors.New("something went wrong"),
+			expectedError: &Error{StatusCode: 500, Code: CodeInternalError, Message: "internal server error"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			m := newMocks(c)
+			m.auth.EXPECT().ParseToken("token").Return(domain.Identity{UserId: 1, SessionId: 1}, nil)
+			m.lists.EXPECT().Delete(1, 2, nil).Return(testCase.serviceError)
+
+			server := newServer(m, nil)
+			defer server.Close()
+
+			client := New(server.URL, WithAccessToken("token"), WithRetries(0, 0))
+
+			err := client.DeleteList(context.Background(), 2, nil)
+
+			var apiErr *Error
+			assert.True(t, errors.As(err, &apiErr))
+			assert.Equal(t, testCase.expectedError, apiErr)
+			if testCase.expectedKind != nil {
+				assert.True(t, errors.Is(err, testCase.expectedKind))
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"net/http"
+)
+
+// Error codes of the API.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodePreconditionFailed   = "precondition_failed"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeValidationError      = "validation_error"
+	CodeInternalError        = "internal_error"
+)
+
+// kinds maps error codes to the domain errors they stand for, so callers can
+// use errors.Is(err, ErrNotFound) and alike.
+var kinds = map[string]error{
+	CodeUnauthorized:       domain.ErrUnauthorized,
+	CodeForbidden:          domain.ErrForbidden,
+	CodeNotFound:           domain.ErrNotFound,
+	CodeConflict:           domain.ErrConflict,
+	CodePreconditionFailed: domain.ErrVersionMismatch,
+	CodeValidationError:    domain.ErrValidation,
+}
+
+// Error is an error response of the API.
+type Error struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func newError(statusCode int, body []byte) *Error {
+	e := &Error{StatusCode: statusCode}
+	if err := json.Unmarshal(body, e); err != nil || e.Code == "" {
+		e.Code, e.Message = CodeInternalError, http.StatusText(statusCode)
+	}
+
+	return e
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return kinds[e.Code]
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (c *Client) CreateItem(ctx context.Context, listId int, item domain.TodoItem) (int, error) {
+	var response idResponse
+	err := c.do(ctx, request{method: http.MethodPost, path: listPath(listId) + "/items/", body: item}, &response)
+
+	return response.Id, err
+}
+
+func (c *Client) GetItems(ctx context.Context, listId int, filter domain.ItemFilter) (domain.ItemPage, error) {
+	query := pageQuery(filter.Page)
+	if filter.Query != "" {
+		query.Set("q", filter.Query)
+	}
+	if filter.Done != nil {
+		query.Set("done", strconv.FormatBool(*filter.Done))
+	}
+	if len(filter.Priorities) > 0 {
+		query.Set("priority", strings.Join(filter.Priorities, ","))
+	}
+	if filter.LabelId != 0 {
+		query.Set("label", strconv.Itoa(filter.LabelId))
+	}
+	if filter.DueBefore != nil {
+		query.Set("due_before", filter.DueBefore.Format(time.RFC3339))
+	}
+	if filter.DueAfter != nil {
+		query.Set("due_after", filter.DueAfter.Format(time.RFC3339))
+	}
+
+	var page domain.ItemPage
+	err := c.do(ctx, request{method: http.MethodGet, path: listPath(listId) + "/items/", query: query}, &page)
+
+	return page, err
+}
+
+func (c *Client) GetItem(ctx context.Context, itemId int) (domain.TodoItem, error) {
+	var item domain.TodoItem
+	err := c.do(ctx, request{method: http.MethodGet, path: itemPath(itemId)}, &item)
+
+	return item, err
+}
+
+func (c *Client) UpdateItem(ctx context.Context, itemId int, input domain.UpdateItemInput) error {
+	return c.do(ctx, request{method: http.MethodPut, path: itemPath(itemId), body: input}, nil)
+}
+
+// PatchItem sends the patch as a JSON merge patch, a nil value clears the
+// field. It applies only to the given version, when one is set.
+func (c *Client) PatchItem(ctx context.Context, itemId int, patch domain.Patch, version *int) error {
+	return c.do(ctx, request{method: http.MethodPatch, path: itemPath(itemId), body: patch,
+		contentType: domain.MergePatchType, version: version}, nil)
+}
+
+func (c *Client) DeleteItem(ctx context.Context, itemId int, version *int) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: itemPath(itemId), version: version}, nil)
+}
+
+func (c *Client) CreateChildItem(ctx context.Context, parentId int, item domain.TodoItem) (int, error) {
+	var response idResponse
+	err := c.do(ctx, request{method: http.MethodPost, path: itemPath(parentId) + "/children", body: item}, &response)
+
+	return response.Id, err
+}
+
+func (c *Client) GetChildItems(ctx context.Context, parentId int) ([]domain.TodoItem, error) {
+	var items []domain.TodoItem
+	err := c.do(ctx, request{method: http.MethodGet, path: itemPath(parentId) + "/children"},
+		&dataResponse{Data: &items})
+
+	return items, err
+}
+
+func (c *Client) ReorderChildItems(ctx context.Context, parentId int, ids []int) error {
+	return c.do(ctx, request{method: http.MethodPut, path: itemPath(parentId) + "/children/order",
+		body: domain.ReorderItemsInput{Ids: ids}}, nil)
+}
+
+func (c *Client) MoveItem(ctx context.Context, itemId int, input domain.MoveInput) error {
+	return c.do(ctx, request{method: http.MethodPost, path: itemPath(itemId) + "/move", body: input}, nil)
+}
+
+func (c *Client) CopyItem(ctx context.Context, itemId int, input domain.CopyItemInput) (int, error) {
+	var response idResponse
+	err := c.do(ctx, request{method: http.MethodPost, path: itemPath(itemId) + "/copy", body: input}, &response)
+
+	return response.Id, err
+}
+
+func (c *Client) BulkCreateItems(ctx context.Context, listId int,
+	input domain.BulkCreateItemsInput) (domain.BulkResponse, error) {
+	return c.bulk(ctx, listId, "/bulk/create", input)
+}
+
+func (c *Client) BulkUpdateItems(ctx context.Context, listId int,
+	input domain.BulkUpdateItemsInput) (domain.BulkResponse, error) {
+	return c.bulk(ctx, listId, "/bulk/update", input)
+}
+
+func (c *Client) BulkDeleteItems(ctx context.Context, listId int,
+	input domain.BulkDeleteItemsInput) (domain.BulkResponse, error) {
+	return c.bulk(ctx, listId, "/bulk/delete", input)
+}
+
+func (c *Client) ClearCompletedItems(ctx context.Context, listId int) (domain.BulkResponse, error) {
+	return c.bulk(ctx, listId, "/clear-completed", nil)
+}
+
+func (c *Client) bulk(ctx context.Context, listId int, action string, input interface{}) (domain.BulkResponse, error) {
+	var response domain.BulkResponse
+	err := c.do(ctx, request{method: http.MethodPost, path: listPath(listId) + "/items" + action, body: input},
+		&response)
+
+	return response, err
+}
+
+func (c *Client) AttachLabel(ctx context.Context, itemId, labelId int) error {
+	return c.do(ctx, request{method: http.MethodPost, path: labelPath(itemId, labelId)}, nil)
+}
+
+func (c *Client) DetachLabel(ctx context.Context, itemId, labelId int) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: labelPath(itemId, labelId)}, nil)
+}
+
+func itemPath(itemId int) string {
+	return fmt.Sprintf("/api/items/%d", itemId)
+}
+
+func labelPath(itemId, labelId int) string {
+	return fmt.Sprintf("/api/items/%d/labels/%d", itemId, labelId)
+}
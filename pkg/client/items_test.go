@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestClient_GetItems(t *testing.T) {
+	done := true
+	dueBefore := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+	testTable := []struct {
+		name           string
+		filter         domain.ItemFilter
+		expectedFilter domain.ItemFilter
+	}{
+		{
+			name: "OK",
+		},
+		{
+			name: "Filtered",
+			filter: domain.ItemFilter{
+				Page:       domain.Page{Sort: domain.SortByDueAt, Order: domain.SortDesc, Limit: 10, Cursor: "abc"},
+				Done:       &done,
+				Priorities: []string{domain.PriorityHigh, domain.PriorityUrgent},
+				DueBefore:  &dueBefore,
+				LabelId:    4,
+				Query:      "milk",
+			},
+			expectedFilter: domain.ItemFilter{
+				Page:       domain.Page{Sort: domain.SortByDueAt, Order: domain.SortDesc, Limit: 10, Cursor: "abc"},
+				Done:       &done,
+				Priorities: []string{domain.PriorityHigh, domain.PriorityUrgent},
+				DueBefore:  &dueBefore,
+				LabelId:    4,
+				Query:      "milk",
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			page := domain.ItemPage{Data: []domain.TodoItem{{Id: 1, Title: "milk"}}, NextCursor: "def"}
+
+			m := newMocks(c)
+			m.auth.EXPECT().ParseToken("token").Return(domain.Identity{UserId: 1, SessionId: 1}, nil)
+			m.items.EXPECT().GetAll(1, 2, testCase.expectedFilter).Return(page, nil)
+
+			server := newServer(m, nil)
+			defer server.Close()
+
+			client := New(server.URL, WithAccessToken("token"))
+
+			got, err := client.GetItems(context.Background(), 2, testCase.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, page.NextCursor, got.NextCursor)
+			assert.Equal(t, 1, len(got.Data))
+			assert.Equal(t, "milk", got.Data[0].Title)
+		})
+	}
+}
+
+func TestClient_PatchItem(t *testing.T) {
+	version := 3
+
+	testTable := []struct {
+		name          string
+		patch         domain.Patch
+		version       *int
+		expectedInput domain.PatchInput
+	}{
+		{
+			name:    "OK",
+			patch:   domain.Patch{"done": true, "description": nil},
+			version: &version,
+			expectedInput: domain.PatchInput{
+				ContentType: domain.MergePatchType,
+				Body:        []byte(`{"description":null,"done":true}`),
+				Version:     &version,
+			},
+		},
+		{
+			name:  "Without Version",
+			patch: domain.Patch{"title": "bread"},
+			expectedInput: domain.PatchInput{
+				ContentType: domain.MergePatchType,
+				Body:        []byte(`{"title":"bread"}`),
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			m := newMocks(c)
+			m.auth.EXPECT().ParseToken("token").Return(domain.Identity{UserId: 1, SessionId: 1}, nil)
+			m.items.EXPECT().Patch(1, 5, testCase.expectedInput).Return(nil)
+
+			server := newServer(m, nil)
+			defer server.Close()
+
+			client := New(server.URL, WithAccessToken("token"))
+
+			assert.NoError(t, client.PatchItem(context.Background(), 5, testCase.patch, testCase.version))
+		})
+	}
+}
+
+func TestClient_items(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	children := []domain.TodoItem{{Id: 6, Title: "child"}}
+	bulk := domain.BulkResponse{Committed: true, Results: []domain.BulkResult{
+		{Index: 0, Id: 6, Status: domain.BulkApplied},
+	}}
+
+	m := newMocks(c)
+	m.auth.EXPECT().ParseToken("token").Return(domain.Identity{UserId: 1, SessionId: 1}, nil).AnyTimes()
+	m.items.EXPECT().Create(1, 2, domain.TodoItem{Title: "milk"}).Return(5, nil)
+	m.items.EXPECT().GetChildren(1, 5).Return(children, nil)
+	m.items.EXPECT().ReorderChildren(1, 5, []int{7, 6}).Return(nil)
+	m.items.EXPECT().BulkDelete(1, 2, domain.BulkDeleteItemsInput{Ids: []int{6}}).Return(bulk, nil)
+	m.labels.EXPECT().Attach(1, 5, 3).Return(nil)
+
+	server := newServer(m, nil)
+	defer server.Close()
+
+	client := New(server.URL, WithAccessToken("token"))
+	ctx := context.Background()
+
+	id, err := client.CreateItem(ctx, 2, domain.TodoItem{Title: "milk"})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, id)
+
+	gotChildren, err := client.GetChildItems(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, children[0].Title, gotChildren[0].Title)
+
+	assert.NoError(t, client.ReorderChildItems(ctx, 5, []int{7, 6}))
+
+	gotBulk, err := client.BulkDeleteItems(ctx, 2, domain.BulkDeleteItemsInput{Ids: []int{6}})
+	assert.NoError(t, err)
+	assert.Equal(t, bulk, gotBulk)
+
+	assert.NoError(t, client.AttachLabel(ctx, 5, 3))
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (c *Client) CreateList(ctx context.Context, list domain.TodoList) (int, error) {
+	var response idResponse
+	err := c.do(ctx, request{method: http.MethodPost, path: "/api/lists/", body: list}, &response)
+
+	return response.Id, err
+}
+
+func (c *Client) GetLists(ctx context.Context, filter domain.ListFilter) (domain.ListPage, error) {
+	query := pageQuery(filter.Page)
+	if filter.Query != "" {
+		query.Set("q", filter.Query)
+	}
+
+	var page domain.ListPage
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/lists/", query: query}, &page)
+
+	return page, err
+}
+
+func (c *Client) GetList(ctx context.Context, listId int) (domain.TodoList, error) {
+	var list domain.TodoList
+	err := c.do(ctx, request{method: http.MethodGet, path: listPath(listId)}, &list)
+
+	return list, err
+}
+
+func (c *Client) UpdateList(ctx context.Context, listId int, input domain.UpdateListInput) error {
+	return c.do(ctx, request{method: http.MethodPut, path: listPath(listId), body: input}, nil)
+}
+
+// PatchList sends the patch as a JSON merge patch, a nil value clears the
+// field. It applies only to the given version, when one is set.
+func (c *Client) PatchList(ctx context.Context, listId int, patch domain.Patch, version *int) error {
+	return c.do(ctx, request{method: http.MethodPatch, path: listPath(listId), body: patch,
+		contentType: domain.MergePatchType, version: version}, nil)
+}
+
+func (c *Client) DeleteList(ctx context.Context, listId int, version *int) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: listPath(listId), version: version}, nil)
+}
+
+func (c *Client) MoveList(ctx context.Context, listId int, input domain.MoveInput) error {
+	return c.do(ctx, request{method: http.MethodPost, path: listPath(listId) + "/move", body: input}, nil)
+}
+
+func (c *Client) DuplicateList(ctx context.Context, listId int, input domain.DuplicateListInput) (int, error) {
+	var response idResponse
+	err := c.do(ctx, request{method: http.MethodPost, path: listPath(listId) + "/duplicate", body: input}, &response)
+
+	return response.Id, err
+}
+
+func (c *Client) LeaveList(ctx context.Context, listId int) error {
+	return c.do(ctx, request{method: http.MethodPost, path: listPath(listId) + "/leave"}, nil)
+}
+
+func (c *Client) GetListMembers(ctx context.Context, listId int) ([]domain.ListMember, error) {
+	var members []domain.ListMember
+	err := c.do(ctx, request{method: http.MethodGet, path: listPath(listId) + "/members/"},
+		&dataResponse{Data: &members})
+
+	return members, err
+}
+
+func (c *Client) InviteListMember(ctx context.Context, listId int, input domain.InviteMemberInput) (int, error) {
+	var response idResponse
+	err := c.do(ctx, request{method: http.MethodPost, path: listPath(listId) + "/members/", body: input}, &response)
+
+	return response.Id, err
+}
+
+func (c *Client) UpdateListMember(ctx context.Context, listId, userId int, input domain.UpdateMemberInput) error {
+	return c.do(ctx, request{method: http.MethodPut, path: memberPath(listId, userId), body: input}, nil)
+}
+
+func (c *Client) RemoveListMember(ctx context.Context, listId, userId int) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: memberPath(listId, userId)}, nil)
+}
+
+func (c *Client) GetInvitations(ctx context.Context) ([]domain.Invitation, error) {
+	var invitations []domain.Invitation
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/invitations/"}, &dataResponse{Data: &invitations})
+
+	return invitations, err
+}
+
+func (c *Client) AcceptInvitation(ctx context.Context, invitationId int) error {
+	return c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/api/invitations/%d/accept", invitationId)},
+		nil)
+}
+
+func (c *Client) DeclineInvitation(ctx context.Context, invitationId int) error {
+	return c.do(ctx, request{method: http.MethodPost, path: fmt.Sprintf("/api/invitations/%d/decline", invitationId)},
+		nil)
+}
+
+func listPath(listId int) string {
+	return fmt.Sprintf("/api/lists/%d", listId)
+}
+
+func memberPath(listId, userId int) string {
+	return fmt.Sprintf("/api/lists/%d/members/%d", listId, userId)
+}
+
+func pageQuery(page domain.Page) url.Values {
+	query := make(url.Values)
+	if page.Sort != "" {
+		query.Set("sort", page.Sort)
+	}
+	if page.Order != "" {
+		query.Set("order", page.Order)
+	}
+	if page.Limit != 0 {
+		query.Set("limit", strconv.Itoa(page.Limit))
+	}
+	if page.Cursor != "" {
+		query.Set("cursor", page.Cursor)
+	}
+
+	return query
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/pavel-trbv/go-todo-app/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClient_GetLists(t *testing.T) {
+	testTable := []struct {
+		name           string
+		filter         domain.ListFilter
+		expectedFilter domain.ListFilter
+	}{
+		{
+			name: "OK",
+		},
+		{
+			name: "Filtered",
+			filter: domain.ListFilter{
+				Page:  domain.Page{Sort: domain.SortByTitle, Order: domain.SortAsc, Limit: 20, Cursor: "abc"},
+				Query: "home",
+			},
+			expectedFilter: domain.ListFilter{
+				Page:  domain.Page{Sort: domain.SortByTitle, Order: domain.SortAsc, Limit: 20, Cursor: "abc"},
+				Query: "home",
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			page := domain.ListPage{Data: []domain.TodoList{{Id: 1, Title: "home", Role: domain.RoleOwner}}}
+
+			m := newMocks(c)
+			m.auth.EXPECT().ParseToken("token").Return(domain.Identity{UserId: 1, SessionId: 1}, nil)
+			m.lists.EXPECT().GetAll(1, testCase.expectedFilter).Return(page, nil)
+
+			server := newServer(m, nil)
+			defer server.Close()
+
+			client := New(server.URL, WithAccessToken("token"))
+
+			got, err := client.GetLists(context.Background(), testCase.filter)
+			assert.NoError(t, err)
+			assert.Equal(t, page, got)
+		})
+	}
+}
+
+func TestClient_lists(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	title := "copy"
+	version := 2
+	members := []domain.ListMember{{UserId: 1, Username: "user", Role: domain.RoleOwner}}
+
+	m := newMocks(c)
+	m.auth.EXPECT().ParseToken("token").Return(domain.Identity{UserId: 1, SessionId: 1}, nil).AnyTimes()
+	m.lists.EXPECT().Create(1, domain.TodoList{Title: "home"}).Return(2, nil)
+	m.lists.EXPECT().Patch(1, 2, domain.PatchInput{ContentType: domain.MergePatchType,
+		Body: []byte(`{"description":null}`), Version: &version}).Return(nil)
+	m.lists.EXPECT().Duplicate(1, 2, domain.DuplicateListInput{Title: &title}).Return(3, nil)
+	m.members.EXPECT().GetAll(1, 2).Return(members, nil)
+	m.members.EXPECT().Invite(1, 2, domain.InviteMemberInput{Username: "friend", Role: domain.RoleEditor}).
+		Return(4, nil)
+	m.lists.EXPECT().Delete(1, 3, &version).Return(nil)
+
+	server := newServer(m, nil)
+	defer server.Close()
+
+	client := New(server.URL, WithAccessToken("token"))
+	ctx := context.Background()
+
+	id, err := client.CreateList(ctx, domain.TodoList{Title: "home"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, id)
+
+	assert.NoError(t, client.PatchList(ctx, 2, domain.Patch{"description": nil}, &version))
+
+	id, err = client.DuplicateList(ctx, 2, domain.DuplicateListInput{Title: &title})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, id)
+
+	gotMembers, err := client.GetListMembers(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, members, gotMembers)
+
+	id, err = client.InviteListMember(ctx, 2, domain.InviteMemberInput{Username: "friend", Role: domain.RoleEditor})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, id)
+
+	assert.NoError(t, client.DeleteList(ctx, 3, &version))
+}
@@ -0,0 +1,43 @@
+package client
+
+import "github.com/pavel-trbv/go-todo-app/internal/domain"
+
+// The types of the API, aliased as other modules can not import the domain
+// package.
+type (
+	User                 = domain.User
+	Tokens               = domain.Tokens
+	TodoList             = domain.TodoList
+	TodoItem             = domain.TodoItem
+	Label                = domain.Label
+	ListMember           = domain.ListMember
+	Invitation           = domain.Invitation
+	Page                 = domain.Page
+	ListFilter           = domain.ListFilter
+	ItemFilter           = domain.ItemFilter
+	ListPage             = domain.ListPage
+	ItemPage             = domain.ItemPage
+	Patch                = domain.Patch
+	UpdateListInput      = domain.UpdateListInput
+	UpdateItemInput      = domain.UpdateItemInput
+	MoveInput            = domain.MoveInput
+	CopyItemInput        = domain.CopyItemInput
+	DuplicateListInput   = domain.DuplicateListInput
+	InviteMemberInput    = domain.InviteMemberInput
+	UpdateMemberInput    = domain.UpdateMemberInput
+	BulkCreateItemsInput = domain.BulkCreateItemsInput
+	BulkUpdateItemsInput = domain.BulkUpdateItemsInput
+	BulkDeleteItemsInput = domain.BulkDeleteItemsInput
+	BulkResult           = domain.BulkResult
+	BulkResponse         = domain.BulkResponse
+)
+
+// The kinds of errors, to be matched with errors.Is.
+var (
+	ErrNotFound        = domain.ErrNotFound
+	ErrForbidden       = domain.ErrForbidden
+	ErrConflict        = domain.ErrConflict
+	ErrVersionMismatch = domain.ErrVersionMismatch
+	ErrValidation      = domain.ErrValidation
+	ErrUnauthorized    = domain.ErrUnauthorized
+)
@@ -0,0 +1,17 @@
+CREATE TABLE sessions
+(
+    id         serial                                      not null unique,
+    user_id    int references users (id) on delete cascade not null,
+    created_at timestamp                                   not null default now(),
+    revoked_at timestamp
+);
+
+CREATE TABLE refresh_tokens
+(
+    id         serial                                         not null unique,
+    session_id int references sessions (id) on delete cascade not null,
+    token_hash varchar(64)                                    not null unique,
+    expires_at timestamp                                      not null,
+    used_at    timestamp,
+    created_at timestamp                                      not null default now()
+);
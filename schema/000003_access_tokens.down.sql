@@ -0,0 +1 @@
+DROP TABLE access_tokens;
@@ -0,0 +1,12 @@
+CREATE TABLE access_tokens
+(
+    id           serial                                      not null unique,
+    user_id      int references users (id) on delete cascade not null,
+    name         varchar(255)                                not null,
+    token_hash   varchar(64)                                 not null unique,
+    scopes       varchar(255)                                not null,
+    expires_at   timestamp,
+    last_used_at timestamp,
+    revoked_at   timestamp,
+    created_at   timestamp                                   not null default now()
+);
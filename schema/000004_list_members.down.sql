@@ -0,0 +1,5 @@
+DROP TABLE list_invitations;
+
+ALTER TABLE users_lists
+    DROP CONSTRAINT users_lists_user_id_list_id_key,
+    DROP COLUMN role;
@@ -0,0 +1,17 @@
+ALTER TABLE users_lists
+    ADD COLUMN role varchar(16) not null default 'owner'
+        CHECK (role IN ('owner', 'editor', 'viewer')),
+    ADD UNIQUE (user_id, list_id);
+
+CREATE TABLE list_invitations
+(
+    id         serial                                           not null unique,
+    list_id    int references todo_lists (id) on delete cascade not null,
+    inviter_id int references users (id) on delete cascade      not null,
+    invitee_id int references users (id) on delete cascade      not null,
+    role       varchar(16)                                      not null
+        CHECK (role IN ('owner', 'editor', 'viewer')),
+    status     varchar(16)                                      not null default 'pending'
+        CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at timestamp                                        not null default now()
+);
@@ -0,0 +1,6 @@
+ALTER TABLE todo_items
+    DROP COLUMN due_at,
+    DROP COLUMN priority,
+    DROP COLUMN created_at,
+    DROP COLUMN updated_at,
+    DROP COLUMN completed_at;
@@ -0,0 +1,9 @@
+ALTER TABLE todo_items
+    ADD COLUMN due_at       timestamp,
+    ADD COLUMN priority     varchar(16) not null default 'none'
+        CHECK (priority IN ('none', 'low', 'medium', 'high', 'urgent')),
+    ADD COLUMN created_at   timestamp   not null default now(),
+    ADD COLUMN updated_at   timestamp   not null default now(),
+    ADD COLUMN completed_at timestamp;
+
+UPDATE todo_items SET completed_at = now() WHERE done;
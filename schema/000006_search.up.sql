@@ -0,0 +1,15 @@
+ALTER TABLE todo_lists
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+        ) STORED;
+
+CREATE INDEX todo_lists_search_vector_idx ON todo_lists USING GIN (search_vector);
+
+ALTER TABLE todo_items
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+        ) STORED;
+
+CREATE INDEX todo_items_search_vector_idx ON todo_items USING GIN (search_vector);
@@ -0,0 +1,20 @@
+CREATE TABLE labels
+(
+    id         serial                                      not null unique,
+    user_id    int references users (id) on delete cascade not null,
+    name       varchar(64)                                 not null,
+    color      varchar(7)                                  not null default '#9e9e9e'
+        CHECK (color ~ '^#[0-9a-fA-F]{6}$'),
+    created_at timestamp                                   not null default now(),
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE items_labels
+(
+    id       serial                                           not null unique,
+    item_id  int references todo_items (id) on delete cascade not null,
+    label_id int references labels (id) on delete cascade     not null,
+    UNIQUE (item_id, label_id)
+);
+
+CREATE INDEX items_labels_label_id_idx ON items_labels (label_id);
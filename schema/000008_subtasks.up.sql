@@ -0,0 +1,6 @@
+ALTER TABLE todo_items
+    ADD COLUMN parent_id     int references todo_items (id) on delete cascade,
+    ADD COLUMN position      int     not null default 0,
+    ADD COLUMN auto_complete boolean not null default false;
+
+CREATE INDEX todo_items_parent_id_idx ON todo_items (parent_id);
@@ -0,0 +1,8 @@
+ALTER TABLE todo_items
+    ALTER COLUMN position TYPE int USING 0,
+    ALTER COLUMN position SET DEFAULT 0;
+
+DROP INDEX users_lists_user_id_position_idx;
+
+ALTER TABLE users_lists
+    DROP COLUMN position;
@@ -0,0 +1,27 @@
+-- positions are lexicographic ranks (see internal/rank) and are compared
+-- byte by byte, hence the "C" collation
+
+ALTER TABLE users_lists
+    ADD COLUMN position varchar(255) COLLATE "C";
+
+UPDATE users_lists ul
+SET position = lpad(o.n::text, 10, '0') || 'i'
+FROM (SELECT id, row_number() OVER (PARTITION BY user_id ORDER BY list_id) AS n FROM users_lists) o
+WHERE ul.id = o.id;
+
+ALTER TABLE users_lists
+    ALTER COLUMN position SET NOT NULL;
+
+CREATE INDEX users_lists_user_id_position_idx ON users_lists (user_id, position);
+
+ALTER TABLE todo_items
+    ALTER COLUMN position DROP DEFAULT,
+    ALTER COLUMN position TYPE varchar(255) COLLATE "C" USING position::text;
+
+UPDATE todo_items ti
+SET position = lpad(o.n::text, 10, '0') || 'i'
+FROM (SELECT ti.id,
+             row_number() OVER (PARTITION BY li.list_id, ti.parent_id ORDER BY ti.position::int, ti.id) AS n
+      FROM todo_items ti
+               INNER JOIN lists_items li ON li.item_id = ti.id) o
+WHERE ti.id = o.id;
@@ -0,0 +1,5 @@
+-- recurrence holds an RRULE subset (see internal/recurrence), an empty
+-- string for items that do not repeat
+ALTER TABLE todo_items
+    ADD COLUMN recurrence varchar(255) not null default '',
+    ADD COLUMN timezone   varchar(64)  not null default 'UTC';
@@ -0,0 +1,22 @@
+-- a reminder fires either at remind_at or offset_minutes before the due date
+-- of its item, sent_at older than the firing time lets a reminder fire again
+-- once the due date moved
+CREATE TABLE reminders
+(
+    id             serial                                            not null unique,
+    item_id        int references todo_items (id) on delete cascade not null,
+    user_id        int references users (id) on delete cascade      not null,
+    remind_at      timestamp,
+    offset_minutes int CHECK (offset_minutes >= 0),
+    channel        varchar(16)                                       not null
+        CHECK (channel IN ('email', 'webhook', 'log')),
+    target         varchar(255)                                      not null default '',
+    sent_at        timestamp,
+    attempts       int                                               not null default 0,
+    last_error     text,
+    created_at     timestamp                                         not null default now(),
+    CHECK ((remind_at IS NULL) <> (offset_minutes IS NULL))
+);
+
+CREATE INDEX reminders_item_id_idx ON reminders (item_id);
+CREATE INDEX reminders_user_id_idx ON reminders (user_id);
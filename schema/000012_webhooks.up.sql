@@ -0,0 +1,33 @@
+CREATE TABLE webhooks
+(
+    id         serial                                      not null unique,
+    user_id    int references users (id) on delete cascade not null,
+    url        varchar(255)                                not null,
+    secret     varchar(255)                                not null,
+    events     text[]                                      not null,
+    active     boolean                                     not null default true,
+    created_at timestamp                                   not null default now()
+);
+
+CREATE INDEX webhooks_user_id_idx ON webhooks (user_id);
+
+-- deliveries are written along with the event and survive restarts, the
+-- dispatcher picks up pending ones once next_attempt_at has passed
+CREATE TABLE webhook_deliveries
+(
+    id               serial                                         not null unique,
+    webhook_id       int references webhooks (id) on delete cascade not null,
+    event            varchar(32)                                    not null,
+    payload          jsonb                                          not null,
+    status           varchar(16)                                    not null default 'pending'
+        CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts         int                                            not null default 0,
+    next_attempt_at  timestamp                                               default now(),
+    last_status_code int,
+    last_error       text,
+    created_at       timestamp                                      not null default now(),
+    delivered_at     timestamp
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, id);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
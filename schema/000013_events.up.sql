@@ -0,0 +1,13 @@
+-- events feed the change stream, every instance is told about new ones with
+-- NOTIFY and clients resume from the id of the last event they received
+CREATE TABLE events
+(
+    id         bigserial   not null unique,
+    type       varchar(32) not null,
+    list_id    int         not null,
+    recipients int[]       not null,
+    payload    jsonb       not null,
+    created_at timestamp   not null default now()
+);
+
+CREATE INDEX events_created_at_idx ON events (created_at);
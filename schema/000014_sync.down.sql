@@ -0,0 +1,25 @@
+DROP TRIGGER lists_items_log_change ON lists_items;
+DROP TRIGGER todo_items_log_delete ON todo_items;
+DROP TRIGGER todo_items_log_change ON todo_items;
+DROP TRIGGER users_lists_log_change ON users_lists;
+DROP TRIGGER todo_lists_log_delete ON todo_lists;
+DROP TRIGGER todo_lists_log_change ON todo_lists;
+
+DROP FUNCTION log_item_list_change();
+DROP FUNCTION log_item_change();
+DROP FUNCTION log_membership_change();
+DROP FUNCTION log_list_change();
+DROP FUNCTION log_changes(int, varchar, int);
+
+DROP TABLE changes;
+
+DROP TRIGGER todo_items_bump_version ON todo_items;
+DROP TRIGGER todo_lists_bump_version ON todo_lists;
+
+DROP FUNCTION bump_version();
+
+ALTER TABLE todo_items
+    DROP COLUMN version;
+
+ALTER TABLE todo_lists
+    DROP COLUMN version;
@@ -0,0 +1,156 @@
+-- versions grow with every update of a row, clients send the version their
+-- changes are based on
+ALTER TABLE todo_lists
+    ADD COLUMN version int not null default 1;
+
+ALTER TABLE todo_items
+    ADD COLUMN version int not null default 1;
+
+CREATE FUNCTION bump_version() RETURNS trigger AS
+$$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todo_lists_bump_version
+    BEFORE UPDATE
+    ON todo_lists
+    FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER todo_items_bump_version
+    BEFORE UPDATE
+    ON todo_items
+    FOR EACH ROW
+EXECUTE FUNCTION bump_version();
+
+-- the change log tells every user which lists and items changed for them,
+-- tx_id orders the changes by the transaction that made them (see
+-- repository.SyncPostgres). Users may be gone by the time their memberships
+-- are deleted, hence no foreign key.
+CREATE TABLE changes
+(
+    id         bigserial  not null unique,
+    user_id    int        not null,
+    entity     varchar(8) not null
+        CHECK (entity IN ('list', 'item')),
+    entity_id  int        not null,
+    tx_id      bigint     not null default txid_current(),
+    created_at timestamp  not null default now()
+);
+
+CREATE INDEX changes_user_id_tx_id_idx ON changes (user_id, tx_id);
+
+-- log_changes records a change for every member of a list
+CREATE FUNCTION log_changes(p_list_id int, p_entity varchar, p_entity_id int) RETURNS void AS
+$$
+INSERT INTO changes (user_id, entity, entity_id)
+SELECT user_id, p_entity, p_entity_id
+FROM users_lists
+WHERE list_id = p_list_id;
+$$ LANGUAGE sql;
+
+CREATE FUNCTION log_list_change() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        -- the members and the items are about to be deleted along
+        PERFORM log_changes(OLD.id, 'list', OLD.id);
+        PERFORM log_changes(OLD.id, 'item', li.item_id) FROM lists_items li WHERE li.list_id = OLD.id;
+        RETURN OLD;
+    END IF;
+
+    PERFORM log_changes(NEW.id, 'list', NEW.id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todo_lists_log_change
+    AFTER UPDATE
+    ON todo_lists
+    FOR EACH ROW
+EXECUTE FUNCTION log_list_change();
+
+CREATE TRIGGER todo_lists_log_delete
+    BEFORE DELETE
+    ON todo_lists
+    FOR EACH ROW
+EXECUTE FUNCTION log_list_change();
+
+-- joining or leaving a list changes which items the user sees
+CREATE FUNCTION log_membership_change() RETURNS trigger AS
+$$
+DECLARE
+    m users_lists;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        m := OLD;
+    ELSE
+        m := NEW;
+    END IF;
+
+    INSERT INTO changes (user_id, entity, entity_id) VALUES (m.user_id, 'list', m.list_id);
+    IF TG_OP <> 'UPDATE' THEN
+        INSERT INTO changes (user_id, entity, entity_id)
+        SELECT m.user_id, 'item', li.item_id
+        FROM lists_items li
+        WHERE li.list_id = m.list_id;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_lists_log_change
+    AFTER INSERT OR UPDATE OR DELETE
+    ON users_lists
+    FOR EACH ROW
+EXECUTE FUNCTION log_membership_change();
+
+CREATE FUNCTION log_item_change() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM log_changes(li.list_id, 'item', OLD.id) FROM lists_items li WHERE li.item_id = OLD.id;
+        RETURN OLD;
+    END IF;
+
+    PERFORM log_changes(li.list_id, 'item', NEW.id) FROM lists_items li WHERE li.item_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER todo_items_log_change
+    AFTER UPDATE
+    ON todo_items
+    FOR EACH ROW
+EXECUTE FUNCTION log_item_change();
+
+CREATE TRIGGER todo_items_log_delete
+    BEFORE DELETE
+    ON todo_items
+    FOR EACH ROW
+EXECUTE FUNCTION log_item_change();
+
+-- items are created and moved by way of lists_items
+CREATE FUNCTION log_item_list_change() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM log_changes(OLD.list_id, 'item', OLD.item_id);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM log_changes(NEW.list_id, 'item', NEW.item_id);
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER lists_items_log_change
+    AFTER INSERT OR UPDATE OR DELETE
+    ON lists_items
+    FOR EACH ROW
+EXECUTE FUNCTION log_item_list_change();
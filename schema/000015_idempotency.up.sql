@@ -0,0 +1,13 @@
+-- successful responses to requests made with an Idempotency-Key header, they
+-- are replayed when a request is retried with the same key
+CREATE TABLE idempotency_keys
+(
+    user_id      int references users (id) on delete cascade not null,
+    key          varchar(255)                                not null,
+    request_hash varchar(64)                                 not null,
+    status_code  int                                         not null,
+    content_type varchar(255)                                not null,
+    body         bytea                                       not null,
+    created_at   timestamp                                   not null default now(),
+    PRIMARY KEY (user_id, key)
+);
@@ -0,0 +1,50 @@
+-- times go back to wall clock times in the time zone of this session, see
+-- the up migration
+ALTER TABLE sessions
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN revoked_at TYPE timestamp USING revoked_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE refresh_tokens
+    ALTER COLUMN expires_at TYPE timestamp USING expires_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN used_at TYPE timestamp USING used_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE access_tokens
+    ALTER COLUMN expires_at TYPE timestamp USING expires_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN last_used_at TYPE timestamp USING last_used_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN revoked_at TYPE timestamp USING revoked_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE list_invitations
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE todo_items
+    ALTER COLUMN due_at TYPE timestamp USING due_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN updated_at TYPE timestamp USING updated_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN completed_at TYPE timestamp USING completed_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE labels
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE reminders
+    ALTER COLUMN remind_at TYPE timestamp USING remind_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN sent_at TYPE timestamp USING sent_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE webhooks
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE webhook_deliveries
+    ALTER COLUMN next_attempt_at TYPE timestamp USING next_attempt_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN delivered_at TYPE timestamp USING delivered_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE events
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE changes
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE idempotency_keys
+    ALTER COLUMN created_at TYPE timestamp USING created_at AT TIME ZONE current_setting('TimeZone');
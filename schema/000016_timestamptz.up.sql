@@ -0,0 +1,54 @@
+-- The timestamp columns hold wall clock times: now() defaults wrote them in
+-- the time zone of the session, times sent by the application lost their
+-- offset. The connections do not set a time zone, so both are assumed to be
+-- in the database time zone, which is what the TimeZone of this session is
+-- by default. When the data was written in another zone, run the migration
+-- with that one, e.g. PGTZ=Europe/Berlin or PGOPTIONS='-c TimeZone=UTC'.
+ALTER TABLE sessions
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN revoked_at TYPE timestamptz USING revoked_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE refresh_tokens
+    ALTER COLUMN expires_at TYPE timestamptz USING expires_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN used_at TYPE timestamptz USING used_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE access_tokens
+    ALTER COLUMN expires_at TYPE timestamptz USING expires_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN last_used_at TYPE timestamptz USING last_used_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN revoked_at TYPE timestamptz USING revoked_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE list_invitations
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE todo_items
+    ALTER COLUMN due_at TYPE timestamptz USING due_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN updated_at TYPE timestamptz USING updated_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN completed_at TYPE timestamptz USING completed_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE labels
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE reminders
+    ALTER COLUMN remind_at TYPE timestamptz USING remind_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN sent_at TYPE timestamptz USING sent_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE webhooks
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE webhook_deliveries
+    ALTER COLUMN next_attempt_at TYPE timestamptz USING next_attempt_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN delivered_at TYPE timestamptz USING delivered_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE events
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE changes
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE idempotency_keys
+    ALTER COLUMN created_at TYPE timestamptz USING created_at AT TIME ZONE current_setting('TimeZone');
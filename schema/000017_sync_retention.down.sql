@@ -0,0 +1,3 @@
+DROP INDEX changes_created_at_idx;
+
+DROP TABLE sync_horizon;
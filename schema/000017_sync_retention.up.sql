@@ -0,0 +1,11 @@
+-- the change log is pruned after the retention window (see
+-- scheduler.SyncPruner), horizon is the newest transaction pruned so far.
+-- Sync tokens at or before it may have missed changes and are refused.
+CREATE TABLE sync_horizon
+(
+    tx_id bigint not null
+);
+
+INSERT INTO sync_horizon (tx_id) VALUES (0);
+
+CREATE INDEX changes_created_at_idx ON changes (created_at);